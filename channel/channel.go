@@ -2,12 +2,15 @@ package channel
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/let-z-go/toolkit/deque"
@@ -20,12 +23,17 @@ import (
 type Channel struct {
 	options                *Options
 	extension              Extension
-	dequeOfPendingRequests deque.Deque
+	dequeOfPendingRequests stream.DequeOfPendingRequests
 	stream_                unsafe.Pointer
 	pendingAbort           atomic.Value
+	peerCert               atomic.Value
+	peerCred               atomic.Value
+	remoteAddr             atomic.Value
 	state_                 int32
 	nextSequenceNumber     uint32
 	inflightRPCs           sync.Map
+	inflightNotifications  sync.Map
+	scheduler              *rpcScheduler
 }
 
 func (c *Channel) Init(options *Options, isServerSide bool) *Channel {
@@ -33,6 +41,10 @@ func (c *Channel) Init(options *Options, isServerSide bool) *Channel {
 	c.extension = c.options.ExtensionFactory(RestrictedChannel{c}, isServerSide)
 	c.dequeOfPendingRequests.Init(0)
 
+	if c.options.Scheduling.MaxConcurrency >= 1 {
+		c.scheduler = new(rpcScheduler).Init(&c.options.Scheduling)
+	}
+
 	c.stream_ = unsafe.Pointer(new(stream.Stream).Init(
 		c.options.Stream,
 		isServerSide,
@@ -60,6 +72,9 @@ func (c *Channel) Run(ctx context.Context, serverURL *url.URL, connection net.Co
 		c.extension.OnReestablishing(serverURL)
 	}
 
+	c.peerCert.Store(getPeerCert(connection))
+	c.peerCred.Store(getPeerCred(connection))
+	c.remoteAddr.Store(remoteAddr{connection.RemoteAddr()})
 	ok, err := c.stream().Establish(ctx, connection, c.extension.NewHandshaker())
 
 	if err != nil {
@@ -89,6 +104,87 @@ func (c *Channel) DoRPC(rpc *RPC, responseFactory MessageFactory) {
 }
 
 func (c *Channel) PrepareRPC(rpc *RPC, responseFactory MessageFactory) {
+	c.prepareRPC(rpc, func(rpc *RPC) {
+		handleOutgoingRPC(rpc, responseFactory)
+	})
+}
+
+func (c *Channel) DoNotification(rpc *RPC) {
+	c.PrepareNotification(rpc)
+	rpc.Handle()
+}
+
+func (c *Channel) PrepareNotification(rpc *RPC) {
+	c.prepareRPC(rpc, handleOutgoingNotification)
+}
+
+func (c *Channel) Abort(extraData ExtraData) {
+	c.AbortWithReason(extraData, HangupReason{})
+}
+
+func (c *Channel) AbortWithReason(extraData ExtraData, reason HangupReason) {
+	c.pendingAbort.Store(&abortion{extraData, reason})
+	c.stream().AbortWithReason(extraData, reason)
+}
+
+func (c *Channel) Drain() {
+	c.stream().Drain()
+}
+
+func (c *Channel) UpdateSettings(incomingKeepaliveInterval time.Duration, incomingConcurrencyLimit int) {
+	c.stream().UpdateSettings(incomingKeepaliveInterval, incomingConcurrencyLimit)
+}
+
+func (c *Channel) Ping(ctx context.Context) (time.Duration, error) {
+	rtt, err := c.stream().Ping(ctx)
+
+	if err == stream.ErrClosed {
+		if c.isClosed() {
+			err = ErrClosed
+		} else {
+			err = ErrBroken
+		}
+	}
+
+	return rtt, err
+}
+
+func (c *Channel) SmoothedRTT() time.Duration {
+	return c.stream().SmoothedRTT()
+}
+
+func (c *Channel) RTTJitter() time.Duration {
+	return c.stream().RTTJitter()
+}
+
+func (c *Channel) IsServerSide() bool {
+	return c.stream().IsServerSide()
+}
+
+func (c *Channel) TransportID() uuid.UUID {
+	return c.stream().TransportID()
+}
+
+func (c *Channel) UserData() interface{} {
+	return c.stream().UserData()
+}
+
+func (c *Channel) PeerCert() *x509.Certificate {
+	peerCert, _ := c.peerCert.Load().(*x509.Certificate)
+	return peerCert
+}
+
+func (c *Channel) PeerCred() *PeerCred {
+	peerCred, _ := c.peerCred.Load().(*PeerCred)
+	return peerCred
+}
+
+func (c *Channel) RemoteAddr() net.Addr {
+	remoteAddr, _ := c.remoteAddr.Load().(remoteAddr)
+	return remoteAddr.Value
+}
+
+func (c *Channel) prepareRPC(rpc *RPC, outgoingRPCHandler RPCHandler) {
 	rpc.internals.Channel = c
 	rpcParent, rpcHasParent := GetRPC(rpc.Ctx)
 
@@ -126,7 +222,7 @@ func (c *Channel) PrepareRPC(rpc *RPC, responseFactory MessageFactory) {
 		}
 
 		rpcHandler = func(rpc *RPC) {
-			handleOutgoingRPC(rpc, responseFactory)
+			outgoingRPCHandler(rpc)
 
 			if rpcHasParent {
 				for key, value := range rpc.ResponseExtraData.Value() {
@@ -145,23 +241,6 @@ func (c *Channel) PrepareRPC(rpc *RPC, responseFactory MessageFactory) {
 	rpc.Ctx = BindRPC(rpc.Ctx, rpc)
 }
 
-func (c *Channel) Abort(extraData ExtraData) {
-	c.pendingAbort.Store(extraData)
-	c.stream().Abort(extraData)
-}
-
-func (c *Channel) IsServerSide() bool {
-	return c.stream().IsServerSide()
-}
-
-func (c *Channel) TransportID() uuid.UUID {
-	return c.stream().TransportID()
-}
-
-func (c *Channel) UserData() interface{} {
-	return c.stream().UserData()
-}
-
 func (c *Channel) setState(newState state) {
 	oldState := c.state()
 
@@ -212,7 +291,8 @@ ValidStateTransition:
 		)
 
 		if value := c.pendingAbort.Load(); value != nil {
-			newStream.Abort(value.(ExtraData))
+			abortion_ := value.(*abortion)
+			newStream.AbortWithReason(abortion_.ExtraData, abortion_.Reason)
 		}
 
 		atomic.StorePointer(&c.stream_, unsafe.Pointer(newStream))
@@ -274,6 +354,11 @@ var (
 	ErrClosed           = errors.New("gogorpc/channel: closed")
 )
 
+type abortion struct {
+	ExtraData ExtraData
+	Reason    HangupReason
+}
+
 const (
 	initial = state(1 + iota)
 	establishing
@@ -304,21 +389,93 @@ func (s state) GoString() string {
 func handleOutgoingRPC(rpc *RPC, responseFactory MessageFactory) {
 	channel := rpc.internals.Channel
 	inflightRPC_ := getPooledInflightRPC(responseFactory)
-	channel.inflightRPCs.Store(rpc.internals.SequenceNumber, inflightRPC_)
+	inflightRPC_.Stream = rpc.internals.Stream
+
+	for {
+		stream_ := channel.stream()
+		channel.inflightRPCs.Store(rpc.internals.SequenceNumber, inflightRPC_)
+
+		if err := stream_.SendRequest(rpc.Ctx, &proto.RequestHeader{
+			SequenceNumber: rpc.internals.SequenceNumber,
+			ServiceName:    rpc.ServiceName,
+			MethodName:     rpc.MethodName,
+			ExtraData:      rpc.RequestExtraData.Value(),
+			Deadline:       rpc.internals.Deadline,
+			Priority:       rpc.Priority,
+
+			TraceId: proto.UUID{
+				Low:  rpc.internals.TraceID[0],
+				High: rpc.internals.TraceID[1],
+			},
+		}, rpc.Request); err != nil {
+			channel.inflightRPCs.Delete(rpc.internals.SequenceNumber)
+
+			switch err {
+			case stream.ErrClosed:
+				rpc.Err = ErrClosed
+			default:
+				rpc.Err = err
+			}
+
+			return
+		}
+
+		if err := inflightRPC_.WaitFor(rpc.Ctx); err != nil {
+			if inflightRPC_.Abandon() {
+				channel.stream().SendCancel(&proto.CancelHeader{
+					SequenceNumber: rpc.internals.SequenceNumber,
+				})
+			}
+
+			rpc.Err = err
+			return
+		}
+
+		if !(inflightRPC_.Err == stream.ErrRequestRefused && inflightRPC_.Stream == nil) {
+			break
+		}
+
+		select {
+		case <-rpc.Ctx.Done():
+			rpc.Err = rpc.Ctx.Err()
+			putPooledInflightRPC(inflightRPC_)
+			return
+		case <-stream_.Closure():
+		}
+
+		inflightRPC_.Reset(responseFactory)
+	}
+
+	if inflightRPC_.Err == stream.ErrRequestExpired {
+		<-rpc.Ctx.Done()
+		inflightRPC_.Err = rpc.Ctx.Err()
+	}
+
+	rpc.ResponseExtraData = inflightRPC_.ResponseExtraData.Ref(false)
+	rpc.Response = inflightRPC_.Response
+	rpc.Err = inflightRPC_.Err
+	putPooledInflightRPC(inflightRPC_)
+}
+
+func handleOutgoingNotification(rpc *RPC) {
+	channel := rpc.internals.Channel
+	stream_ := channel.stream()
+	emission := make(chan error, 1)
+	channel.inflightNotifications.Store(rpc.internals.SequenceNumber, emission)
+	rpc.Response = NullMessage
 
-	if err := channel.stream().SendRequest(rpc.Ctx, &proto.RequestHeader{
+	if err := stream_.SendNotification(rpc.Ctx, &proto.NotificationHeader{
 		SequenceNumber: rpc.internals.SequenceNumber,
 		ServiceName:    rpc.ServiceName,
 		MethodName:     rpc.MethodName,
 		ExtraData:      rpc.RequestExtraData.Value(),
-		Deadline:       rpc.internals.Deadline,
 
 		TraceId: proto.UUID{
 			Low:  rpc.internals.TraceID[0],
 			High: rpc.internals.TraceID[1],
 		},
 	}, rpc.Request); err != nil {
-		channel.inflightRPCs.Delete(rpc.internals.SequenceNumber)
+		channel.inflightNotifications.Delete(rpc.internals.SequenceNumber)
 
 		switch err {
 		case stream.ErrClosed:
@@ -330,18 +487,43 @@ func handleOutgoingRPC(rpc *RPC, responseFactory MessageFactory) {
 		return
 	}
 
-	if err := inflightRPC_.WaitFor(rpc.Ctx); err != nil {
+	select {
+	case <-rpc.Ctx.Done():
+		channel.inflightNotifications.Delete(rpc.internals.SequenceNumber)
+		rpc.Err = rpc.Ctx.Err()
+	case err := <-emission:
 		rpc.Err = err
-		return
+	case <-stream_.Closure():
+		channel.inflightNotifications.Delete(rpc.internals.SequenceNumber)
+
+		select {
+		case err := <-emission:
+			rpc.Err = err
+		default:
+			if channel.isClosed() {
+				rpc.Err = ErrClosed
+			} else {
+				rpc.Err = ErrBroken
+			}
+		}
 	}
+}
 
-	if inflightRPC_.Err == stream.ErrRequestExpired {
-		<-rpc.Ctx.Done()
-		inflightRPC_.Err = rpc.Ctx.Err()
+type remoteAddr struct {
+	Value net.Addr
+}
+
+func getPeerCert(connection net.Conn) *x509.Certificate {
+	// besides *tls.Conn, connections layered over tls (wss, mux+tls) expose the state too.
+	tlsConnection, ok := connection.(interface{ ConnectionState() tls.ConnectionState })
+
+	if !ok {
+		return nil
 	}
 
-	rpc.ResponseExtraData = inflightRPC_.ResponseExtraData.Ref(false)
-	rpc.Response = inflightRPC_.Response
-	rpc.Err = inflightRPC_.Err
-	putPooledInflightRPC(inflightRPC_)
+	if verifiedChains := tlsConnection.ConnectionState().VerifiedChains; len(verifiedChains) >= 1 {
+		return verifiedChains[0][0]
+	}
+
+	return nil
 }
@@ -3,9 +3,12 @@ package channel
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -376,6 +379,570 @@ func TestDeadline(t *testing.T) {
 	)
 }
 
+func TestCancellation(t *testing.T) {
+	cancellation := make(chan struct{})
+	responses := make(chan Message, 1)
+	opts2 := &Options{
+		Stream: (&StreamOptions{}).AddEventFilter(EventOutgoing, EventResponse, func(ev *Event) {
+			responses <- ev.Message
+		}),
+	}
+	opts2.BuildMethod("foo", "bar").
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			select {
+			case <-rpc.Ctx.Done():
+				assert.Equal(t, context.Canceled, rpc.Ctx.Err())
+				close(cancellation)
+			case <-time.After(3 * time.Second):
+				t.Error("rpc not cancelled")
+			}
+			rpc.Response = &RawMessage{'x'}
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			ctx2, cancel := context.WithCancel(ctx)
+			time.AfterFunc(500*time.Millisecond, cancel)
+			rpc := RPC{
+				Ctx:         ctx2,
+				ServiceName: "foo",
+				MethodName:  "bar",
+				Request:     NullMessage,
+			}
+			cn.DoRPC(&rpc, GetNullMessage)
+			assert.EqualError(t, rpc.Err, "context canceled")
+			select {
+			case <-cancellation:
+			case <-time.After(3 * time.Second):
+				t.Error("rpc not cancelled")
+			}
+			select {
+			case response := <-responses:
+				// the response of the handler is skipped.
+				assert.Equal(t, NullMessage, response)
+			case <-time.After(3 * time.Second):
+				t.Error("no response")
+			}
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			time.Sleep(2 * time.Second)
+			return false
+		},
+		0,
+	)
+}
+
+func TestRPCStream(t *testing.T) {
+	const N = 100
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetStreamRequestFactory(NewRawMessage).
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			rpc.Response = NullMessage
+			for {
+				msg, err := rpc.Stream().Receive(rpc.Ctx)
+				if err != nil {
+					if err != io.EOF {
+						rpc.Err = err
+					}
+					return
+				}
+				msg2 := RawMessage(fmt.Sprintf("echo(%s)", *msg.(*RawMessage)))
+				if err := rpc.Stream().Send(rpc.Ctx, &msg2); err != nil {
+					rpc.Err = err
+					return
+				}
+			}
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			rpc := GetPooledRPC()
+			*rpc = RPC{
+				Ctx:         ctx,
+				ServiceName: "foo",
+				MethodName:  "bar",
+			}
+			rs := PrepareRPCStream(cn, rpc, NewRawMessage)
+			defer rs.Close()
+			rs.Open()
+			for i := 0; i < N; i++ {
+				msg := RawMessage(fmt.Sprintf("msg:%d", i))
+				if !assert.NoError(t, rs.Send(ctx, &msg)) {
+					t.FailNow()
+				}
+			}
+			if !assert.NoError(t, rs.CloseSend(ctx)) {
+				t.FailNow()
+			}
+			assert.Equal(t, ErrStreamClosed, rs.CloseSend(ctx))
+			for i := 0; i < N; i++ {
+				msg, err := rs.Receive(ctx)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				assert.Equal(t, fmt.Sprintf("echo(msg:%d)", i), string(*msg.(*RawMessage)))
+			}
+			_, err := rs.Receive(ctx)
+			assert.Equal(t, io.EOF, err)
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			time.Sleep(2 * time.Second)
+			return false
+		},
+		0,
+	)
+}
+
+func TestRPCStreamFlowControl(t *testing.T) {
+	const M = 8 * 1024
+	release := make(chan struct{})
+	n := 0
+	opts2 := &Options{Stream: &StreamOptions{IncomingWindowSize: 1 << 16}}
+	opts2.BuildMethod("foo", "bar").
+		SetStreamRequestFactory(NewRawMessage).
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			rpc.Response = NullMessage
+			<-release
+			for {
+				_, err := rpc.Stream().Receive(rpc.Ctx)
+				if err != nil {
+					if err != io.EOF {
+						rpc.Err = err
+					}
+					return
+				}
+				n++
+			}
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			rpc := GetPooledRPC()
+			*rpc = RPC{
+				Ctx:         ctx,
+				ServiceName: "foo",
+				MethodName:  "bar",
+			}
+			rs := PrepareRPCStream(cn, rpc, NewRawMessage)
+			defer rs.Close()
+			rs.Open()
+			msg := RawMessage(make([]byte, M))
+			i := 0
+			for ; i < 100; i++ {
+				ctx2, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+				err := rs.Send(ctx2, &msg)
+				cancel()
+				if err != nil {
+					assert.Equal(t, context.DeadlineExceeded, err)
+					break
+				}
+			}
+			// the handler doesn't read, so the writer stalls once the window is used up.
+			assert.Greater(t, i, 0)
+			assert.Less(t, i, (1<<16)/M)
+			close(release)
+			if !assert.NoError(t, rs.Send(ctx, &msg)) {
+				t.FailNow()
+			}
+			if !assert.NoError(t, rs.CloseSend(ctx)) {
+				t.FailNow()
+			}
+			_, err := rs.Receive(ctx)
+			assert.Equal(t, io.EOF, err)
+			assert.Equal(t, i+1, n)
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			time.Sleep(2 * time.Second)
+			return false
+		},
+		0,
+	)
+}
+
+func TestDrain(t *testing.T) {
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			time.Sleep(time.Second)
+			rpc.Response = NullMessage
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			rpc := RPC{
+				Ctx:         ctx,
+				ServiceName: "foo",
+				MethodName:  "bar",
+				Request:     NullMessage,
+			}
+			cn.DoRPC(&rpc, GetNullMessage)
+			assert.NoError(t, rpc.Err)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			time.Sleep(200 * time.Millisecond)
+			cn.Drain()
+			return false
+		},
+		0,
+	)
+}
+
+func TestDrainAndResend(t *testing.T) {
+	var n int32
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			atomic.AddInt32(&n, 1)
+			time.Sleep(time.Second)
+			rpc.Response = NullMessage
+		})
+	f := false
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			wg := sync.WaitGroup{}
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					rpc := RPC{
+						Ctx:         ctx,
+						ServiceName: "foo",
+						MethodName:  "bar",
+						Request:     NullMessage,
+					}
+					if i == 0 {
+						cn.DoRPC(&rpc, GetNullMessage)
+						assert.NoError(t, rpc.Err)
+					} else {
+						// sent after the go-away, refused by the old stream and then re-sent.
+						time.Sleep(400 * time.Millisecond)
+						t0 := time.Now()
+						cn.DoRPC(&rpc, GetNullMessage)
+						assert.NoError(t, rpc.Err)
+						assert.True(t, time.Since(t0) >= time.Second+500*time.Millisecond)
+					}
+				}(i)
+			}
+			wg.Wait()
+			assert.Equal(t, int32(2), atomic.LoadInt32(&n))
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			if !f {
+				f = true
+				time.Sleep(200 * time.Millisecond)
+				cn.Drain()
+				return true
+			}
+			return false
+		},
+		1,
+	)
+}
+
+func TestGoAway(t *testing.T) {
+	cn := new(Channel).Init(&Options{}, false)
+	inflightRPCs := []*inflightRPC(nil)
+	for i := int32(1); i <= 4; i++ {
+		inflightRPC_ := getPooledInflightRPC(GetNullMessage)
+		if i <= 3 {
+			inflightRPC_.SetEmitted()
+		}
+		cn.inflightRPCs.Store(i, inflightRPC_)
+		inflightRPCs = append(inflightRPCs, inflightRPC_)
+	}
+	mp := messageProcessor{Channel: cn}
+	ev := Event{}
+	ev.GoAway.LastSequenceNumber = 1
+	mp.HandleGoAway(context.Background(), &ev)
+	for i, inflightRPC_ := range inflightRPCs {
+		_, ok := cn.inflightRPCs.Load(int32(i + 1))
+		if i+1 == 2 || i+1 == 3 {
+			assert.False(t, ok)
+			assert.Equal(t, ErrRequestRefused, inflightRPC_.Err)
+		} else {
+			assert.True(t, ok)
+			assert.NoError(t, inflightRPC_.Err)
+		}
+	}
+}
+
+func TestNotification(t *testing.T) {
+	const N = 100
+	n := int32(0)
+	notifications := make(chan struct{})
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetRequestFactory(NewRawMessage).
+		SetIncomingNotificationHandler(func(rpc *RPC) {
+			assert.Equal(t, "ping", string(*rpc.Request.(*RawMessage)))
+			if atomic.AddInt32(&n, 1) == N {
+				close(notifications)
+			}
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			for i := 0; i < N; i++ {
+				msg := RawMessage("ping")
+				rpc := RPC{
+					Ctx:         ctx,
+					ServiceName: "foo",
+					MethodName:  "bar",
+					Request:     &msg,
+				}
+				cn.DoNotification(&rpc)
+				assert.NoError(t, rpc.Err)
+			}
+			select {
+			case <-notifications:
+			case <-time.After(3 * time.Second):
+				t.Error("notifications not handled")
+			}
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			return false
+		},
+		0,
+	)
+	assert.Equal(t, int32(N), atomic.LoadInt32(&n))
+}
+
+func TestNotificationUnsupported(t *testing.T) {
+	rpc := RPC{
+		Ctx:         context.Background(),
+		ServiceName: "foo",
+		MethodName:  "bar",
+		Request:     NullMessage,
+	}
+	PrepareNotification(rpcOnlyPreparer{}, &rpc)
+	rpc.Handle()
+	assert.Equal(t, ErrNotificationUnsupported, rpc.Err)
+}
+
+type rpcOnlyPreparer struct{}
+
+func (rpcOnlyPreparer) PrepareRPC(rpc *RPC, responseFactory MessageFactory) {
+	panic("unreachable")
+}
+
+func TestBorrowedMessage1(t *testing.T) {
+	const N = 1000
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetRequestFactory(NewBorrowedMessage).
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			time.Sleep(time.Millisecond)
+			msg := RawMessage(fmt.Sprintf("echo(%s)", rpc.Request.(*BorrowedMessage).Payload))
+			rpc.Response = &msg
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			wg := sync.WaitGroup{}
+			for i := 0; i < N; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					msg := RawMessage(fmt.Sprintf("req:%d", i))
+					rpc := RPC{
+						Ctx:         ctx,
+						ServiceName: "foo",
+						MethodName:  "bar",
+						Request:     &msg,
+					}
+					cn.DoRPC(&rpc, NewRawMessage)
+					if !assert.NoError(t, rpc.Err) {
+						t.FailNow()
+					}
+					assert.Equal(t, fmt.Sprintf("echo(%s)", msg), string(*rpc.Response.(*RawMessage)))
+				}(i)
+			}
+			wg.Wait()
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			return false
+		},
+		0,
+	)
+}
+
+func TestBorrowedMessage2(t *testing.T) {
+	const N = 1000
+	opts2 := &Options{}
+	opts2.BuildMethod("foo", "bar").
+		SetRequestFactory(NewBorrowedMessage).
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			// echo the request, whose payload is gone once the handler returns.
+			rpc.Response = rpc.Request
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			wg := sync.WaitGroup{}
+			for i := 0; i < N; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					msg := RawMessage(strings.Repeat(fmt.Sprintf("req:%d;", i), 100))
+					rpc := RPC{
+						Ctx:         ctx,
+						ServiceName: "foo",
+						MethodName:  "bar",
+						Request:     &msg,
+					}
+					cn.DoRPC(&rpc, NewRawMessage)
+					if !assert.NoError(t, rpc.Err) {
+						t.FailNow()
+					}
+					assert.Equal(t, string(msg), string(*rpc.Response.(*RawMessage)))
+				}(i)
+			}
+			wg.Wait()
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			return false
+		},
+		0,
+	)
+}
+
+func TestPing(t *testing.T) {
+	testSetup2(
+		t,
+		&Options{},
+		&Options{},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			for i := 0; i < 3; i++ {
+				rtt, err := cn.Ping(ctx)
+				if !assert.NoError(t, err) {
+					break
+				}
+				assert.Greater(t, int64(rtt), int64(0))
+			}
+			assert.Greater(t, int64(cn.SmoothedRTT()), int64(0))
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			return false
+		},
+		0,
+	)
+}
+
+func TestScheduling(t *testing.T) {
+	var mutex sync.Mutex
+	var priorities []int32
+	opts2 := &Options{Scheduling: SchedulingOptions{MaxConcurrency: 1}}
+	opts2.BuildMethod("foo", "bar").
+		SetIncomingRPCHandler(func(rpc *RPC) {
+			mutex.Lock()
+			priorities = append(priorities, rpc.Priority)
+			mutex.Unlock()
+			time.Sleep(200 * time.Millisecond)
+			rpc.Response = NullMessage
+		})
+	testSetup2(
+		t,
+		&Options{},
+		opts2,
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			wg := sync.WaitGroup{}
+			for _, priority := range [...]int32{0, 0, 0, 5, 0} {
+				wg.Add(1)
+				go func(priority int32) {
+					defer wg.Done()
+					rpc := RPC{
+						Ctx:         ctx,
+						ServiceName: "foo",
+						MethodName:  "bar",
+						Priority:    priority,
+						Request:     NullMessage,
+					}
+					cn.DoRPC(&rpc, GetNullMessage)
+					assert.NoError(t, rpc.Err)
+				}(priority)
+				time.Sleep(20 * time.Millisecond)
+			}
+			wg.Wait()
+			cn.Abort(nil)
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			return false
+		},
+		0,
+	)
+	assert.Equal(t, []int32{0, 5, 0, 0, 0}, priorities)
+}
+
+func TestHangupReason(t *testing.T) {
+	reason := HangupReason{
+		Message:           "maintenance",
+		RetryAfter:        3 * time.Second,
+		RedirectServerURL: "tcp://127.0.0.1:8888",
+	}
+	errs := make(chan error, 1)
+	testSetup2(
+		t,
+		&Options{ExtensionFactory: testExtension{
+			ob: func(err error) {
+				errs <- err
+			},
+		}.Factory()},
+		&Options{},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			err := <-errs
+			if hangup, ok := err.(*Hangup); assert.True(t, ok) {
+				assert.True(t, hangup.IsPassive)
+				assert.Equal(t, HangupAborted, hangup.Code)
+				assert.Equal(t, reason, hangup.Reason)
+			}
+			assert.EqualError(t, err, "gogorpc/stream: hangup (passive): aborted: maintenance")
+			return false
+		},
+		func(ctx context.Context, cn *Channel, conn net.Conn) bool {
+			time.Sleep(100 * time.Millisecond)
+			cn.AbortWithReason(nil, reason)
+			return false
+		},
+		0,
+	)
+}
+
 func testSetup2(
 	t *testing.T,
 	opts1 *Options,
@@ -470,6 +1037,7 @@ type testExtension struct {
 	tc TrafficCrypter
 	k  Keepaliver
 	ud interface{}
+	ob func(error)
 }
 
 func (s testExtension) Listener() Listener                { return s.l }
@@ -478,6 +1046,12 @@ func (s testExtension) NewHandshaker() Handshaker         { return s.h }
 func (s testExtension) NewTrafficCrypter() TrafficCrypter { return s.tc }
 func (s testExtension) NewKeepaliver() Keepaliver         { return s.k }
 
+func (s testExtension) OnBroken(err error) {
+	if s.ob != nil {
+		s.ob(err)
+	}
+}
+
 func (s testExtension) Factory() ExtensionFactory {
 	return func(RestrictedChannel, bool) Extension {
 		if s.l == nil {
@@ -3,6 +3,7 @@ package channel
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 )
 
 type inflightRPC struct {
@@ -10,9 +11,11 @@ type inflightRPC struct {
 	ResponseExtraData ExtraData
 	Response          Message
 	Err               error
+	Stream            *RPCStream
 
 	responseFactory MessageFactory
 	completion      chan struct{}
+	emissionState   int32
 }
 
 func (ir *inflightRPC) Init(responseFactory MessageFactory) *inflightRPC {
@@ -26,10 +29,34 @@ func (ir *inflightRPC) Reset(responseFactory MessageFactory) *inflightRPC {
 	ir.ResponseExtraData = nil
 	ir.Response = nil
 	ir.Err = nil
+	ir.Stream = nil
 	ir.responseFactory = responseFactory
+	ir.emissionState = emissionPending
 	return ir
 }
 
+func (ir *inflightRPC) SetEmitted() bool {
+	ir.IsEmitted = true
+	return atomic.CompareAndSwapInt32(&ir.emissionState, emissionPending, emissionDone)
+}
+
+func (ir *inflightRPC) Abandon() (isEmitted bool) {
+	if atomic.CompareAndSwapInt32(&ir.emissionState, emissionPending, emissionAbandoned) {
+		return false
+	}
+
+	atomic.StoreInt32(&ir.emissionState, emissionAbandoned)
+	return true
+}
+
+func (ir *inflightRPC) IsAwaited() bool {
+	return atomic.LoadInt32(&ir.emissionState) == emissionDone
+}
+
+func (ir *inflightRPC) IsAbandoned() bool {
+	return atomic.LoadInt32(&ir.emissionState) == emissionAbandoned
+}
+
 func (ir *inflightRPC) NewResponse() Message {
 	return ir.responseFactory()
 }
@@ -56,6 +83,12 @@ func (ir *inflightRPC) WaitFor(ctx context.Context) error {
 	}
 }
 
+const (
+	emissionPending = iota
+	emissionDone
+	emissionAbandoned
+)
+
 var inflightRPCPool = sync.Pool{}
 
 func getPooledInflightRPC(responseFactory MessageFactory) *inflightRPC {
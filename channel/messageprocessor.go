@@ -3,6 +3,8 @@ package channel
 import (
 	"context"
 	"errors"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/let-z-go/gogorpc/internal/proto"
@@ -16,8 +18,11 @@ type messageProcessor struct {
 	Channel    *Channel
 	Keepaliver Keepaliver
 
+	incomingRPCCancels sync.Map
+	incomingRPCStreams sync.Map
 	methodOptionsCache *MethodOptions
 	inflightRPCCache   *inflightRPC
+	rpcStreamCache     *RPCStream
 }
 
 var _ = stream.MessageProcessor((*messageProcessor)(nil))
@@ -36,7 +41,13 @@ func (mp *messageProcessor) EmitKeepalive(event *Event) {
 
 func (mp *messageProcessor) NewRequest(event *Event) {
 	methodOptions := mp.Channel.options.GetMethod(event.RequestHeader.ServiceName, event.RequestHeader.MethodName)
-	event.Message = methodOptions.RequestFactory()
+
+	if methodOptions.StreamRequestFactory == nil {
+		event.Message = methodOptions.RequestFactory()
+	} else {
+		event.Message = NullMessage
+	}
+
 	mp.methodOptionsCache = methodOptions
 }
 
@@ -51,7 +62,9 @@ func (mp *messageProcessor) HandleRequest(ctx context.Context, event *Event) {
 			responseHeader.SequenceNumber = requestHeader.SequenceNumber
 
 			if event.ResponseHeader.RpcError.Type == 0 {
-				event.Stream().SendResponse(responseHeader, event.Message)
+				// not to hold up receiving on the window.
+				responseHeader2 := *responseHeader
+				go event.Stream().SendResponse(&responseHeader2, event.Message)
 			} else {
 				event.Stream().SendResponse(responseHeader, NullMessage)
 			}
@@ -63,6 +76,8 @@ func (mp *messageProcessor) HandleRequest(ctx context.Context, event *Event) {
 				Str("method_name", requestHeader.MethodName).
 				Msg("rpc_bad_request")
 
+			releaseMessage(event.Message)
+
 			event.Stream().SendResponse(&proto.ResponseHeader{
 				SequenceNumber: requestHeader.SequenceNumber,
 				RpcError:       proto.RPCError(*RPCErrBadRequest),
@@ -81,6 +96,8 @@ func (mp *messageProcessor) HandleRequest(ctx context.Context, event *Event) {
 			Str("method_name", requestHeader.MethodName).
 			Msg("rpc_not_found")
 
+		releaseMessage(event.Message)
+
 		event.Stream().SendResponse(&proto.ResponseHeader{
 			SequenceNumber: requestHeader.SequenceNumber,
 			RpcError:       proto.RPCError(*RPCErrNotFound),
@@ -95,6 +112,7 @@ func (mp *messageProcessor) HandleRequest(ctx context.Context, event *Event) {
 		Ctx:              ctx,
 		ServiceName:      requestHeader.ServiceName,
 		MethodName:       requestHeader.MethodName,
+		Priority:         requestHeader.Priority,
 		RequestExtraData: ExtraData(requestHeader.ExtraData).Ref(false),
 		Request:          event.Message,
 
@@ -107,7 +125,34 @@ func (mp *messageProcessor) HandleRequest(ctx context.Context, event *Event) {
 	}
 
 	rpc.internals.Init(mp.methodOptionsCache.IncomingRPCHandler, mp.methodOptionsCache.IncomingRPCInterceptors)
-	go handleIncomingRPC(rpc, event.Stream())
+	var cancel context.CancelFunc
+
+	if rpc.internals.Deadline == 0 {
+		rpc.Ctx, cancel = context.WithCancel(rpc.Ctx)
+	} else {
+		rpc.Ctx, cancel = context.WithDeadline(rpc.Ctx, time.Unix(0, rpc.internals.Deadline))
+	}
+
+	mp.incomingRPCCancels.Store(requestHeader.SequenceNumber, cancel)
+
+	if streamRequestFactory := mp.methodOptionsCache.StreamRequestFactory; streamRequestFactory != nil {
+		rpc.internals.Stream = newIncomingRPCStream(rpc, streamRequestFactory, event.Stream(), &mp.incomingRPCStreams)
+	}
+
+	// the request is consumed once handled.
+	windowCredit := event.TakeWindowCredit()
+	scheduler := mp.Channel.scheduler
+
+	if scheduler == nil {
+		go handleIncomingRPC(rpc, cancel, event.Stream(), windowCredit, &mp.incomingRPCCancels)
+		return
+	}
+
+	stream_ := event.Stream()
+
+	scheduler.Schedule(rpc.Priority, rpc.internals.Deadline, func() {
+		handleIncomingRPC(rpc, cancel, stream_, windowCredit, &mp.incomingRPCCancels)
+	})
 }
 
 func (mp *messageProcessor) PostEmitRequest(event *Event) {
@@ -116,7 +161,15 @@ func (mp *messageProcessor) PostEmitRequest(event *Event) {
 	inflightRPC_ := value.(*inflightRPC)
 
 	if event.Err == nil {
-		inflightRPC_.IsEmitted = true
+		if !inflightRPC_.SetEmitted() {
+			event.Stream().SendCancel(&proto.CancelHeader{
+				SequenceNumber: requestHeader.SequenceNumber,
+			})
+		}
+
+		if rpcStream := inflightRPC_.Stream; rpcStream != nil {
+			rpcStream.setEmitted(event.Stream())
+		}
 	} else {
 		mp.Channel.inflightRPCs.Delete(requestHeader.SequenceNumber)
 
@@ -143,10 +196,14 @@ func (mp *messageProcessor) NewResponse(event *Event) {
 	value, ok := mp.Channel.inflightRPCs.Load(responseHeader.SequenceNumber)
 
 	if !ok {
-		mp.Channel.options.Logger.Warn().
-			Str("transport_id", event.Stream().TransportID().String()).
-			Int("sequence_number", int(responseHeader.SequenceNumber)).
-			Msg("channel_ignored_response")
+		// refused rpcs may have been re-sent on the go-away.
+		if !responseHeader.IsRefused {
+			mp.Channel.options.Logger.Warn().
+				Str("transport_id", event.Stream().TransportID().String()).
+				Int("sequence_number", int(responseHeader.SequenceNumber)).
+				Msg("channel_ignored_response")
+		}
+
 		event.Err = ErrEventDropped
 		return
 	}
@@ -164,7 +221,7 @@ func (mp *messageProcessor) NewResponse(event *Event) {
 
 	mp.Channel.inflightRPCs.Delete(responseHeader.SequenceNumber)
 
-	if responseHeader.RpcError.Type == 0 {
+	if responseHeader.RpcError.Type == 0 && !inflightRPC_.IsAbandoned() {
 		event.Message = inflightRPC_.NewResponse()
 	} else {
 		event.Message = NullMessage
@@ -192,19 +249,207 @@ func (mp *messageProcessor) HandleResponse(ctx context.Context, event *Event) {
 func (mp *messageProcessor) PostEmitResponse(event *Event) {
 }
 
-func handleIncomingRPC(rpc *RPC, stream_ stream.RestrictedStream) {
-	var cancel context.CancelFunc
+func (mp *messageProcessor) NewStreamMessage(event *Event) {
+	streamMessageHeader := &event.StreamMessageHeader
+	var rpcStream *RPCStream
 
-	if rpc.internals.Deadline == 0 {
-		rpc.Ctx, cancel = context.WithCancel(rpc.Ctx)
+	if streamMessageHeader.IsResponse {
+		if value, ok := mp.Channel.inflightRPCs.Load(streamMessageHeader.SequenceNumber); ok {
+			if inflightRPC_ := value.(*inflightRPC); inflightRPC_.IsEmitted {
+				rpcStream = inflightRPC_.Stream
+			}
+		}
 	} else {
-		rpc.Ctx, cancel = context.WithDeadline(rpc.Ctx, time.Unix(0, rpc.internals.Deadline))
+		if value, ok := mp.incomingRPCStreams.Load(streamMessageHeader.SequenceNumber); ok {
+			rpcStream = value.(*RPCStream)
+		}
+	}
+
+	if rpcStream == nil {
+		mp.Channel.options.Logger.Warn().
+			Str("transport_id", event.Stream().TransportID().String()).
+			Int("sequence_number", int(streamMessageHeader.SequenceNumber)).
+			Bool("is_response", streamMessageHeader.IsResponse).
+			Msg("channel_ignored_stream_message")
+		event.Err = ErrEventDropped
+		return
+	}
+
+	event.Message = rpcStream.messageFactory()
+	mp.rpcStreamCache = rpcStream
+}
+
+func (mp *messageProcessor) HandleStreamMessage(ctx context.Context, event *Event) {
+	if event.Err == nil {
+		mp.rpcStreamCache.putMessage(event.Message, event.TakeWindowCredit())
+	} else {
+		mp.rpcStreamCache.putEnd(event.Err)
+		event.Err = nil
+	}
+}
+
+func (mp *messageProcessor) HandleStreamEnd(ctx context.Context, event *Event) {
+	streamEndHeader := &event.StreamEndHeader
+	value, ok := mp.incomingRPCStreams.Load(streamEndHeader.SequenceNumber)
+
+	if !ok {
+		mp.Channel.options.Logger.Warn().
+			Str("transport_id", event.Stream().TransportID().String()).
+			Int("sequence_number", int(streamEndHeader.SequenceNumber)).
+			Msg("channel_ignored_stream_end")
+		event.Err = nil
+		return
+	}
+
+	rpcStream := value.(*RPCStream)
+
+	if event.Err == nil {
+		rpcStream.putEnd(io.EOF)
+	} else {
+		rpcStream.putEnd(event.Err)
+		event.Err = nil
+	}
+}
+
+func (mp *messageProcessor) HandleCancel(ctx context.Context, event *Event) {
+	// taking the cancel away tells handleIncomingRPC that the peer has given up the rpc.
+	if value, ok := mp.incomingRPCCancels.LoadAndDelete(event.CancelHeader.SequenceNumber); ok {
+		value.(context.CancelFunc)()
+	}
+
+	event.Err = nil
+}
+
+func (mp *messageProcessor) PostEmitStreamMessage(event *Event) {
+}
+
+func (mp *messageProcessor) NewNotification(event *Event) {
+	methodOptions := mp.Channel.options.GetMethod(event.NotificationHeader.ServiceName, event.NotificationHeader.MethodName)
+	event.Message = methodOptions.RequestFactory()
+	mp.methodOptionsCache = methodOptions
+}
+
+func (mp *messageProcessor) HandleNotification(ctx context.Context, event *Event) {
+	notificationHeader := &event.NotificationHeader
+	traceID := uuid.UUID{notificationHeader.TraceId.Low, notificationHeader.TraceId.High}
+
+	if event.Err != nil {
+		mp.Channel.options.Logger.Info().Err(event.Err).
+			Str("transport_id", event.Stream().TransportID().String()).
+			Str("trace_id", traceID.String()).
+			Str("service_name", notificationHeader.ServiceName).
+			Str("method_name", notificationHeader.MethodName).
+			Msg("notification_bad_request")
+		releaseMessage(event.Message)
+		event.Err = nil
+		return
+	}
+
+	if !mp.methodOptionsCache.IsOneWay {
+		mp.Channel.options.Logger.Info().
+			Str("transport_id", event.Stream().TransportID().String()).
+			Str("trace_id", traceID.String()).
+			Str("service_name", notificationHeader.ServiceName).
+			Str("method_name", notificationHeader.MethodName).
+			Msg("notification_not_found")
+		releaseMessage(event.Message)
+		return
+	}
+
+	rpc := GetPooledRPC()
+
+	*rpc = RPC{
+		Ctx:              ctx,
+		ServiceName:      notificationHeader.ServiceName,
+		MethodName:       notificationHeader.MethodName,
+		RequestExtraData: ExtraData(notificationHeader.ExtraData).Ref(false),
+		Request:          event.Message,
+
+		internals: rpcInternals{
+			Channel:        mp.Channel,
+			SequenceNumber: notificationHeader.SequenceNumber,
+			TraceID:        traceID,
+		},
+	}
+
+	rpc.internals.Init(mp.methodOptionsCache.IncomingRPCHandler, mp.methodOptionsCache.IncomingRPCInterceptors)
+	go handleIncomingNotification(rpc, event.Stream(), event.TakeWindowCredit())
+}
+
+func (mp *messageProcessor) PostEmitNotification(event *Event) {
+	notificationHeader := &event.NotificationHeader
+
+	if value, ok := mp.Channel.inflightNotifications.Load(notificationHeader.SequenceNumber); ok {
+		mp.Channel.inflightNotifications.Delete(notificationHeader.SequenceNumber)
+		value.(chan error) <- event.Err
+	}
+
+	if event.Err != nil {
+		event.Err = ErrEventDropped
 	}
+}
+
+func (mp *messageProcessor) HandleGoAway(ctx context.Context, event *Event) {
+	lastSequenceNumber := event.GoAway.LastSequenceNumber
 
+	// the rpcs after the last one the peer processes are to be refused, get them re-sent without waiting.
+	mp.Channel.inflightRPCs.Range(func(key interface{}, value interface{}) bool {
+		inflightRPC_ := value.(*inflightRPC)
+
+		if key.(int32) > lastSequenceNumber && inflightRPC_.Stream == nil && inflightRPC_.IsAwaited() {
+			if _, ok := mp.Channel.inflightRPCs.LoadAndDelete(key); ok {
+				inflightRPC_.Fail(nil, ErrRequestRefused)
+			}
+		}
+
+		return true
+	})
+}
+
+func handleIncomingRPC(
+	rpc *RPC,
+	cancel context.CancelFunc,
+	stream_ stream.RestrictedStream,
+	windowCredit int,
+	incomingRPCCancels *sync.Map,
+) {
 	defer cancel()
 	rpc.Ctx = BindRPC(rpc.Ctx, rpc)
+	rpcStream := rpc.internals.Stream
+
+	if rpcStream != nil {
+		rpcStream.ctx = rpc.Ctx
+	}
+
+	request := rpc.Request
 	rpc.Handle()
 
+	if borrowedMessage, ok := request.(*BorrowedMessage); ok && rpc.Response == request {
+		// echoed, the response would outlive the payload, which goes with the handler returning.
+		response := RawMessage(append([]byte(nil), borrowedMessage.Payload...))
+		rpc.Response = &response
+	}
+
+	releaseMessage(request)
+	stream_.ReturnWindowCredit(windowCredit)
+	_, ok := incomingRPCCancels.LoadAndDelete(rpc.internals.SequenceNumber)
+
+	if rpcStream != nil {
+		rpcStream.close()
+	}
+
+	if !ok {
+		// cancelled by the peer, which no longer waits for the response, a bare header only gives its request slot back.
+		sequenceNumber := rpc.internals.SequenceNumber
+		PutPooledRPC(rpc)
+
+		stream_.SendResponse(&proto.ResponseHeader{
+			SequenceNumber: sequenceNumber,
+		}, NullMessage)
+
+		return
+	}
+
 	responseHeader := proto.ResponseHeader{
 		SequenceNumber: rpc.internals.SequenceNumber,
 		ExtraData:      rpc.ResponseExtraData.Value(),
@@ -220,12 +465,15 @@ func handleIncomingRPC(rpc *RPC, stream_ stream.RestrictedStream) {
 		if rpcErr2, ok := rpc.Err.(*RPCError); ok {
 			rpcErr = rpcErr2
 		} else {
-			rpc.internals.Channel.options.Logger.Error().Err(rpc.Err).
-				Str("transport_id", stream_.TransportID().String()).
-				Str("trace_id", rpc.internals.TraceID.String()).
-				Str("service_name", rpc.ServiceName).
-				Str("method_name", rpc.MethodName).
-				Msg("rpc_internal_server_error")
+			if rpc.Ctx.Err() != context.Canceled {
+				rpc.internals.Channel.options.Logger.Error().Err(rpc.Err).
+					Str("transport_id", stream_.TransportID().String()).
+					Str("trace_id", rpc.internals.TraceID.String()).
+					Str("service_name", rpc.ServiceName).
+					Str("method_name", rpc.MethodName).
+					Msg("rpc_internal_server_error")
+			}
+
 			rpcErr = RPCErrInternalServer
 		}
 
@@ -236,3 +484,29 @@ func handleIncomingRPC(rpc *RPC, stream_ stream.RestrictedStream) {
 	PutPooledRPC(rpc)
 	stream_.SendResponse(&responseHeader, response)
 }
+
+func handleIncomingNotification(rpc *RPC, stream_ stream.RestrictedStream, windowCredit int) {
+	rpc.Ctx = BindRPC(rpc.Ctx, rpc)
+	request := rpc.Request
+	rpc.Handle()
+	releaseMessage(request)
+	stream_.ReturnWindowCredit(windowCredit)
+
+	if rpc.Err != nil {
+		rpc.internals.Channel.options.Logger.Warn().Err(rpc.Err).
+			Str("transport_id", stream_.TransportID().String()).
+			Str("trace_id", rpc.internals.TraceID.String()).
+			Str("service_name", rpc.ServiceName).
+			Str("method_name", rpc.MethodName).
+			Msg("notification_failed")
+	}
+
+	PutPooledRPC(rpc)
+}
+
+func releaseMessage(message Message) {
+	// a borrowed payload is only valid until the handler returns.
+	if borrowedMessage, ok := message.(*BorrowedMessage); ok {
+		borrowedMessage.Release()
+	}
+}
@@ -12,6 +12,7 @@ type Options struct {
 	Stream           *StreamOptions
 	Logger           *zerolog.Logger
 	ExtensionFactory ExtensionFactory
+	Scheduling       SchedulingOptions
 
 	serviceOptionsManager
 
@@ -37,6 +38,8 @@ func (o *Options) Normalize() *Options {
 		if !o.GeneralMethod.requestFactoryIsSet {
 			o.setRequestFactory("", "", GetNullMessage)
 		}
+
+		o.Scheduling.normalize()
 	})
 
 	return o
@@ -51,6 +54,27 @@ func (o *Options) Do(doer func(*Options)) *Options {
 	return o
 }
 
+type SchedulingOptions struct {
+	MaxConcurrency        int
+	ReservedConcurrency   int
+	HighPriority          int32
+	EarliestDeadlineFirst bool
+}
+
+func (so *SchedulingOptions) normalize() {
+	if so.MaxConcurrency < 1 {
+		so.MaxConcurrency = 0
+		so.ReservedConcurrency = 0
+		return
+	}
+
+	if so.ReservedConcurrency < 0 {
+		so.ReservedConcurrency = 0
+	} else if so.ReservedConcurrency >= so.MaxConcurrency {
+		so.ReservedConcurrency = so.MaxConcurrency - 1
+	}
+}
+
 type MethodOptionsBuilder struct {
 	options *Options
 
@@ -63,11 +87,21 @@ func (mob MethodOptionsBuilder) SetRequestFactory(requestFactory MessageFactory)
 	return mob
 }
 
+func (mob MethodOptionsBuilder) SetStreamRequestFactory(requestFactory MessageFactory) MethodOptionsBuilder {
+	mob.options.setStreamRequestFactory(mob.serviceName, mob.methodName, requestFactory)
+	return mob
+}
+
 func (mob MethodOptionsBuilder) SetIncomingRPCHandler(rpcHandler RPCHandler) MethodOptionsBuilder {
 	mob.options.setIncomingRPCHandler(mob.serviceName, mob.methodName, rpcHandler)
 	return mob
 }
 
+func (mob MethodOptionsBuilder) SetIncomingNotificationHandler(rpcHandler RPCHandler) MethodOptionsBuilder {
+	mob.options.setIncomingNotificationHandler(mob.serviceName, mob.methodName, rpcHandler)
+	return mob
+}
+
 func (mob MethodOptionsBuilder) AddIncomingRPCInterceptor(rpcInterceptor RPCHandler) MethodOptionsBuilder {
 	mob.options.addIncomingRPCInterceptor(mob.serviceName, mob.methodName, rpcInterceptor)
 	return mob
@@ -89,9 +123,11 @@ type ServiceOptions struct {
 
 type MethodOptions struct {
 	RequestFactory          MessageFactory
+	StreamRequestFactory    MessageFactory
 	IncomingRPCHandler      RPCHandler
 	IncomingRPCInterceptors []RPCHandler
 	OutgoingRPCInterceptors []RPCHandler
+	IsOneWay                bool
 
 	requestFactoryIsSet     bool
 	incomingRPCHandlerIsSet bool
@@ -105,12 +141,21 @@ func GetNullMessage() Message {
 
 var _ = MessageFactory(GetNullMessage)
 
+// NewRawMessage makes payloads plain bytes, outgoing ones are referenced until flushed.
 func NewRawMessage() Message {
 	return new(RawMessage)
 }
 
 var _ = MessageFactory(NewRawMessage)
 
+// NewBorrowedMessage makes handlers see request payloads in place, valid until they return.
+// Outgoing ones are referenced until flushed, which DoRPC and DoNotification wait for.
+func NewBorrowedMessage() Message {
+	return new(BorrowedMessage)
+}
+
+var _ = MessageFactory(NewBorrowedMessage)
+
 type serviceOptionsManager struct {
 	GeneralMethod MethodOptions
 	Services      map[string]*ServiceOptions
@@ -178,6 +223,15 @@ func (som *serviceOptionsManager) setRequestFactory(serviceName string, methodNa
 	}
 }
 
+func (som *serviceOptionsManager) setStreamRequestFactory(serviceName string, methodName string, messageFactory MessageFactory) {
+	utils.Assert(serviceName != "" && methodName != "", func() string {
+		return fmt.Sprintf("gogorpc/channel: invalid argument: methodName=%#v, serviceName=%#v", methodName, serviceName)
+	})
+
+	method := som.getOrSetService(serviceName).getOrSetMethod(methodName)
+	method.StreamRequestFactory = messageFactory
+}
+
 func (som *serviceOptionsManager) setIncomingRPCHandler(serviceName string, methodName string, rpcHandler RPCHandler) {
 	if serviceName == "" {
 		utils.Assert(methodName == "", func() string {
@@ -224,6 +278,17 @@ func (som *serviceOptionsManager) setIncomingRPCHandler(serviceName string, meth
 	}
 }
 
+func (som *serviceOptionsManager) setIncomingNotificationHandler(serviceName string, methodName string, rpcHandler RPCHandler) {
+	utils.Assert(serviceName != "" && methodName != "", func() string {
+		return fmt.Sprintf("gogorpc/channel: invalid argument: methodName=%#v, serviceName=%#v", methodName, serviceName)
+	})
+
+	method := som.getOrSetService(serviceName).getOrSetMethod(methodName)
+	method.IncomingRPCHandler = rpcHandler
+	method.IsOneWay = true
+	method.incomingRPCHandlerIsSet = true
+}
+
 func (som *serviceOptionsManager) addIncomingRPCInterceptor(serviceName string, methodName string, rpcInterceptor RPCHandler) {
 	if serviceName == "" {
 		utils.Assert(methodName == "", func() string {
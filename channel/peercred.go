@@ -0,0 +1,7 @@
+package channel
+
+type PeerCred struct {
+	UID int
+	GID int
+	PID int
+}
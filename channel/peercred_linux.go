@@ -0,0 +1,37 @@
+//go:build linux
+// +build linux
+
+package channel
+
+import (
+	"net"
+	"syscall"
+)
+
+func getPeerCred(connection net.Conn) *PeerCred {
+	unixConnection, ok := connection.(*net.UnixConn)
+
+	if !ok {
+		return nil
+	}
+
+	rawConnection, err := unixConnection.SyscallConn()
+
+	if err != nil {
+		return nil
+	}
+
+	var ucred *syscall.Ucred
+
+	if rawConnection.Control(func(fd uintptr) {
+		ucred, err = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}) != nil || err != nil {
+		return nil
+	}
+
+	return &PeerCred{
+		UID: int(ucred.Uid),
+		GID: int(ucred.Gid),
+		PID: int(ucred.Pid),
+	}
+}
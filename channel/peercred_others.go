@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package channel
+
+import (
+	"net"
+)
+
+func getPeerCred(connection net.Conn) *PeerCred {
+	return nil
+}
@@ -2,6 +2,10 @@ package channel
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"time"
 
 	"github.com/let-z-go/toolkit/uuid"
 )
@@ -10,6 +14,7 @@ type RPC struct {
 	Ctx              context.Context
 	ServiceName      string
 	MethodName       string
+	Priority         int32
 	RequestExtraData ExtraDataRef
 	Request          Message
 
@@ -43,6 +48,10 @@ func (r *RPC) IsHandled() bool {
 	return r.internals.IsHandled()
 }
 
+func (r *RPC) Stream() *RPCStream {
+	return r.internals.Stream
+}
+
 type RestrictedChannel struct {
 	underlying *Channel
 }
@@ -55,10 +64,30 @@ func (rc RestrictedChannel) PrepareRPC(rpc *RPC, responseFactory MessageFactory)
 	rc.underlying.PrepareRPC(rpc, responseFactory)
 }
 
+func (rc RestrictedChannel) DoNotification(rpc *RPC) {
+	rc.underlying.DoNotification(rpc)
+}
+
+func (rc RestrictedChannel) PrepareNotification(rpc *RPC) {
+	rc.underlying.PrepareNotification(rpc)
+}
+
 func (rc RestrictedChannel) Abort(extraData ExtraData) {
 	rc.underlying.Abort(extraData)
 }
 
+func (rc RestrictedChannel) AbortWithReason(extraData ExtraData, reason HangupReason) {
+	rc.underlying.AbortWithReason(extraData, reason)
+}
+
+func (rc RestrictedChannel) SmoothedRTT() time.Duration {
+	return rc.underlying.SmoothedRTT()
+}
+
+func (rc RestrictedChannel) RTTJitter() time.Duration {
+	return rc.underlying.RTTJitter()
+}
+
 func (rc RestrictedChannel) IsServerSide() bool {
 	return rc.underlying.IsServerSide()
 }
@@ -71,12 +100,46 @@ func (rc RestrictedChannel) UserData() interface{} {
 	return rc.underlying.UserData()
 }
 
+func (rc RestrictedChannel) PeerCert() *x509.Certificate {
+	return rc.underlying.PeerCert()
+}
+
+func (rc RestrictedChannel) PeerCred() *PeerCred {
+	return rc.underlying.PeerCred()
+}
+
+func (rc RestrictedChannel) RemoteAddr() net.Addr {
+	return rc.underlying.RemoteAddr()
+}
+
 type RPCHandler func(rpc *RPC)
 
 type RPCPreparer interface {
 	PrepareRPC(rpc *RPC, responseFactory MessageFactory)
 }
 
+// NotificationPreparer is optionally implemented by RPCPreparers which are able to
+// emit one-way rpcs.
+type NotificationPreparer interface {
+	PrepareNotification(rpc *RPC)
+}
+
+var (
+	_ = NotificationPreparer((*Channel)(nil))
+	_ = NotificationPreparer(RestrictedChannel{})
+)
+
+func PrepareNotification(rpcPreparer RPCPreparer, rpc *RPC) {
+	if notificationPreparer, ok := rpcPreparer.(NotificationPreparer); ok {
+		notificationPreparer.PrepareNotification(rpc)
+		return
+	}
+
+	rpc.internals.Init(func(rpc *RPC) {
+		rpc.Err = ErrNotificationUnsupported
+	}, nil)
+}
+
 func BindRPC(ctx context.Context, rpc *RPC) context.Context {
 	return context.WithValue(ctx, rpcKey{}, rpc)
 }
@@ -98,12 +161,15 @@ type rpcInternals struct {
 	SequenceNumber int32
 	Deadline       int64
 	TraceID        uuid.UUID
+	Stream         *RPCStream
 
 	handler              RPCHandler
 	interceptors         []RPCHandler
 	nextInterceptorIndex int
 }
 
+var ErrNotificationUnsupported = errors.New("gogorpc/channel: notification unsupported")
+
 func (ri *rpcInternals) Init(handler RPCHandler, interceptors []RPCHandler) {
 	ri.handler = handler
 	ri.interceptors = interceptors
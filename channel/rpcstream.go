@@ -0,0 +1,292 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/let-z-go/toolkit/utils"
+
+	"github.com/let-z-go/gogorpc/internal/proto"
+	"github.com/let-z-go/gogorpc/internal/stream"
+)
+
+type RPCStream struct {
+	rpc                *RPC
+	ctx                context.Context
+	messageFactory     MessageFactory
+	isIncoming         bool
+	stream_            stream.RestrictedStream
+	incomingRPCStreams *sync.Map
+	cancel             context.CancelFunc
+	emission           chan struct{}
+	completion         chan struct{}
+	isOpened           bool
+	isSendClosed_      int32
+	isClosed_          int32
+	mutex              sync.Mutex
+	receivedMessages   []receivedMessage
+	receiveErr         error
+	arrival            chan struct{}
+}
+
+func PrepareRPCStream(rpcPreparer RPCPreparer, rpc *RPC, responseFactory MessageFactory) *RPCStream {
+	rs := new(RPCStream).init(rpc, responseFactory, false)
+	rs.emission = make(chan struct{})
+	rs.completion = make(chan struct{})
+	rpc.Ctx, rs.cancel = context.WithCancel(rpc.Ctx)
+	rs.ctx = rpc.Ctx
+	rpc.Request = NullMessage
+	rpc.internals.Stream = rs
+	rpcPreparer.PrepareRPC(rpc, GetNullMessage)
+	return rs
+}
+
+func (rs *RPCStream) Open() {
+	utils.Assert(!rs.isIncoming && !rs.isOpened, func() string {
+		return "gogorpc/channel: rpc stream already opened"
+	})
+
+	rs.isOpened = true
+
+	go func() {
+		rs.rpc.Handle()
+
+		if err := rs.rpc.Err; err == nil {
+			rs.putEnd(io.EOF)
+		} else {
+			rs.putEnd(err)
+		}
+
+		close(rs.completion)
+	}()
+}
+
+func (rs *RPCStream) Send(ctx context.Context, message Message) error {
+	if rs.isIncoming {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if rs.isClosed() {
+			return ErrStreamClosed
+		}
+
+		return rs.stream_.SendStreamMessage(ctx, &proto.StreamMessageHeader{
+			SequenceNumber: rs.rpc.internals.SequenceNumber,
+			IsResponse:     true,
+		}, message)
+	}
+
+	if rs.isSendClosed() {
+		return ErrStreamClosed
+	}
+
+	if err := rs.waitForEmission(ctx); err != nil {
+		return err
+	}
+
+	if err := rs.stream_.SendStreamMessage(ctx, &proto.StreamMessageHeader{
+		SequenceNumber: rs.rpc.internals.SequenceNumber,
+	}, message); err != nil {
+		if err == stream.ErrClosed {
+			return ErrBroken
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (rs *RPCStream) CloseSend(ctx context.Context) error {
+	utils.Assert(!rs.isIncoming, func() string {
+		return "gogorpc/channel: send side of incoming rpc stream can not be closed"
+	})
+
+	if !atomic.CompareAndSwapInt32(&rs.isSendClosed_, 0, 1) {
+		return ErrStreamClosed
+	}
+
+	if err := rs.waitForEmission(ctx); err != nil {
+		return err
+	}
+
+	if err := rs.stream_.SendStreamEnd(&proto.StreamEndHeader{
+		SequenceNumber: rs.rpc.internals.SequenceNumber,
+	}); err != nil {
+		if err == stream.ErrClosed {
+			return ErrBroken
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (rs *RPCStream) Receive(ctx context.Context) (Message, error) {
+	for {
+		rs.mutex.Lock()
+
+		if len(rs.receivedMessages) >= 1 {
+			receivedMessage_ := rs.receivedMessages[0]
+			rs.receivedMessages[0] = receivedMessage{}
+			rs.receivedMessages = rs.receivedMessages[1:]
+			rs.mutex.Unlock()
+			// the peer may send more as soon as the message is taken.
+			rs.stream_.ReturnWindowCredit(receivedMessage_.WindowCredit)
+			return receivedMessage_.Message, nil
+		}
+
+		err := rs.receiveErr
+		rs.mutex.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-rs.ctx.Done():
+			return nil, rs.ctx.Err()
+		case <-rs.arrival:
+		}
+	}
+}
+
+func (rs *RPCStream) Close() {
+	utils.Assert(!rs.isIncoming, func() string {
+		return "gogorpc/channel: incoming rpc stream can not be closed"
+	})
+
+	rs.cancel()
+
+	if rs.isOpened {
+		<-rs.completion
+	}
+
+	rs.discardReceivedMessages()
+	PutPooledRPC(rs.rpc)
+}
+
+func (rs *RPCStream) RPC() *RPC {
+	return rs.rpc
+}
+
+func (rs *RPCStream) init(rpc *RPC, messageFactory MessageFactory, isIncoming bool) *RPCStream {
+	rs.rpc = rpc
+	rs.messageFactory = messageFactory
+	rs.isIncoming = isIncoming
+	rs.arrival = make(chan struct{}, 1)
+	return rs
+}
+
+func (rs *RPCStream) waitForEmission(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-rs.completion:
+		if err := rs.rpc.Err; err != nil {
+			return err
+		}
+
+		return ErrStreamClosed
+	case <-rs.emission:
+		return nil
+	}
+}
+
+func (rs *RPCStream) setEmitted(stream_ stream.RestrictedStream) {
+	rs.stream_ = stream_
+	close(rs.emission)
+}
+
+func (rs *RPCStream) putMessage(message Message, windowCredit int) {
+	rs.mutex.Lock()
+
+	if rs.receiveErr != nil {
+		rs.mutex.Unlock()
+		releaseMessage(message)
+		rs.stream_.ReturnWindowCredit(windowCredit)
+		return
+	}
+
+	rs.receivedMessages = append(rs.receivedMessages, receivedMessage{message, windowCredit})
+	rs.mutex.Unlock()
+	rs.notifyArrival()
+}
+
+func (rs *RPCStream) putEnd(err error) {
+	rs.mutex.Lock()
+
+	if rs.receiveErr != nil {
+		rs.mutex.Unlock()
+		return
+	}
+
+	rs.receiveErr = err
+	rs.mutex.Unlock()
+	rs.notifyArrival()
+}
+
+func (rs *RPCStream) notifyArrival() {
+	select {
+	case rs.arrival <- struct{}{}:
+	default:
+	}
+}
+
+func (rs *RPCStream) discardReceivedMessages() {
+	rs.mutex.Lock()
+
+	if rs.receiveErr == nil {
+		rs.receiveErr = ErrStreamClosed
+	}
+
+	receivedMessages := rs.receivedMessages
+	rs.receivedMessages = nil
+	rs.mutex.Unlock()
+
+	for _, receivedMessage_ := range receivedMessages {
+		releaseMessage(receivedMessage_.Message)
+		rs.stream_.ReturnWindowCredit(receivedMessage_.WindowCredit)
+	}
+}
+
+func (rs *RPCStream) close() {
+	atomic.StoreInt32(&rs.isClosed_, 1)
+	rs.incomingRPCStreams.Delete(rs.rpc.internals.SequenceNumber)
+	rs.discardReceivedMessages()
+}
+
+func (rs *RPCStream) isSendClosed() bool {
+	return atomic.LoadInt32(&rs.isSendClosed_) == 1
+}
+
+func (rs *RPCStream) isClosed() bool {
+	return atomic.LoadInt32(&rs.isClosed_) == 1
+}
+
+var ErrStreamClosed = errors.New("gogorpc/channel: stream closed")
+
+type receivedMessage struct {
+	Message      Message
+	WindowCredit int
+}
+
+func newIncomingRPCStream(
+	rpc *RPC,
+	requestFactory MessageFactory,
+	stream_ stream.RestrictedStream,
+	incomingRPCStreams *sync.Map,
+) *RPCStream {
+	rs := new(RPCStream).init(rpc, requestFactory, true)
+	rs.stream_ = stream_
+	rs.incomingRPCStreams = incomingRPCStreams
+	incomingRPCStreams.Store(rpc.internals.SequenceNumber, rs)
+	return rs
+}
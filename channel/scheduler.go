@@ -0,0 +1,121 @@
+package channel
+
+import (
+	"container/heap"
+	"sync"
+)
+
+type rpcScheduler struct {
+	options *SchedulingOptions
+
+	mutex         sync.Mutex
+	runningCount  int
+	queue         scheduledRPCQueue
+	nextSerialNum uint64
+}
+
+func (rs *rpcScheduler) Init(options *SchedulingOptions) *rpcScheduler {
+	rs.options = options
+	rs.queue.IsEDF = options.EarliestDeadlineFirst
+	return rs
+}
+
+func (rs *rpcScheduler) Schedule(priority int32, deadline int64, runner func()) {
+	rs.mutex.Lock()
+
+	if rs.canRun(priority) {
+		rs.runningCount++
+		rs.mutex.Unlock()
+		go rs.run(runner)
+		return
+	}
+
+	heap.Push(&rs.queue, scheduledRPC{
+		Priority:  priority,
+		Deadline:  deadline,
+		SerialNum: rs.nextSerialNum,
+		Runner:    runner,
+	})
+
+	rs.nextSerialNum++
+	rs.mutex.Unlock()
+}
+
+func (rs *rpcScheduler) run(runner func()) {
+	for {
+		runner()
+		rs.mutex.Lock()
+		rs.runningCount--
+
+		if rs.queue.Len() == 0 || !rs.canRun(rs.queue.Items[0].Priority) {
+			rs.mutex.Unlock()
+			return
+		}
+
+		runner = heap.Pop(&rs.queue).(scheduledRPC).Runner
+		rs.runningCount++
+		rs.mutex.Unlock()
+	}
+}
+
+func (rs *rpcScheduler) canRun(priority int32) bool {
+	concurrencyLimit := rs.options.MaxConcurrency
+
+	if priority < rs.options.HighPriority {
+		concurrencyLimit -= rs.options.ReservedConcurrency
+	}
+
+	return rs.runningCount < concurrencyLimit
+}
+
+type scheduledRPC struct {
+	Priority  int32
+	Deadline  int64
+	SerialNum uint64
+	Runner    func()
+}
+
+type scheduledRPCQueue struct {
+	Items []scheduledRPC
+	IsEDF bool
+}
+
+var _ = heap.Interface((*scheduledRPCQueue)(nil))
+
+func (srq *scheduledRPCQueue) Len() int {
+	return len(srq.Items)
+}
+
+func (srq *scheduledRPCQueue) Less(i, j int) bool {
+	x, y := &srq.Items[i], &srq.Items[j]
+
+	if x.Priority != y.Priority {
+		return x.Priority > y.Priority
+	}
+
+	if srq.IsEDF && x.Deadline != y.Deadline {
+		if x.Deadline == 0 || y.Deadline == 0 {
+			return y.Deadline == 0
+		}
+
+		return x.Deadline < y.Deadline
+	}
+
+	return x.SerialNum < y.SerialNum
+}
+
+func (srq *scheduledRPCQueue) Swap(i, j int) {
+	srq.Items[i], srq.Items[j] = srq.Items[j], srq.Items[i]
+}
+
+func (srq *scheduledRPCQueue) Push(item interface{}) {
+	srq.Items = append(srq.Items, item.(scheduledRPC))
+}
+
+func (srq *scheduledRPCQueue) Pop() interface{} {
+	i := len(srq.Items) - 1
+	item := srq.Items[i]
+	srq.Items[i] = scheduledRPC{}
+	srq.Items = srq.Items[:i]
+	return item
+}
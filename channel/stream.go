@@ -12,11 +12,23 @@ const (
 	EventResponse  = stream.EventResponse
 	EventHangup    = stream.EventHangup
 
+	EventStreamMessage = stream.EventStreamMessage
+	EventStreamEnd     = stream.EventStreamEnd
+	EventCancel        = stream.EventCancel
+	EventGoAway        = stream.EventGoAway
+	EventNotification  = stream.EventNotification
+	EventSettings      = stream.EventSettings
+	EventWindowUpdate  = stream.EventWindowUpdate
+
 	HangupAborted                 = stream.HangupAborted
 	HangupBadIncomingEvent        = stream.HangupBadIncomingEvent
 	HangupTooManyIncomingRequests = stream.HangupTooManyIncomingRequests
 	HangupOutgoingPacketTooLarge  = stream.HangupOutgoingPacketTooLarge
 	HangupSystem                  = stream.HangupSystem
+	HangupDrained                 = stream.HangupDrained
+	HangupBadIncomingChecksum     = stream.HangupBadIncomingChecksum
+
+	RetryAfterKey = stream.RetryAfterKey
 )
 
 type (
@@ -25,15 +37,17 @@ type (
 	Handshaker      = stream.Handshaker
 	DummyHandshaker = stream.DummyHandshaker
 
-	Event          = stream.Event
-	EventDirection = stream.EventDirection
-	EventType      = stream.EventType
-	EventFilter    = stream.EventFilter
-	Message        = stream.Message
-	RawMessage     = stream.RawMessage
+	Event           = stream.Event
+	EventDirection  = stream.EventDirection
+	EventType       = stream.EventType
+	EventFilter     = stream.EventFilter
+	Message         = stream.Message
+	RawMessage      = stream.RawMessage
+	BorrowedMessage = stream.BorrowedMessage
 
-	Hangup     = stream.Hangup
-	HangupCode = stream.HangupCode
+	Hangup       = stream.Hangup
+	HangupCode   = stream.HangupCode
+	HangupReason = stream.HangupReason
 
 	ExtraData    = stream.ExtraData
 	ExtraDataRef = stream.ExtraDataRef
@@ -42,6 +56,9 @@ type (
 var (
 	ErrBadHandshake = stream.ErrBadHandshake
 
-	ErrEventDropped = stream.ErrEventDropped
-	NullMessage     = stream.NullMessage
+	ErrEventDropped   = stream.ErrEventDropped
+	ErrRequestRefused = stream.ErrRequestRefused
+	NullMessage       = stream.NullMessage
+
+	GetRetryAfter = stream.GetRetryAfter
 )
@@ -11,4 +11,8 @@ type (
 
 	TrafficCrypter      = transport.TrafficCrypter
 	DummyTrafficCrypter = transport.DummyTrafficCrypter
+
+	BufferPool = transport.BufferPool
 )
+
+var DefaultBufferPool = transport.DefaultBufferPool
@@ -46,6 +46,14 @@ func (c *Client) PrepareRPC(rpc *channel.RPC, responseFactory channel.MessageFac
 	c.channel.PrepareRPC(rpc, responseFactory)
 }
 
+func (c *Client) DoNotification(rpc *channel.RPC) {
+	c.channel.DoNotification(rpc)
+}
+
+func (c *Client) PrepareNotification(rpc *channel.RPC) {
+	c.channel.PrepareNotification(rpc)
+}
+
 func (c *Client) Abort(extraData channel.ExtraData) {
 	c.channel.Abort(extraData)
 }
@@ -86,6 +94,7 @@ func (c *Client) run() (err error) {
 	}
 
 	connectRetryCount := -1
+	var redirectedServerURL *url.URL
 
 	for {
 		err = c.ctx.Err()
@@ -101,21 +110,27 @@ func (c *Client) run() (err error) {
 		}
 
 		var serverURL *url.URL
-		serverURL, err = serverURLManager_.GetNextServerURL(c.ctx, connectRetryCount)
 
-		if err != nil {
-			return
+		if redirectedServerURL == nil {
+			serverURL, err = serverURLManager_.GetNextServerURL(c.ctx, connectRetryCount)
+
+			if err != nil {
+				return
+			}
+		} else {
+			serverURL = redirectedServerURL
+			redirectedServerURL = nil
 		}
 
-		var connector Connector
-		connector, err = GetConnector(serverURL.Scheme)
+		var connector ConnectorWithOptions
+		connector, err = GetConnectorWithOptions(serverURL.Scheme)
 
 		if err != nil {
 			return
 		}
 
 		var connection net.Conn
-		connection, err = connector(c.ctx, c.getConnectTimeout(), serverURL)
+		connection, err = connector(c.ctx, c.options, serverURL)
 
 		if err != nil {
 			c.options.Logger.Error().Err(err).
@@ -134,8 +149,54 @@ func (c *Client) run() (err error) {
 			continue
 		}
 
-		if hangup, ok := err.(*channel.Hangup); ok && !hangup.IsPassive {
-			return
+		if hangup, ok := err.(*channel.Hangup); ok {
+			if !hangup.IsPassive {
+				return
+			}
+
+			if hangup.Reason.RedirectServerURL != "" {
+				redirectedServerURL, _ = serverURLManager_.ParseServerURL(hangup.Reason.RedirectServerURL)
+
+				if redirectedServerURL != nil {
+					if serverURLManager_.CheckRedirect(serverURL, redirectedServerURL) {
+						c.options.Logger.Info().
+							Str("server_url", serverURL.String()).
+							Str("redirected_server_url", redirectedServerURL.String()).
+							Msg("client_redirected")
+					} else {
+						c.options.Logger.Warn().
+							Str("server_url", serverURL.String()).
+							Str("redirected_server_url", redirectedServerURL.String()).
+							Msg("client_redirect_refused")
+						redirectedServerURL = nil
+					}
+				}
+			}
+
+			if retryAfter := hangup.Reason.RetryAfter; retryAfter >= 1 && !c.options.CloseOnChannelError {
+				c.options.Logger.Info().
+					Str("server_url", serverURL.String()).
+					Dur("retry_after", retryAfter).
+					Msg("client_retry_deferred")
+				timer := timerpool.GetTimer(retryAfter)
+
+				select {
+				case <-c.ctx.Done():
+					timerpool.StopAndPutTimer(timer)
+					err = c.ctx.Err()
+					return
+				case <-timer.C:
+					timerpool.PutTimer(timer)
+				}
+
+				connectRetryCount = -1
+				continue
+			}
+
+			if hangup.Code == channel.HangupDrained || redirectedServerURL != nil {
+				connectRetryCount = -1
+				continue
+			}
 		}
 
 		if c.options.CloseOnChannelError {
@@ -144,14 +205,6 @@ func (c *Client) run() (err error) {
 	}
 }
 
-func (c *Client) getConnectTimeout() time.Duration {
-	if connectTimeout := c.options.ConnectTimeout; connectTimeout >= 1 {
-		return connectTimeout
-	}
-
-	return 0
-}
-
 var (
 	ErrNoValidServerURL      = errors.New("gogorpc/client: no valid server url")
 	ErrTooManyConnectRetries = errors.New("gogorpc/client: too many connect retries")
@@ -167,21 +220,9 @@ type serverURLManager struct {
 
 func (sum *serverURLManager) LoadServerURLs(rawServerURLs []string) error {
 	for _, rawServerURL := range rawServerURLs {
-		serverURL, err := url.Parse(rawServerURL)
+		serverURL, err := sum.ParseServerURL(rawServerURL)
 
 		if err != nil {
-			sum.Options.Logger.Warn().
-				Err(err).
-				Str("server_url", rawServerURL).
-				Msg("client_invalid_server_url")
-			continue
-		}
-
-		if _, err := GetConnector(serverURL.Scheme); err != nil {
-			sum.Options.Logger.Warn().
-				Err(err).
-				Str("server_url", rawServerURL).
-				Msg("client_invalid_server_url")
 			continue
 		}
 
@@ -201,6 +242,45 @@ func (sum *serverURLManager) LoadServerURLs(rawServerURLs []string) error {
 	return nil
 }
 
+func (sum *serverURLManager) ParseServerURL(rawServerURL string) (*url.URL, error) {
+	serverURL, err := url.Parse(rawServerURL)
+
+	if err != nil {
+		sum.Options.Logger.Warn().
+			Err(err).
+			Str("server_url", rawServerURL).
+			Msg("client_invalid_server_url")
+		return nil, err
+	}
+
+	if _, err := GetConnectorWithOptions(serverURL.Scheme); err != nil {
+		sum.Options.Logger.Warn().
+			Err(err).
+			Str("server_url", rawServerURL).
+			Msg("client_invalid_server_url")
+		return nil, err
+	}
+
+	return serverURL, nil
+}
+
+// CheckRedirect only allows a redirect to the host of a known server url with the scheme
+// currently in use, so that the peer can neither send the client elsewhere nor
+// downgrade its transport (e.g. tls:// to tcp://).
+func (sum *serverURLManager) CheckRedirect(serverURL *url.URL, redirectedServerURL *url.URL) bool {
+	if redirectedServerURL.Scheme != serverURL.Scheme {
+		return false
+	}
+
+	for _, serverURL2 := range sum.serverURLs {
+		if redirectedServerURL.Host == serverURL2.Host {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (sum *serverURLManager) GetNextServerURL(ctx context.Context, connectRetryCount int) (*url.URL, error) {
 	connectRetryOptions := &sum.Options.ConnectRetry
 
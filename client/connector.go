@@ -2,15 +2,36 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/url"
 	"time"
+
+	"github.com/let-z-go/gogorpc/internal/inproc"
+	"github.com/let-z-go/gogorpc/internal/mux"
+	"golang.org/x/net/websocket"
 )
 
 type Connector func(ctx context.Context, timeout time.Duration, serverURL *url.URL) (connection net.Conn, err error)
 
+// ConnectorWithOptions is a Connector which is given the whole client options rather
+// than the connect timeout only, e.g. for the tls settings.
+type ConnectorWithOptions func(ctx context.Context, options *Options, serverURL *url.URL) (connection net.Conn, err error)
+
 func RegisterConnector(schemeName string, connector Connector) error {
+	return RegisterConnectorWithOptions(schemeName, func(ctx context.Context, options *Options, serverURL *url.URL) (net.Conn, error) {
+		return connector(ctx, options.getConnectTimeout(), serverURL)
+	})
+}
+
+func MustRegisterConnector(schemeName string, connector Connector) {
+	if err := RegisterConnector(schemeName, connector); err != nil {
+		panic(err)
+	}
+}
+
+func RegisterConnectorWithOptions(schemeName string, connector ConnectorWithOptions) error {
 	if _, ok := connectors[schemeName]; ok {
 		return &ConnectorExistsError{fmt.Sprintf("schemeName=%#v", schemeName)}
 	}
@@ -19,13 +40,42 @@ func RegisterConnector(schemeName string, connector Connector) error {
 	return nil
 }
 
-func MustRegisterConnector(schemeName string, connector Connector) {
-	if err := RegisterConnector(schemeName, connector); err != nil {
+func MustRegisterConnectorWithOptions(schemeName string, connector ConnectorWithOptions) {
+	if err := RegisterConnectorWithOptions(schemeName, connector); err != nil {
 		panic(err)
 	}
 }
 
 func GetConnector(schemeName string) (Connector, error) {
+	connector, err := GetConnectorWithOptions(schemeName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
+		options := Options{ConnectTimeout: timeout}
+
+		if timeout == 0 {
+			// no timeout rather than the default one.
+			options.ConnectTimeout = -1
+		}
+
+		return connector(ctx, options.Normalize(), serverURL)
+	}, nil
+}
+
+func MustGetConnector(schemeName string) Connector {
+	connector, err := GetConnector(schemeName)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return connector
+}
+
+func GetConnectorWithOptions(schemeName string) (ConnectorWithOptions, error) {
 	connector, ok := connectors[schemeName]
 
 	if !ok {
@@ -35,8 +85,8 @@ func GetConnector(schemeName string) (Connector, error) {
 	return connector, nil
 }
 
-func MustGetConnector(schemeName string) Connector {
-	connector, err := GetConnector(schemeName)
+func MustGetConnectorWithOptions(schemeName string) ConnectorWithOptions {
+	connector, err := GetConnectorWithOptions(schemeName)
 
 	if err != nil {
 		panic(err)
@@ -73,7 +123,7 @@ func (cnfe ConnectorNotFoundError) Error() string {
 	return message
 }
 
-var connectors = map[string]Connector{}
+var connectors = map[string]ConnectorWithOptions{}
 
 func tcpConnector(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
 	return (&net.Dialer{
@@ -81,6 +131,164 @@ func tcpConnector(ctx context.Context, timeout time.Duration, serverURL *url.URL
 	}).DialContext(ctx, "tcp", serverURL.Host)
 }
 
+func tlsConnector(ctx context.Context, options *Options, serverURL *url.URL) (net.Conn, error) {
+	if connectTimeout := options.getConnectTimeout(); connectTimeout >= 1 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, connectTimeout)
+		defer cancel()
+	}
+
+	return dialTLS(ctx, options.TLS.config, serverURL.Host, serverURL.Hostname())
+}
+
+func dialTLS(ctx context.Context, config *tls.Config, address string, serverName string) (*tls.Conn, error) {
+	connection, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ServerName == "" {
+		config = config.Clone()
+		config.ServerName = serverName
+	}
+
+	tlsConnection := tls.Client(connection, config)
+
+	if err := tlsConnection.HandshakeContext(ctx); err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	return tlsConnection, nil
+}
+
+func unixConnector(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
+	return (&net.Dialer{
+		Timeout: timeout,
+	}).DialContext(ctx, "unix", serverURL.Host+serverURL.Path)
+}
+
+func unixAbstractConnector(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
+	return (&net.Dialer{
+		Timeout: timeout,
+	}).DialContext(ctx, "unix", "@"+serverURL.Host+serverURL.Path)
+}
+
+func inprocConnector(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
+	if timeout >= 1 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return inproc.Dial(ctx, serverURL.Host+serverURL.Path)
+}
+
+func webSocketConnector(ctx context.Context, options *Options, serverURL *url.URL) (net.Conn, error) {
+	return dialWebSocket(ctx, options, serverURL, "http", nil)
+}
+
+func webSocketSecureConnector(ctx context.Context, options *Options, serverURL *url.URL) (net.Conn, error) {
+	return dialWebSocket(ctx, options, serverURL, "https", options.TLS.config)
+}
+
+func dialWebSocket(ctx context.Context, options *Options, serverURL *url.URL, originScheme string, tlsConfig *tls.Config) (net.Conn, error) {
+	origin := url.URL{Scheme: originScheme, Host: serverURL.Host}
+	config, err := websocket.NewConfig(serverURL.String(), origin.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if connectTimeout := options.getConnectTimeout(); connectTimeout >= 1 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, connectTimeout)
+		defer cancel()
+	}
+
+	if tlsConfig == nil {
+		connection, err := config.DialContext(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		connection.PayloadType = websocket.BinaryFrame
+		return connection, nil
+	}
+
+	// dial tls by hand so that the peer certificate stays reachable.
+	address := serverURL.Host
+
+	if serverURL.Port() == "" {
+		address = net.JoinHostPort(serverURL.Hostname(), "443")
+	}
+
+	tlsConnection, err := dialTLS(ctx, tlsConfig, address, serverURL.Hostname())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		tlsConnection.SetDeadline(deadline)
+	}
+
+	connection, err := websocket.NewClient(config, tlsConnection)
+
+	if err != nil {
+		tlsConnection.Close()
+		return nil, err
+	}
+
+	tlsConnection.SetDeadline(time.Time{})
+	connection.PayloadType = websocket.BinaryFrame
+	return &webSocketSecureConnection{connection, tlsConnection}, nil
+}
+
+type webSocketSecureConnection struct {
+	*websocket.Conn
+
+	tlsConnection *tls.Conn
+}
+
+func (wssc *webSocketSecureConnection) ConnectionState() tls.ConnectionState {
+	return wssc.tlsConnection.ConnectionState()
+}
+
+func muxConnector(underlyingConnector ConnectorWithOptions) ConnectorWithOptions {
+	return func(ctx context.Context, options *Options, serverURL *url.URL) (net.Conn, error) {
+		// clients with the same options and server url share one underlying connection.
+		conn, err := muxSessionPool.Open(ctx, muxSessionKey{options, serverURL.String()}, func(ctx context.Context) (net.Conn, error) {
+			return underlyingConnector(ctx, options, serverURL)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	}
+}
+
+var muxSessionPool mux.SessionPool
+
+type muxSessionKey struct {
+	Options   *Options
+	ServerURL string
+}
+
 func init() {
 	MustRegisterConnector("tcp", tcpConnector)
+	MustRegisterConnectorWithOptions("tls", tlsConnector)
+	MustRegisterConnector("unix", unixConnector)
+	MustRegisterConnector("unix-abstract", unixAbstractConnector)
+	MustRegisterConnector("inproc", inprocConnector)
+	MustRegisterConnectorWithOptions("ws", webSocketConnector)
+	MustRegisterConnectorWithOptions("wss", webSocketSecureConnector)
+
+	for _, schemeName := range [...]string{"tcp", "tls", "unix", "unix-abstract", "ws", "wss"} {
+		MustRegisterConnectorWithOptions("mux+"+schemeName, muxConnector(MustGetConnectorWithOptions(schemeName)))
+	}
 }
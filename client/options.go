@@ -1,10 +1,12 @@
 package client
 
 import (
+	"crypto/tls"
 	"sync"
 	"time"
 
 	"github.com/let-z-go/gogorpc/channel"
+	"github.com/let-z-go/gogorpc/internal/certloader"
 	"github.com/rs/zerolog"
 )
 
@@ -15,6 +17,7 @@ type Options struct {
 	CloseOnChannelError bool
 	WithoutConnectRetry bool
 	ConnectRetry        ConnectRetryOptions
+	TLS                 TLSOptions
 
 	normalizeOnce sync.Once
 }
@@ -38,11 +41,21 @@ func (o *Options) Normalize() *Options {
 		if !o.WithoutConnectRetry {
 			o.ConnectRetry.normalize()
 		}
+
+		o.TLS.normalize()
 	})
 
 	return o
 }
 
+func (o *Options) getConnectTimeout() time.Duration {
+	if connectTimeout := o.ConnectTimeout; connectTimeout >= 1 {
+		return connectTimeout
+	}
+
+	return 0
+}
+
 type ConnectRetryOptions struct {
 	MaxCount             int
 	WithoutBackoff       bool
@@ -64,6 +77,30 @@ func (cro *ConnectRetryOptions) normalize() {
 	}
 }
 
+type TLSOptions struct {
+	Config   *tls.Config
+	CertFile string
+	KeyFile  string
+
+	config *tls.Config
+}
+
+func (to *TLSOptions) normalize() {
+	if to.Config == nil {
+		to.config = &tls.Config{}
+	} else {
+		to.config = to.Config.Clone()
+	}
+
+	if to.CertFile != "" {
+		certLoader := new(certloader.CertLoader).Init(to.CertFile, to.KeyFile)
+
+		to.config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return certLoader.GetCert()
+		}
+	}
+}
+
 const defaultConnectTimeout = 3 * time.Second
 
 const (
@@ -407,7 +407,8 @@ type service struct {
 
 	Methods []*method
 
-	FullName string
+	FullName            string
+	HasStreamingMethods bool
 }
 
 func (s *service) Load(context_ *context, raw *descriptor.ServiceDescriptorProto) {
@@ -435,6 +436,10 @@ func (s *service) Resolve(context_ *context) {
 		context_.EnterNode(method_, func() {
 			method_.Resolve(context_)
 		})
+
+		if method_.IsStreaming() {
+			s.HasStreamingMethods = true
+		}
 	}
 }
 
@@ -452,15 +457,27 @@ const (
 
 type {{.Name}} interface {
 {{- range .Methods}}
+	{{- if .IsStreaming}}
 	{{.Name}}(ctx context.Context
-	{{- if .Request}}
+		{{- if not .ClientStreaming}}
 		{{- ", request *"}}{{.Request.GoMessagePath}}
-	{{- end}}
-	{{- ") ("}}
-	{{- if .Response}}
+		{{- end}}
+		{{- ", stream "}}{{$.Name}}_{{.Name}}ServerStream) (
+		{{- if not .ServerStreaming}}
 		{{- "response *"}}{{.Response.GoMessagePath}},{{" "}}
+		{{- end}}
+		{{- "err error)"}}
+	{{- else}}
+	{{.Name}}(ctx context.Context
+		{{- if .Request}}
+		{{- ", request *"}}{{.Request.GoMessagePath}}
+		{{- end}}
+		{{- ") ("}}
+		{{- if .Response}}
+		{{- "response *"}}{{.Response.GoMessagePath}},{{" "}}
+		{{- end}}
+		{{- "err error)"}}
 	{{- end}}
-	{{- "err error)"}}
 {{- end}}
 }
 
@@ -474,10 +491,51 @@ func Implement{{.Name}}(service {{.Name}}) func(*channel.Options) {
 				{{- "."}}
 			{{- end}}
 			BuildMethod(Service{{$.Name}}, {{$.Name}}_{{.Name}}).
+	{{- if .IsStreaming}}
+			SetStreamRequestFactory({{$.Name}}_New{{.Name}}Request).
+			SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		{{- if not .ClientStreaming}}
+				request, err := rpc.Stream().Receive(rpc.Ctx)
+
+				if err != nil {
+					if err == io.EOF {
+						err = channel.RPCErrBadRequest
+					}
+
+					rpc.Response = channel.NullMessage
+					rpc.Err = err
+					return
+				}
+
+				rpc.Response = channel.NullMessage
+				rpc.Err = service.{{.Name}}(rpc.Ctx, request.(*{{.Request.GoMessagePath}}), {{$.Name}}_{{.Name}}ServerStream{rpc.Stream()})
+		{{- else if not .ServerStreaming}}
+				response, err := service.{{.Name}}(rpc.Ctx, {{$.Name}}_{{.Name}}ServerStream{rpc.Stream()})
+
+				if err == nil {
+					if response == nil {
+						err = rpc.Stream().Send(rpc.Ctx, channel.NullMessage)
+					} else {
+						err = rpc.Stream().Send(rpc.Ctx, response)
+					}
+				}
+
+				rpc.Response = channel.NullMessage
+				rpc.Err = err
+		{{- else}}
+				rpc.Response = channel.NullMessage
+				rpc.Err = service.{{.Name}}(rpc.Ctx, {{$.Name}}_{{.Name}}ServerStream{rpc.Stream()})
+		{{- end}}
+			}).
+	{{- else}}
 	{{- if .Request}}
 			SetRequestFactory({{$.Name}}_New{{.Name}}Request).
 	{{- end}}
+	{{- if .IsOneWay}}
+			SetIncomingNotificationHandler(func(rpc *channel.RPC) {
+	{{- else}}
 			SetIncomingRPCHandler(func(rpc *channel.RPC) {
+	{{- end}}
 	{{- if .Response}}
 				response, err := service.{{.Name}}(rpc.Ctx
 		{{- if .Request}}
@@ -501,6 +559,7 @@ func Implement{{.Name}}(service {{.Name}}) func(*channel.Options) {
 		{{- ")"}}
 	{{- end}}
 			}).
+	{{- end}}
 			End()
 {{- end}}
 	}
@@ -509,9 +568,12 @@ func Implement{{.Name}}(service {{.Name}}) func(*channel.Options) {
 type {{.Name}}Stub struct {
 	rpcPreparer channel.RPCPreparer
 	requestExtraData channel.ExtraData
+	priority int32
 }
+{{- if not .HasStreamingMethods}}
 
 var _ = {{.Name}}({{.Name}}Stub{})
+{{- end}}
 
 func (ss *{{.Name}}Stub) Init(rpcPreparer channel.RPCPreparer) *{{.Name}}Stub {
 	ss.rpcPreparer = rpcPreparer
@@ -522,7 +584,46 @@ func (ss *{{.Name}}Stub) WithRequestExtraData(extraData channel.ExtraData) *{{.N
 	ss.requestExtraData = extraData
 	return ss
 }
+
+func (ss *{{.Name}}Stub) WithPriority(priority int32) *{{.Name}}Stub {
+	ss.priority = priority
+	return ss
+}
 {{- range .Methods}}
+{{- if .IsStreaming}}
+
+func (ss {{$.Name}}Stub) {{.Name}}(ctx context.Context
+	{{- if not .ClientStreaming}}
+		{{- ", request *"}}{{.Request.GoMessagePath}}
+	{{- end}}
+	{{- ") ("}}{{$.Name}}_{{.Name}}Stream, error) {
+	rpc := channel.GetPooledRPC()
+
+	*rpc = channel.RPC{
+		Ctx: ctx,
+		ServiceName: Service{{$.Name}},
+		MethodName: {{$.Name}}_{{.Name}},
+		Priority: ss.priority,
+		RequestExtraData: ss.requestExtraData.Ref(true),
+	}
+
+	stream := {{$.Name}}_{{.Name}}Stream{channel.PrepareRPCStream(ss.rpcPreparer, rpc, {{$.Name}}_New{{.Name}}Response)}
+	stream.underlying.Open()
+	{{- if not .ClientStreaming}}
+
+	if err := stream.underlying.Send(ctx, request); err != nil {
+		stream.Close()
+		return {{$.Name}}_{{.Name}}Stream{}, err
+	}
+
+	if err := stream.underlying.CloseSend(ctx); err != nil {
+		stream.Close()
+		return {{$.Name}}_{{.Name}}Stream{}, err
+	}
+{{end}}
+	return stream, nil
+}
+{{- else}}
 
 func (ss {{$.Name}}Stub) {{.Name}}(ctx context.Context
 	{{- if .Request}}
@@ -562,23 +663,116 @@ func (ss {{$.Name}}Stub) Make{{.Name}}RPC(ctx context.Context
 		Ctx: ctx,
 		ServiceName: Service{{$.Name}},
 		MethodName: {{$.Name}}_{{.Name}},
+		Priority: ss.priority,
 		RequestExtraData: ss.requestExtraData.Ref(true),
 	{{- if .Request}}
 		Request: request,
 	{{- end}}
 	}
 
-	ss.rpcPreparer.PrepareRPC(rpc,{{" "}}
-	{{- if .Response}}
-		{{- $.Name}}_New{{.Name}}Response
+	{{- if .IsOneWay}}
+
+	channel.PrepareNotification(ss.rpcPreparer, rpc)
 	{{- else}}
-		{{- "channel.GetNullMessage"}}
+
+	ss.rpcPreparer.PrepareRPC(rpc,{{" "}}
+		{{- if .Response}}
+			{{- $.Name}}_New{{.Name}}Response
+		{{- else}}
+			{{- "channel.GetNullMessage"}}
+		{{- end}}
+		{{- ")"}}
 	{{- end}}
-	{{- ")"}}
 	return {{$.Name}}_{{.Name}}RPC{rpc}
 }
 {{- end}}
+{{- end}}
 {{- range .Methods}}
+{{- if .IsStreaming}}
+
+type {{$.Name}}_{{.Name}}Stream struct {
+	underlying *channel.RPCStream
+}
+	{{- if .ClientStreaming}}
+
+func (ms {{$.Name}}_{{.Name}}Stream) Send(ctx context.Context, request *{{.Request.GoMessagePath}}) error {
+	return ms.underlying.Send(ctx, request)
+}
+
+func (ms {{$.Name}}_{{.Name}}Stream) CloseSend(ctx context.Context) error {
+	return ms.underlying.CloseSend(ctx)
+}
+	{{- end}}
+	{{- if .ServerStreaming}}
+
+func (ms {{$.Name}}_{{.Name}}Stream) Receive(ctx context.Context) (*{{.Response.GoMessagePath}}, error) {
+	response, err := ms.underlying.Receive(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*{{.Response.GoMessagePath}}), nil
+}
+	{{- else}}
+
+func (ms {{$.Name}}_{{.Name}}Stream) CloseAndReceive(ctx context.Context) (*{{.Response.GoMessagePath}}, error) {
+	if err := ms.underlying.CloseSend(ctx); err != nil {
+		return nil, err
+	}
+
+	response, err := ms.underlying.Receive(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, err := ms.underlying.Receive(ctx); err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+
+			return response.(*{{.Response.GoMessagePath}}), nil
+		}
+	}
+}
+	{{- end}}
+
+func (ms {{$.Name}}_{{.Name}}Stream) Close() {
+	ms.underlying.Close()
+}
+
+func (ms {{$.Name}}_{{.Name}}Stream) RequestExtraData() channel.ExtraDataRef {
+	return ms.underlying.RPC().RequestExtraData
+}
+
+func (ms {{$.Name}}_{{.Name}}Stream) ResponseExtraData() channel.ExtraDataRef {
+	return ms.underlying.RPC().ResponseExtraData
+}
+
+type {{$.Name}}_{{.Name}}ServerStream struct {
+	underlying *channel.RPCStream
+}
+	{{- if .ClientStreaming}}
+
+func (ms {{$.Name}}_{{.Name}}ServerStream) Receive(ctx context.Context) (*{{.Request.GoMessagePath}}, error) {
+	request, err := ms.underlying.Receive(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return request.(*{{.Request.GoMessagePath}}), nil
+}
+	{{- end}}
+	{{- if .ServerStreaming}}
+
+func (ms {{$.Name}}_{{.Name}}ServerStream) Send(ctx context.Context, response *{{.Response.GoMessagePath}}) error {
+	return ms.underlying.Send(ctx, response)
+}
+	{{- end}}
+{{- else}}
 
 type {{$.Name}}_{{.Name}}RPC struct {
 	underlying *channel.RPC
@@ -589,6 +783,11 @@ func (mr {{$.Name}}_{{.Name}}RPC) WithRequestExtraData(extraData channel.ExtraDa
 	return mr
 }
 
+func (mr {{$.Name}}_{{.Name}}RPC) WithPriority(priority int32) {{$.Name}}_{{.Name}}RPC {
+	mr.underlying.Priority = priority
+	return mr
+}
+
 func (mr {{$.Name}}_{{.Name}}RPC) Do() {{$.Name}}_{{.Name}}RPC {
 	if mr.underlying.IsHandled() {
 		mr.underlying.Reprepare()
@@ -629,6 +828,7 @@ func (mr {{$.Name}}_{{.Name}}RPC) ResponseExtraData() channel.ExtraDataRef {
 	return mr.underlying.ResponseExtraData
 }
 {{- end}}
+{{- end}}
 {{- range .Methods}}
 {{- if .Request}}
 
@@ -659,11 +859,29 @@ func (s *service) GetNodeNameDelimiter() string {
 type method struct {
 	Name string
 
-	Request  *reqresp
-	Response *reqresp
+	Request         *reqresp
+	Response        *reqresp
+	ClientStreaming bool
+	ServerStreaming bool
+	IsOneWay        bool
 }
 
 func (m *method) Load(context_ *context, raw *descriptor.MethodDescriptorProto) {
+	m.ClientStreaming = raw.GetClientStreaming()
+	m.ServerStreaming = raw.GetServerStreaming()
+
+	if raw.Options != nil {
+		extension, err := proto.GetExtension(raw.Options, gogorpc.E_OneWay)
+
+		if err == nil {
+			m.IsOneWay = *extension.(*bool)
+		}
+	}
+
+	if m.IsOneWay && m.IsStreaming() {
+		context_.Fatal("invalid option `gogorpc.one_way`: streaming rpc")
+	}
+
 	request := reqresp{}
 	request.Load(raw.GetInputType())
 	m.Request = &request
@@ -676,6 +894,24 @@ func (m *method) Resolve(context_ *context) {
 	inputFile_ := context_.Nodes[len(context_.Nodes)-3].(*inputFile)
 	inputFile_.ImportGoPackage("context", "context")
 
+	if m.IsOneWay && !(m.Response.PackageName == "gogorpc" && m.Response.MessageName == "Void") {
+		context_.Fatal("invalid option `gogorpc.one_way`: response type other than `gogorpc.Void`")
+	}
+
+	if m.IsStreaming() {
+		if m.Request.PackageName == "gogorpc" && m.Request.MessageName == "Void" {
+			context_.Fatal("streaming rpc with request type `gogorpc.Void`")
+		}
+
+		if m.Response.PackageName == "gogorpc" && m.Response.MessageName == "Void" {
+			context_.Fatal("streaming rpc with response type `gogorpc.Void`")
+		}
+
+		if m.ClientStreaming != m.ServerStreaming {
+			inputFile_.ImportGoPackage("io", "io")
+		}
+	}
+
 	switch packageName, messageName := m.Request.PackageName, m.Request.MessageName; {
 	case packageName == "gogorpc" && messageName == "Void":
 		m.Request = nil
@@ -705,6 +941,10 @@ func (m *method) Resolve(context_ *context) {
 	}
 }
 
+func (m *method) IsStreaming() bool {
+	return m.ClientStreaming || m.ServerStreaming
+}
+
 func (m *method) GetNodeName() string {
 	return m.Name
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/let-z-go/gogorpc/client"
 	"github.com/let-z-go/gogorpc/examples/helloworld/proto"
@@ -34,4 +35,41 @@ func main() {
 			}
 		}
 	}
+
+	stream, err := stub.SayHello4(context.Background())
+
+	if err != nil {
+		fmt.Println("err:", err)
+		return
+	}
+
+	defer stream.Close()
+
+	for _, name := range []string{"tom", "jerry"} {
+		if err := stream.Send(context.Background(), &proto.SayHelloReq{
+			Name: name,
+		}); err != nil {
+			fmt.Println("err:", err)
+			return
+		}
+	}
+
+	if err := stream.CloseSend(context.Background()); err != nil {
+		fmt.Println("err:", err)
+		return
+	}
+
+	for {
+		resp, err := stream.Receive(context.Background())
+
+		if err != nil {
+			if err != io.EOF {
+				fmt.Println("err:", err)
+			}
+
+			break
+		}
+
+		fmt.Println("resp:", resp)
+	}
 }
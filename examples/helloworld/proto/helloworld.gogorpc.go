@@ -17,12 +17,14 @@ const (
 	Greeter_SayHello  = "SayHello"
 	Greeter_SayHello2 = "SayHello2"
 	Greeter_SayHello3 = "SayHello3"
+	Greeter_SayHello4 = "SayHello4"
 )
 
 type Greeter interface {
 	SayHello(ctx context.Context, request *SayHelloReq) (response *SayHelloResp, err error)
 	SayHello2(ctx context.Context, request *SayHelloReq) (err error)
 	SayHello3(ctx context.Context) (response *SayHelloResp, err error)
+	SayHello4(ctx context.Context, stream Greeter_SayHello4ServerStream) (err error)
 }
 
 func ImplementGreeter(service Greeter) func(*channel.Options) {
@@ -61,6 +63,13 @@ func ImplementGreeter(service Greeter) func(*channel.Options) {
 
 				rpc.Err = err
 			}).
+			End().
+			BuildMethod(ServiceGreeter, Greeter_SayHello4).
+			SetStreamRequestFactory(Greeter_NewSayHello4Request).
+			SetIncomingRPCHandler(func(rpc *channel.RPC) {
+				rpc.Response = channel.NullMessage
+				rpc.Err = service.SayHello4(rpc.Ctx, Greeter_SayHello4ServerStream{rpc.Stream()})
+			}).
 			End()
 	}
 }
@@ -68,10 +77,9 @@ func ImplementGreeter(service Greeter) func(*channel.Options) {
 type GreeterStub struct {
 	rpcPreparer      channel.RPCPreparer
 	requestExtraData channel.ExtraData
+	priority         int32
 }
 
-var _ = Greeter(GreeterStub{})
-
 func (ss *GreeterStub) Init(rpcPreparer channel.RPCPreparer) *GreeterStub {
 	ss.rpcPreparer = rpcPreparer
 	return ss
@@ -82,6 +90,11 @@ func (ss *GreeterStub) WithRequestExtraData(extraData channel.ExtraData) *Greete
 	return ss
 }
 
+func (ss *GreeterStub) WithPriority(priority int32) *GreeterStub {
+	ss.priority = priority
+	return ss
+}
+
 func (ss GreeterStub) SayHello(ctx context.Context, request *SayHelloReq) (*SayHelloResp, error) {
 	rpc := ss.MakeSayHelloRPC(ctx, request).Do()
 	response, err := rpc.Result()
@@ -96,6 +109,7 @@ func (ss GreeterStub) MakeSayHelloRPC(ctx context.Context, request *SayHelloReq)
 		Ctx:              ctx,
 		ServiceName:      ServiceGreeter,
 		MethodName:       Greeter_SayHello,
+		Priority:         ss.priority,
 		RequestExtraData: ss.requestExtraData.Ref(true),
 		Request:          request,
 	}
@@ -118,6 +132,7 @@ func (ss GreeterStub) MakeSayHello2RPC(ctx context.Context, request *SayHelloReq
 		Ctx:              ctx,
 		ServiceName:      ServiceGreeter,
 		MethodName:       Greeter_SayHello2,
+		Priority:         ss.priority,
 		RequestExtraData: ss.requestExtraData.Ref(true),
 		Request:          request,
 	}
@@ -140,6 +155,7 @@ func (ss GreeterStub) MakeSayHello3RPC(ctx context.Context) Greeter_SayHello3RPC
 		Ctx:              ctx,
 		ServiceName:      ServiceGreeter,
 		MethodName:       Greeter_SayHello3,
+		Priority:         ss.priority,
 		RequestExtraData: ss.requestExtraData.Ref(true),
 	}
 
@@ -147,6 +163,22 @@ func (ss GreeterStub) MakeSayHello3RPC(ctx context.Context) Greeter_SayHello3RPC
 	return Greeter_SayHello3RPC{rpc}
 }
 
+func (ss GreeterStub) SayHello4(ctx context.Context) (Greeter_SayHello4Stream, error) {
+	rpc := channel.GetPooledRPC()
+
+	*rpc = channel.RPC{
+		Ctx:              ctx,
+		ServiceName:      ServiceGreeter,
+		MethodName:       Greeter_SayHello4,
+		Priority:         ss.priority,
+		RequestExtraData: ss.requestExtraData.Ref(true),
+	}
+
+	stream := Greeter_SayHello4Stream{channel.PrepareRPCStream(ss.rpcPreparer, rpc, Greeter_NewSayHello4Response)}
+	stream.underlying.Open()
+	return stream, nil
+}
+
 type Greeter_SayHelloRPC struct {
 	underlying *channel.RPC
 }
@@ -156,6 +188,11 @@ func (mr Greeter_SayHelloRPC) WithRequestExtraData(extraData channel.ExtraDataRe
 	return mr
 }
 
+func (mr Greeter_SayHelloRPC) WithPriority(priority int32) Greeter_SayHelloRPC {
+	mr.underlying.Priority = priority
+	return mr
+}
+
 func (mr Greeter_SayHelloRPC) Do() Greeter_SayHelloRPC {
 	if mr.underlying.IsHandled() {
 		mr.underlying.Reprepare()
@@ -195,6 +232,11 @@ func (mr Greeter_SayHello2RPC) WithRequestExtraData(extraData channel.ExtraDataR
 	return mr
 }
 
+func (mr Greeter_SayHello2RPC) WithPriority(priority int32) Greeter_SayHello2RPC {
+	mr.underlying.Priority = priority
+	return mr
+}
+
 func (mr Greeter_SayHello2RPC) Do() Greeter_SayHello2RPC {
 	if mr.underlying.IsHandled() {
 		mr.underlying.Reprepare()
@@ -230,6 +272,11 @@ func (mr Greeter_SayHello3RPC) WithRequestExtraData(extraData channel.ExtraDataR
 	return mr
 }
 
+func (mr Greeter_SayHello3RPC) WithPriority(priority int32) Greeter_SayHello3RPC {
+	mr.underlying.Priority = priority
+	return mr
+}
+
 func (mr Greeter_SayHello3RPC) Do() Greeter_SayHello3RPC {
 	if mr.underlying.IsHandled() {
 		mr.underlying.Reprepare()
@@ -260,6 +307,58 @@ func (mr Greeter_SayHello3RPC) ResponseExtraData() channel.ExtraDataRef {
 	return mr.underlying.ResponseExtraData
 }
 
+type Greeter_SayHello4Stream struct {
+	underlying *channel.RPCStream
+}
+
+func (ms Greeter_SayHello4Stream) Send(ctx context.Context, request *SayHelloReq) error {
+	return ms.underlying.Send(ctx, request)
+}
+
+func (ms Greeter_SayHello4Stream) CloseSend(ctx context.Context) error {
+	return ms.underlying.CloseSend(ctx)
+}
+
+func (ms Greeter_SayHello4Stream) Receive(ctx context.Context) (*SayHelloResp, error) {
+	response, err := ms.underlying.Receive(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*SayHelloResp), nil
+}
+
+func (ms Greeter_SayHello4Stream) Close() {
+	ms.underlying.Close()
+}
+
+func (ms Greeter_SayHello4Stream) RequestExtraData() channel.ExtraDataRef {
+	return ms.underlying.RPC().RequestExtraData
+}
+
+func (ms Greeter_SayHello4Stream) ResponseExtraData() channel.ExtraDataRef {
+	return ms.underlying.RPC().ResponseExtraData
+}
+
+type Greeter_SayHello4ServerStream struct {
+	underlying *channel.RPCStream
+}
+
+func (ms Greeter_SayHello4ServerStream) Receive(ctx context.Context) (*SayHelloReq, error) {
+	request, err := ms.underlying.Receive(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return request.(*SayHelloReq), nil
+}
+
+func (ms Greeter_SayHello4ServerStream) Send(ctx context.Context, response *SayHelloResp) error {
+	return ms.underlying.Send(ctx, response)
+}
+
 func Greeter_NewSayHelloRequest() channel.Message {
 	return new(SayHelloReq)
 }
@@ -275,3 +374,11 @@ func Greeter_NewSayHello2Request() channel.Message {
 func Greeter_NewSayHello3Response() channel.Message {
 	return new(SayHelloResp)
 }
+
+func Greeter_NewSayHello4Request() channel.Message {
+	return new(SayHelloReq)
+}
+
+func Greeter_NewSayHello4Response() channel.Message {
+	return new(SayHelloResp)
+}
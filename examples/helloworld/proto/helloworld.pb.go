@@ -121,7 +121,7 @@ func init() {
 }
 
 var fileDescriptor_80a5b9c62924e902 = []byte{
-	// 271 bytes of a gzipped FileDescriptorProto
+	// 281 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x72, 0x4b, 0xcf, 0x2c, 0xc9,
 	0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0xcf, 0x49, 0x2d, 0xd1, 0xad, 0xd2, 0x4d, 0xcf, 0xd7,
 	0x4f, 0xcf, 0x4f, 0xcf, 0x2f, 0x2a, 0x48, 0xd6, 0x4f, 0xad, 0x48, 0xcc, 0x2d, 0xc8, 0x49, 0x2d,
@@ -130,15 +130,16 @@ var fileDescriptor_80a5b9c62924e902 = []byte{
 	0x19, 0x0e, 0xa5, 0x21, 0xfa, 0x95, 0x14, 0xb9, 0xb8, 0x83, 0x13, 0x2b, 0x3d, 0x40, 0x7a, 0x83,
 	0x52, 0x0b, 0x85, 0x84, 0xb8, 0x58, 0xf2, 0x12, 0x73, 0x53, 0x25, 0x18, 0x15, 0x18, 0x35, 0x38,
 	0x83, 0xc0, 0x6c, 0x25, 0x0d, 0x2e, 0x1e, 0x84, 0x92, 0xe2, 0x02, 0x21, 0x09, 0x2e, 0xf6, 0xdc,
-	0xd4, 0xe2, 0xe2, 0xc4, 0x74, 0x98, 0x32, 0x18, 0xd7, 0xe8, 0x3a, 0x23, 0x17, 0xbb, 0x7b, 0x51,
+	0xd4, 0xe2, 0xe2, 0xc4, 0x74, 0x98, 0x32, 0x18, 0xd7, 0x68, 0x2d, 0x13, 0x17, 0xbb, 0x7b, 0x51,
 	0x6a, 0x6a, 0x49, 0x6a, 0x91, 0x90, 0x3f, 0x17, 0x07, 0x4c, 0x97, 0x90, 0x82, 0x1e, 0x16, 0x57,
 	0xea, 0x21, 0xd9, 0x2b, 0xa5, 0x48, 0x40, 0x45, 0x71, 0x81, 0x90, 0x0d, 0x17, 0x27, 0x8c, 0x6f,
 	0x44, 0x84, 0x89, 0xbc, 0x7a, 0x30, 0x8f, 0x86, 0xe5, 0x67, 0xa6, 0x08, 0xd9, 0x22, 0x74, 0x1b,
-	0x0b, 0xa1, 0xca, 0x11, 0x61, 0xb9, 0x53, 0xea, 0x89, 0x47, 0x72, 0x8c, 0x17, 0x1e, 0xc9, 0x31,
-	0x3e, 0x78, 0x24, 0xc7, 0x38, 0xe1, 0xb1, 0x1c, 0xc3, 0x85, 0xc7, 0x72, 0x0c, 0x37, 0x1e, 0xcb,
-	0x31, 0x34, 0xfd, 0xd4, 0x16, 0xe2, 0xe2, 0x75, 0xcb, 0x2f, 0x4a, 0xca, 0x4c, 0x49, 0x49, 0xcd,
-	0xf3, 0x4b, 0xcc, 0x4d, 0x15, 0x98, 0xcc, 0x1c, 0x65, 0x4a, 0x56, 0xf4, 0x26, 0xb1, 0x81, 0x29,
-	0x63, 0x40, 0x00, 0x00, 0x00, 0xff, 0xff, 0x7f, 0x11, 0x57, 0x71, 0x1e, 0x02, 0x00, 0x00,
+	0x0b, 0xa1, 0xca, 0x11, 0x63, 0x79, 0x08, 0x42, 0xbb, 0x09, 0x55, 0xbc, 0xa3, 0xc1, 0x68, 0xc0,
+	0xe8, 0x94, 0x7a, 0xe2, 0x91, 0x1c, 0xe3, 0x85, 0x47, 0x72, 0x8c, 0x0f, 0x1e, 0xc9, 0x31, 0x4e,
+	0x78, 0x2c, 0xc7, 0x70, 0xe1, 0xb1, 0x1c, 0xc3, 0x8d, 0xc7, 0x72, 0x0c, 0x4d, 0x3f, 0xb5, 0x85,
+	0xb8, 0x78, 0xdd, 0xf2, 0x8b, 0x92, 0x32, 0x53, 0x52, 0x52, 0xf3, 0xfc, 0x12, 0x73, 0x53, 0x05,
+	0x26, 0x33, 0x47, 0x99, 0x92, 0x95, 0x68, 0x92, 0xd8, 0xc0, 0x94, 0x31, 0x20, 0x00, 0x00, 0xff,
+	0xff, 0x05, 0x17, 0xdc, 0xc4, 0x74, 0x02, 0x00, 0x00,
 }
 
 func (m *SayHelloReq) Marshal() (dAtA []byte, err error) {
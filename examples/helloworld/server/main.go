@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"io"
 
 	"github.com/let-z-go/gogorpc/channel"
 	"github.com/let-z-go/gogorpc/examples/helloworld/proto"
@@ -28,6 +29,26 @@ func (Greeter) SayHello3(ctx context.Context) (*proto.SayHelloResp, error) {
 	return nil, channel.RPCErrNotImplemented
 }
 
+func (Greeter) SayHello4(ctx context.Context, stream proto.Greeter_SayHello4ServerStream) error {
+	for {
+		request, err := stream.Receive(ctx)
+
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := stream.Send(ctx, &proto.SayHelloResp{
+			Message: "Hello " + request.Name,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
 func main() {
 	opts := server.Options{
 		Channel: (&channel.Options{}).
@@ -121,10 +121,20 @@ var E_Error = &proto.ExtensionDesc{
 	Filename:      "github.com/let-z-go/gogorpc/gogorpc.proto",
 }
 
+var E_OneWay = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MethodOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         90001,
+	Name:          "gogorpc.one_way",
+	Tag:           "varint,90001,opt,name=one_way,json=oneWay",
+	Filename:      "github.com/let-z-go/gogorpc/gogorpc.proto",
+}
+
 func init() {
 	proto.RegisterType((*Void)(nil), "gogorpc.Void")
 	proto.RegisterType((*Error)(nil), "gogorpc.Error")
 	proto.RegisterExtension(E_Error)
+	proto.RegisterExtension(E_OneWay)
 }
 
 func init() {
@@ -132,7 +142,7 @@ func init() {
 }
 
 var fileDescriptor_71af08cebd7aada9 = []byte{
-	// 224 bytes of a gzipped FileDescriptorProto
+	// 263 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xd2, 0x4c, 0xcf, 0x2c, 0xc9,
 	0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0xcf, 0x49, 0x2d, 0xd1, 0xad, 0xd2, 0x4d, 0xcf, 0xd7,
 	0x4f, 0xcf, 0x4f, 0xcf, 0x2f, 0x2a, 0x48, 0x86, 0xd1, 0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42,
@@ -144,9 +154,12 @@ var fileDescriptor_71af08cebd7aada9 = []byte{
 	0x83, 0x15, 0x26, 0x03, 0x12, 0xb1, 0x72, 0xe1, 0x62, 0x4d, 0x05, 0x6b, 0x96, 0xd1, 0x83, 0x58,
 	0xa8, 0x07, 0xb3, 0x50, 0xcf, 0x2d, 0x33, 0x27, 0xd5, 0xbf, 0xa0, 0x24, 0x33, 0x3f, 0xaf, 0x58,
 	0x62, 0xc2, 0x7e, 0x56, 0x05, 0x66, 0x0d, 0x6e, 0x23, 0x3e, 0x3d, 0x98, 0x73, 0xc1, 0x56, 0x06,
-	0x41, 0x34, 0x3b, 0x99, 0x9e, 0x78, 0x24, 0xc7, 0x78, 0xe1, 0x91, 0x1c, 0xe3, 0x83, 0x47, 0x72,
-	0x8c, 0x13, 0x1e, 0xcb, 0x31, 0x5c, 0x78, 0x2c, 0xc7, 0x70, 0xe3, 0xb1, 0x1c, 0x43, 0x94, 0x34,
-	0x1e, 0xaf, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0xd8, 0xe7, 0x8d, 0x70, 0x18, 0x01, 0x00, 0x00,
+	0x41, 0x34, 0x5b, 0x59, 0x72, 0xb1, 0xe7, 0xe7, 0xa5, 0xc6, 0x97, 0x27, 0x56, 0x0a, 0xc9, 0x61,
+	0x98, 0xe3, 0x9b, 0x5a, 0x92, 0x91, 0x9f, 0x02, 0x33, 0x69, 0xe2, 0x7e, 0x56, 0x05, 0x46, 0x0d,
+	0x8e, 0x20, 0xb6, 0xfc, 0xbc, 0xd4, 0xf0, 0xc4, 0x4a, 0x27, 0xd3, 0x13, 0x8f, 0xe4, 0x18, 0x2f,
+	0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63, 0x39, 0x86, 0x0b, 0x8f, 0xe5, 0x18,
+	0x6e, 0x3c, 0x96, 0x63, 0x88, 0x92, 0xc6, 0x13, 0x6a, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff,
+	0x7e, 0x14, 0x37, 0x53, 0x01, 0x00, 0x00,
 }
 
 func (m *Void) Marshal() (dAtA []byte, err error) {
@@ -0,0 +1,74 @@
+package certloader
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+type CertLoader struct {
+	certFileName string
+	keyFileName  string
+
+	mutex         sync.Mutex
+	cert          *tls.Certificate
+	certModTime   time.Time
+	keyModTime    time.Time
+	nextCheckTime time.Time
+}
+
+func (cl *CertLoader) Init(certFileName string, keyFileName string) *CertLoader {
+	cl.certFileName = certFileName
+	cl.keyFileName = keyFileName
+	return cl
+}
+
+func (cl *CertLoader) GetCert() (*tls.Certificate, error) {
+	now := time.Now()
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	if cl.cert != nil && now.Before(cl.nextCheckTime) {
+		return cl.cert, nil
+	}
+
+	cl.nextCheckTime = now.Add(checkInterval)
+	certFileInfo, err := os.Stat(cl.certFileName)
+
+	if err != nil {
+		return cl.fallBack(err)
+	}
+
+	keyFileInfo, err := os.Stat(cl.keyFileName)
+
+	if err != nil {
+		return cl.fallBack(err)
+	}
+
+	if cl.cert != nil && certFileInfo.ModTime().Equal(cl.certModTime) && keyFileInfo.ModTime().Equal(cl.keyModTime) {
+		return cl.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cl.certFileName, cl.keyFileName)
+
+	if err != nil {
+		return cl.fallBack(err)
+	}
+
+	cl.cert = &cert
+	cl.certModTime = certFileInfo.ModTime()
+	cl.keyModTime = keyFileInfo.ModTime()
+	return cl.cert, nil
+}
+
+func (cl *CertLoader) fallBack(err error) (*tls.Certificate, error) {
+	// keep serving the last good certificate while files are being replaced.
+	if cl.cert != nil {
+		return cl.cert, nil
+	}
+
+	return nil, err
+}
+
+const checkInterval = 1 * time.Second
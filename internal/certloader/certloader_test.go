@@ -0,0 +1,101 @@
+package certloader
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertLoader(t *testing.T) {
+	dirName, err := ioutil.TempDir("", "gogorpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dirName)
+	certFileName := filepath.Join(dirName, "test.crt")
+	keyFileName := filepath.Join(dirName, "test.key")
+	modTime := time.Now().Add(-time.Hour)
+	makeTestCert(t, certFileName, keyFileName, "foo", modTime)
+	cl := new(CertLoader).Init(certFileName, keyFileName)
+	assert.Equal(t, "foo", getCommonName(t, cl))
+
+	// replaced files are not picked up before the next check.
+	makeTestCert(t, certFileName, keyFileName, "bar", modTime.Add(time.Minute))
+	assert.Equal(t, "foo", getCommonName(t, cl))
+	cl.nextCheckTime = time.Time{}
+	assert.Equal(t, "bar", getCommonName(t, cl))
+
+	// the last good certificate is kept while the files are being replaced.
+	os.Remove(keyFileName)
+	cl.nextCheckTime = time.Time{}
+	assert.Equal(t, "bar", getCommonName(t, cl))
+	if !assert.NoError(t, ioutil.WriteFile(keyFileName, []byte("garbage"), 0600)) {
+		t.FailNow()
+	}
+	cl.nextCheckTime = time.Time{}
+	assert.Equal(t, "bar", getCommonName(t, cl))
+
+	makeTestCert(t, certFileName, keyFileName, "baz", modTime.Add(2*time.Minute))
+	cl.nextCheckTime = time.Time{}
+	assert.Equal(t, "baz", getCommonName(t, cl))
+
+	_, err = new(CertLoader).Init(filepath.Join(dirName, "none.crt"), keyFileName).GetCert()
+	assert.Error(t, err)
+}
+
+func getCommonName(t *testing.T, cl *CertLoader) string {
+	cert, err := cl.GetCert()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return leaf.Subject.CommonName
+}
+
+func makeTestCert(t *testing.T, certFileName string, keyFileName string, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	rawCert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(certFileName, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rawCert}), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(keyFileName, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey}), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, fileName := range [...]string{certFileName, keyFileName} {
+		if !assert.NoError(t, os.Chtimes(fileName, modTime, modTime)) {
+			t.FailNow()
+		}
+	}
+}
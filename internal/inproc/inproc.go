@@ -0,0 +1,105 @@
+package inproc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+type Listener struct {
+	name        string
+	connections chan net.Conn
+	closure     chan struct{}
+	closeOnce   sync.Once
+}
+
+var _ = net.Listener((*Listener)(nil))
+
+func Listen(name string) (*Listener, error) {
+	listenersMutex.Lock()
+	defer listenersMutex.Unlock()
+
+	if _, ok := listeners[name]; ok {
+		return nil, ErrAddressInUse
+	}
+
+	listener := &Listener{
+		name:        name,
+		connections: make(chan net.Conn),
+		closure:     make(chan struct{}),
+	}
+
+	listeners[name] = listener
+	return listener, nil
+}
+
+func Dial(ctx context.Context, name string) (net.Conn, error) {
+	listenersMutex.RLock()
+	listener, ok := listeners[name]
+	listenersMutex.RUnlock()
+
+	if !ok {
+		return nil, ErrConnectionRefused
+	}
+
+	clientConnection, serverConnection := net.Pipe()
+
+	select {
+	case listener.connections <- serverConnection:
+		return clientConnection, nil
+	case <-listener.closure:
+		clientConnection.Close()
+		serverConnection.Close()
+		return nil, ErrConnectionRefused
+	case <-ctx.Done():
+		clientConnection.Close()
+		serverConnection.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case connection := <-l.connections:
+		return connection, nil
+	case <-l.closure:
+		return nil, ErrListenerClosed
+	}
+}
+
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		listenersMutex.Lock()
+		delete(listeners, l.name)
+		listenersMutex.Unlock()
+		close(l.closure)
+	})
+
+	return nil
+}
+
+func (l *Listener) Addr() net.Addr {
+	return address(l.name)
+}
+
+var (
+	ErrAddressInUse      = errors.New("gogorpc/inproc: address in use")
+	ErrConnectionRefused = errors.New("gogorpc/inproc: connection refused")
+	ErrListenerClosed    = errors.New("gogorpc/inproc: listener closed")
+)
+
+type address string
+
+func (address) Network() string {
+	return "inproc"
+}
+
+func (a address) String() string {
+	return string(a)
+}
+
+var (
+	listenersMutex sync.RWMutex
+	listeners      = map[string]*Listener{}
+)
@@ -0,0 +1,271 @@
+package mux
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type Conn struct {
+	session            *Session
+	streamID           uint32
+	writeMutex         sync.Mutex
+	mutex              sync.Mutex
+	change             chan struct{}
+	data               []byte
+	unacknowledgedSize int
+	availableWindow    int
+	isClosed           bool
+	isRemoteClosed     bool
+	readDeadline       deadline
+	writeDeadline      deadline
+}
+
+var _ = net.Conn((*Conn)(nil))
+
+func (c *Conn) Init(session *Session, streamID uint32) *Conn {
+	c.session = session
+	c.streamID = streamID
+	c.change = make(chan struct{})
+	c.availableWindow = windowSize
+	c.readDeadline.Init()
+	c.writeDeadline.Init()
+	return c
+}
+
+func (c *Conn) Read(buffer []byte) (int, error) {
+	for {
+		c.mutex.Lock()
+
+		if c.isClosed {
+			c.mutex.Unlock()
+			return 0, io.ErrClosedPipe
+		}
+
+		if len(c.data) >= 1 {
+			n := copy(buffer, c.data)
+			c.data = c.data[n:]
+			c.unacknowledgedSize += n
+			windowIncrement := 0
+
+			// acknowledge in batches so as not to flood the peer with window updates.
+			if c.unacknowledgedSize >= windowSize/2 {
+				windowIncrement = c.unacknowledgedSize
+				c.unacknowledgedSize = 0
+			}
+
+			c.mutex.Unlock()
+
+			if windowIncrement >= 1 {
+				c.session.writeFrame(c.streamID, frameWindowUpdate, uint32(windowIncrement), nil, false)
+			}
+
+			return n, nil
+		}
+
+		if c.isRemoteClosed {
+			c.mutex.Unlock()
+			return 0, io.EOF
+		}
+
+		change := c.change
+		c.mutex.Unlock()
+
+		if err := c.wait(change, &c.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (c *Conn) Write(buffer []byte) (int, error) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	n := 0
+
+	for n < len(buffer) {
+		c.mutex.Lock()
+
+		if c.isClosed || c.isRemoteClosed {
+			c.mutex.Unlock()
+			return n, io.ErrClosedPipe
+		}
+
+		if c.availableWindow == 0 {
+			change := c.change
+			c.mutex.Unlock()
+
+			if err := c.wait(change, &c.writeDeadline); err != nil {
+				return n, err
+			}
+
+			continue
+		}
+
+		dataSize := len(buffer) - n
+
+		if dataSize > c.availableWindow {
+			dataSize = c.availableWindow
+		}
+
+		if dataSize > maxFrameDataSize {
+			dataSize = maxFrameDataSize
+		}
+
+		c.availableWindow -= dataSize
+		c.mutex.Unlock()
+		frame := c.session.writeFrame(c.streamID, frameData, uint32(dataSize), buffer[n:n+dataSize], true)
+
+		if err := c.waitForFrame(frame); err != nil {
+			if c.session.withdrawFrame(frame) {
+				c.mutex.Lock()
+				c.availableWindow += dataSize
+				c.mutex.Unlock()
+			} else {
+				// too late, the frame is on its way.
+				n += dataSize
+			}
+
+			return n, err
+		}
+
+		n += dataSize
+	}
+
+	return n, nil
+}
+
+func (c *Conn) Close() error {
+	c.mutex.Lock()
+
+	if c.isClosed {
+		c.mutex.Unlock()
+		return nil
+	}
+
+	c.isClosed = true
+	c.data = nil
+	c.notifyChangeLocked()
+	c.mutex.Unlock()
+
+	if !c.session.isClosed() {
+		c.session.removeConn(c.streamID)
+	}
+
+	return nil
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.session.connection.LocalAddr()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.session.connection.RemoteAddr()
+}
+
+// ConnectionState returns the tls state of the underlying connection, if any.
+func (c *Conn) ConnectionState() tls.ConnectionState {
+	if tlsConnection, ok := c.session.connection.(interface{ ConnectionState() tls.ConnectionState }); ok {
+		return tlsConnection.ConnectionState()
+	}
+
+	return tls.ConnectionState{}
+}
+
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.readDeadline.Set(t)
+	c.writeDeadline.Set(t)
+	return nil
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.Set(t)
+	return nil
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.Set(t)
+	return nil
+}
+
+func (c *Conn) handleData(reader *bufio.Reader, dataSize int) error {
+	data := make([]byte, dataSize)
+
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.isClosed {
+		return nil
+	}
+
+	// the peer must never send more than what has been acknowledged.
+	if len(c.data)+c.unacknowledgedSize+dataSize > windowSize {
+		return ErrBadFrame
+	}
+
+	c.data = append(c.data, data...)
+	c.notifyChangeLocked()
+	return nil
+}
+
+func (c *Conn) handleWindowUpdate(windowIncrement int) {
+	c.mutex.Lock()
+	c.availableWindow += windowIncrement
+	c.notifyChangeLocked()
+	c.mutex.Unlock()
+}
+
+func (c *Conn) handleClose() {
+	c.mutex.Lock()
+	c.isRemoteClosed = true
+	c.notifyChangeLocked()
+	c.mutex.Unlock()
+}
+
+func (c *Conn) wait(change <-chan struct{}, deadline *deadline) error {
+	select {
+	case <-change:
+		return nil
+	case <-deadline.Expiry():
+		return os.ErrDeadlineExceeded
+	case <-c.session.closure:
+		c.mutex.Lock()
+		hasData := len(c.data) >= 1
+		c.mutex.Unlock()
+
+		if hasData {
+			return nil
+		}
+
+		return c.session.error()
+	}
+}
+
+func (c *Conn) waitForFrame(frame *outgoingFrame) error {
+	select {
+	case <-frame.isSent:
+		return nil
+	case <-c.writeDeadline.Expiry():
+		return os.ErrDeadlineExceeded
+	case <-c.session.closure:
+		return c.session.error()
+	}
+}
+
+func (c *Conn) notifyChange() {
+	c.mutex.Lock()
+	c.notifyChangeLocked()
+	c.mutex.Unlock()
+}
+
+func (c *Conn) notifyChangeLocked() {
+	close(c.change)
+	c.change = make(chan struct{})
+}
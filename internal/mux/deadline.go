@@ -0,0 +1,68 @@
+package mux
+
+import (
+	"sync"
+	"time"
+)
+
+type deadline struct {
+	mutex  sync.Mutex
+	timer  *time.Timer
+	expiry chan struct{}
+}
+
+func (d *deadline) Init() *deadline {
+	d.expiry = make(chan struct{})
+	return d
+}
+
+func (d *deadline) Set(t time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.timer != nil && !d.timer.Stop() {
+		// the timer has fired, wait for the expiry to be closed.
+		<-d.expiry
+	}
+
+	d.timer = nil
+	isExpired := isClosed(d.expiry)
+
+	if t.IsZero() {
+		if isExpired {
+			d.expiry = make(chan struct{})
+		}
+
+		return
+	}
+
+	if duration := time.Until(t); duration >= 1 {
+		if isExpired {
+			d.expiry = make(chan struct{})
+		}
+
+		expiry := d.expiry
+		d.timer = time.AfterFunc(duration, func() { close(expiry) })
+		return
+	}
+
+	if !isExpired {
+		close(d.expiry)
+	}
+}
+
+func (d *deadline) Expiry() <-chan struct{} {
+	d.mutex.Lock()
+	expiry := d.expiry
+	d.mutex.Unlock()
+	return expiry
+}
+
+func isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
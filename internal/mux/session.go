@@ -0,0 +1,351 @@
+package mux
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+type Session struct {
+	connection     net.Conn
+	writeMutex     sync.Mutex
+	outgoingFrames []*outgoingFrame
+	newFrames      chan struct{}
+	mutex          sync.Mutex
+	conns          map[uint32]*Conn
+	nextStreamID   uint32
+	pendingConns   chan *Conn
+	isDraining     bool
+	isGoneAway     bool
+	isIdleClosing  bool
+	closure        chan struct{}
+	closeOnce      sync.Once
+	err            error
+}
+
+func (s *Session) Init(connection net.Conn, isServerSide bool) *Session {
+	s.connection = connection
+	s.conns = map[uint32]*Conn{}
+
+	if isServerSide {
+		s.nextStreamID = 2
+	} else {
+		s.nextStreamID = 1
+	}
+
+	s.newFrames = make(chan struct{}, 1)
+	s.pendingConns = make(chan *Conn, maxNumberOfPendingConns)
+	s.closure = make(chan struct{})
+	go s.receiveFrames()
+	go s.sendFrames()
+	return s
+}
+
+func (s *Session) Open() (*Conn, error) {
+	s.mutex.Lock()
+
+	if s.isClosed() || s.isGoneAway {
+		s.mutex.Unlock()
+		return nil, ErrSessionClosed
+	}
+
+	streamID := s.nextStreamID
+	s.nextStreamID += 2
+	conn := new(Conn).Init(s, streamID)
+	s.conns[streamID] = conn
+	s.mutex.Unlock()
+	s.writeFrame(streamID, frameOpen, 0, nil, false)
+	return conn, nil
+}
+
+func (s *Session) Accept() (*Conn, error) {
+	select {
+	case conn := <-s.pendingConns:
+		return conn, nil
+	case <-s.closure:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Drain refuses new conns from the peer, tells it to open them elsewhere,
+// and closes the session once the last conn is closed.
+func (s *Session) Drain() {
+	s.mutex.Lock()
+	s.isDraining = true
+	s.mutex.Unlock()
+	s.writeFrame(0, frameGoAway, 0, nil, false)
+	s.CloseWhenIdle()
+}
+
+// CloseWhenIdle closes the session once the last conn is closed.
+func (s *Session) CloseWhenIdle() {
+	s.mutex.Lock()
+	s.isIdleClosing = true
+	isIdle := len(s.conns) == 0
+	s.mutex.Unlock()
+
+	if isIdle {
+		s.close(ErrSessionClosed)
+	}
+}
+
+func (s *Session) Close() error {
+	s.close(ErrSessionClosed)
+	return nil
+}
+
+func (s *Session) receiveFrames() {
+	reader := bufio.NewReader(s.connection)
+	var header [frameHeaderSize]byte
+
+	for {
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			s.close(err)
+			return
+		}
+
+		streamID := binary.BigEndian.Uint32(header[0:])
+		frameType := header[4]
+		value := binary.BigEndian.Uint32(header[5:])
+
+		var err error
+
+		switch frameType {
+		case frameOpen:
+			err = s.handleOpen(streamID)
+		case frameData:
+			err = s.handleData(reader, streamID, int(value))
+		case frameWindowUpdate:
+			if conn := s.getConn(streamID); conn != nil {
+				conn.handleWindowUpdate(int(value))
+			}
+		case frameClose:
+			if conn := s.getConn(streamID); conn != nil {
+				conn.handleClose()
+			}
+		case frameGoAway:
+			s.mutex.Lock()
+			s.isGoneAway = true
+			s.mutex.Unlock()
+		default:
+			err = ErrBadFrame
+		}
+
+		if err != nil {
+			s.close(err)
+			return
+		}
+	}
+}
+
+func (s *Session) handleOpen(streamID uint32) error {
+	s.mutex.Lock()
+
+	if _, ok := s.conns[streamID]; ok || streamID%2 == s.nextStreamID%2 {
+		s.mutex.Unlock()
+		return ErrBadFrame
+	}
+
+	if s.isDraining {
+		s.mutex.Unlock()
+		s.writeFrame(streamID, frameClose, 0, nil, false)
+		return nil
+	}
+
+	conn := new(Conn).Init(s, streamID)
+
+	select {
+	case s.pendingConns <- conn:
+		s.conns[streamID] = conn
+		s.mutex.Unlock()
+		return nil
+	default:
+		s.mutex.Unlock()
+		s.writeFrame(streamID, frameClose, 0, nil, false)
+		return nil
+	}
+}
+
+func (s *Session) handleData(reader *bufio.Reader, streamID uint32, dataSize int) error {
+	if dataSize > maxFrameDataSize {
+		return ErrBadFrame
+	}
+
+	conn := s.getConn(streamID)
+
+	if conn == nil {
+		// the conn has been closed locally, drop what is still in flight.
+		_, err := reader.Discard(dataSize)
+		return err
+	}
+
+	return conn.handleData(reader, dataSize)
+}
+
+func (s *Session) getConn(streamID uint32) *Conn {
+	s.mutex.Lock()
+	conn := s.conns[streamID]
+	s.mutex.Unlock()
+	return conn
+}
+
+func (s *Session) removeConn(streamID uint32) {
+	s.mutex.Lock()
+	delete(s.conns, streamID)
+	isIdle := s.isIdleClosing && len(s.conns) == 0
+	s.mutex.Unlock()
+
+	s.writeFrame(streamID, frameClose, 0, nil, false)
+
+	if isIdle {
+		s.close(ErrSessionClosed)
+	}
+}
+
+// writeFrame queues a frame for sendFrames and never blocks, so that neither the
+// receiving loop nor closing conns can hang on a stalled connection.
+func (s *Session) writeFrame(streamID uint32, frameType uint8, value uint32, data []byte, isAwaited bool) *outgoingFrame {
+	frame := outgoingFrame{Data: make([]byte, frameHeaderSize+len(data))}
+	binary.BigEndian.PutUint32(frame.Data[0:], streamID)
+	frame.Data[4] = frameType
+	binary.BigEndian.PutUint32(frame.Data[5:], value)
+	copy(frame.Data[frameHeaderSize:], data)
+
+	if isAwaited {
+		frame.isSent = make(chan struct{})
+	}
+
+	s.writeMutex.Lock()
+	s.outgoingFrames = append(s.outgoingFrames, &frame)
+	s.writeMutex.Unlock()
+
+	select {
+	case s.newFrames <- struct{}{}:
+	default:
+	}
+
+	return &frame
+}
+
+// withdrawFrame takes back a queued frame, it fails once the frame is being sent.
+func (s *Session) withdrawFrame(frame *outgoingFrame) bool {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if frame.isTaken {
+		return false
+	}
+
+	frame.isWithdrawn = true
+	return true
+}
+
+func (s *Session) sendFrames() {
+	defer s.connection.Close()
+
+	for {
+		isClosed := false
+
+		select {
+		case <-s.newFrames:
+		case <-s.closure:
+			// flush what is left (e.g. close frames), but not forever.
+			isClosed = true
+			s.connection.SetWriteDeadline(time.Now().Add(finalFlushTimeout))
+		}
+
+		s.writeMutex.Lock()
+		frames := s.outgoingFrames
+		s.outgoingFrames = nil
+		buffers := make(net.Buffers, 0, len(frames))
+
+		for _, frame := range frames {
+			if !frame.isWithdrawn {
+				frame.isTaken = true
+				buffers = append(buffers, frame.Data)
+			}
+		}
+
+		s.writeMutex.Unlock()
+
+		if _, err := buffers.WriteTo(s.connection); err != nil {
+			s.close(err)
+			return
+		}
+
+		for _, frame := range frames {
+			if frame.isTaken && frame.isSent != nil {
+				close(frame.isSent)
+			}
+		}
+
+		if isClosed {
+			return
+		}
+	}
+}
+
+func (s *Session) close(err error) {
+	s.closeOnce.Do(func() {
+		s.mutex.Lock()
+		s.err = err
+		conns := s.conns
+		s.conns = map[uint32]*Conn{}
+		s.mutex.Unlock()
+		// the connection is closed by sendFrames.
+		close(s.closure)
+
+		for _, conn := range conns {
+			conn.notifyChange()
+		}
+	})
+}
+
+func (s *Session) isClosed() bool {
+	select {
+	case <-s.closure:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Session) error() error {
+	s.mutex.Lock()
+	err := s.err
+	s.mutex.Unlock()
+	return err
+}
+
+type outgoingFrame struct {
+	Data []byte
+
+	isSent      chan struct{}
+	isTaken     bool
+	isWithdrawn bool
+}
+
+var (
+	ErrSessionClosed = errors.New("gogorpc/mux: session closed")
+	ErrBadFrame      = errors.New("gogorpc/mux: bad frame")
+)
+
+const (
+	frameOpen uint8 = iota
+	frameData
+	frameWindowUpdate
+	frameClose
+	frameGoAway
+)
+
+const (
+	frameHeaderSize         = 9
+	maxFrameDataSize        = 1 << 14
+	windowSize              = 1 << 18
+	maxNumberOfPendingConns = 1 << 6
+	finalFlushTimeout       = 1 * time.Second
+)
@@ -0,0 +1,175 @@
+package mux
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	cs, ss := makeSessions()
+	defer cs.Close()
+	defer ss.Close()
+	go func() {
+		for {
+			conn, err := ss.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := cs.Open()
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			data := make([]byte, 4*windowSize+rand.Intn(maxFrameDataSize))
+			rand.Read(data)
+			go conn.Write(data)
+			data2 := make([]byte, len(data))
+			if _, err := io.ReadFull(conn, data2); assert.NoError(t, err) {
+				assert.True(t, bytes.Equal(data, data2))
+			}
+		}()
+	}
+	wg.Wait()
+	conn, err := cs.Open()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Equal(t, os.ErrDeadlineExceeded, err)
+	conn.SetReadDeadline(time.Time{})
+	_, err = conn.Write([]byte("x"))
+	assert.NoError(t, err)
+	_, err = io.ReadFull(conn, make([]byte, 1))
+	assert.NoError(t, err)
+}
+
+func TestSessionDrain(t *testing.T) {
+	cs, ss := makeSessions()
+	defer cs.Close()
+	conn, err := cs.Open()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn2, err := ss.Accept()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ss.Drain()
+	for {
+		if _, err := cs.Open(); err != nil {
+			assert.Equal(t, ErrSessionClosed, err)
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err = conn.Write([]byte("x"))
+	assert.NoError(t, err)
+	_, err = io.ReadFull(conn2, make([]byte, 1))
+	assert.NoError(t, err)
+	conn.Close()
+	conn2.Close()
+	_, err = ss.Accept()
+	assert.Equal(t, ErrSessionClosed, err)
+}
+
+func TestSessionStall(t *testing.T) {
+	connection1, connection2 := net.Pipe()
+	defer connection2.Close()
+	// nobody reads connection2.
+	s := new(Session).Init(connection1, false)
+	conn, err := s.Open()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn.Write([]byte("x"))
+	assert.Equal(t, os.ErrDeadlineExceeded, err)
+	closed := make(chan struct{})
+	go func() {
+		conn.Close()
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("close hung")
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	var sp SessionPool
+	var mutex sync.Mutex
+	n := 0
+	dialer := func(ctx context.Context) (net.Conn, error) {
+		connection1, connection2 := net.Pipe()
+		ss := new(Session).Init(connection2, true)
+		go func() {
+			for {
+				if _, err := ss.Accept(); err != nil {
+					return
+				}
+			}
+		}()
+		mutex.Lock()
+		n++
+		mutex.Unlock()
+		return connection1, nil
+	}
+	conn1, err := sp.Open(context.Background(), "a", dialer)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn2, err := sp.Open(context.Background(), "a", dialer)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn3, err := sp.Open(context.Background(), "b", dialer)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn3.Close()
+	mutex.Lock()
+	assert.Equal(t, 2, n)
+	mutex.Unlock()
+	// the entry goes away along with its session.
+	conn1.Close()
+	conn2.Close()
+	for {
+		sp.mutex.Lock()
+		_, ok := sp.entries["a"]
+		m := len(sp.entries)
+		sp.mutex.Unlock()
+		if !ok {
+			assert.Equal(t, 1, m)
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func makeSessions() (*Session, *Session) {
+	connection1, connection2 := net.Pipe()
+	return new(Session).Init(connection1, false), new(Session).Init(connection2, true)
+}
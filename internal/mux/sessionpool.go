@@ -0,0 +1,89 @@
+package mux
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+type SessionPool struct {
+	mutex   sync.Mutex
+	entries map[interface{}]*sessionPoolEntry
+}
+
+func (sp *SessionPool) Open(ctx context.Context, key interface{}, dialer func(ctx context.Context) (net.Conn, error)) (*Conn, error) {
+	sp.mutex.Lock()
+
+	if sp.entries == nil {
+		sp.entries = map[interface{}]*sessionPoolEntry{}
+	}
+
+	entry, ok := sp.entries[key]
+
+	if !ok {
+		entry = new(sessionPoolEntry)
+		sp.entries[key] = entry
+	}
+
+	sp.mutex.Unlock()
+	// hold the entry so that concurrent opens share a single dial.
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	sp.mutex.Lock()
+	session := entry.session
+	sp.mutex.Unlock()
+
+	if session != nil {
+		if conn, err := session.Open(); err == nil {
+			return conn, nil
+		}
+	}
+
+	connection, err := dialer(ctx)
+
+	if err != nil {
+		sp.removeEntry(key, entry, nil)
+		return nil, err
+	}
+
+	session = new(Session).Init(connection, false)
+	conn, err := session.Open()
+
+	if err != nil {
+		session.Close()
+		sp.removeEntry(key, entry, nil)
+		return nil, err
+	}
+
+	// the connection is given up as soon as no conn is using it.
+	session.CloseWhenIdle()
+	sp.mutex.Lock()
+	entry.session = session
+	sp.mutex.Unlock()
+
+	go func() {
+		<-session.closure
+		sp.removeEntry(key, entry, session)
+	}()
+
+	return conn, nil
+}
+
+func (sp *SessionPool) removeEntry(key interface{}, entry *sessionPoolEntry, session *Session) {
+	sp.mutex.Lock()
+
+	if entry.session == session {
+		entry.session = nil
+
+		if sp.entries[key] == entry {
+			delete(sp.entries, key)
+		}
+	}
+
+	sp.mutex.Unlock()
+}
+
+type sessionPoolEntry struct {
+	mutex   sync.Mutex
+	session *Session // guarded by SessionPool.mutex
+}
@@ -83,6 +83,8 @@ const (
 	HANGUP_TOO_MANY_INCOMING_REQUESTS HangupCode = 2
 	HANGUP_OUTGOING_PACKET_TOO_LARGE  HangupCode = 3
 	HANGUP_SYSTEM                     HangupCode = 4
+	HANGUP_DRAINED                    HangupCode = 5
+	HANGUP_BAD_INCOMING_CHECKSUM      HangupCode = 6
 )
 
 var HangupCode_name = map[int32]string{
@@ -91,6 +93,8 @@ var HangupCode_name = map[int32]string{
 	2: "HANGUP_TOO_MANY_INCOMING_REQUESTS",
 	3: "HANGUP_OUTGOING_PACKET_TOO_LARGE",
 	4: "HANGUP_SYSTEM",
+	5: "HANGUP_DRAINED",
+	6: "HANGUP_BAD_INCOMING_CHECKSUM",
 }
 
 var HangupCode_value = map[string]int32{
@@ -99,6 +103,8 @@ var HangupCode_value = map[string]int32{
 	"HANGUP_TOO_MANY_INCOMING_REQUESTS": 2,
 	"HANGUP_OUTGOING_PACKET_TOO_LARGE":  3,
 	"HANGUP_SYSTEM":                     4,
+	"HANGUP_DRAINED":                    5,
+	"HANGUP_BAD_INCOMING_CHECKSUM":      6,
 }
 
 func (x HangupCode) String() string {
@@ -114,6 +120,10 @@ type StreamHandshakeHeader struct {
 	OutgoingKeepaliveInterval int32 `protobuf:"varint,2,opt,name=outgoing_keepalive_interval,json=outgoingKeepaliveInterval,proto3" json:"outgoing_keepalive_interval,omitempty"`
 	IncomingConcurrencyLimit  int32 `protobuf:"varint,3,opt,name=incoming_concurrency_limit,json=incomingConcurrencyLimit,proto3" json:"incoming_concurrency_limit,omitempty"`
 	OutgoingConcurrencyLimit  int32 `protobuf:"varint,4,opt,name=outgoing_concurrency_limit,json=outgoingConcurrencyLimit,proto3" json:"outgoing_concurrency_limit,omitempty"`
+	IncomingWindowSize        int32 `protobuf:"varint,5,opt,name=incoming_window_size,json=incomingWindowSize,proto3" json:"incoming_window_size,omitempty"`
+	OutgoingWindowSize        int32 `protobuf:"varint,6,opt,name=outgoing_window_size,json=outgoingWindowSize,proto3" json:"outgoing_window_size,omitempty"`
+	IsKeepaliveHeaderEnabled  bool  `protobuf:"varint,7,opt,name=is_keepalive_header_enabled,json=isKeepaliveHeaderEnabled,proto3" json:"is_keepalive_header_enabled,omitempty"`
+	IsMethodIdEnabled         bool  `protobuf:"varint,8,opt,name=is_method_id_enabled,json=isMethodIdEnabled,proto3" json:"is_method_id_enabled,omitempty"`
 }
 
 func (m *StreamHandshakeHeader) Reset()         { *m = StreamHandshakeHeader{} }
@@ -177,6 +187,102 @@ func (m *StreamHandshakeHeader) GetOutgoingConcurrencyLimit() int32 {
 	return 0
 }
 
+func (m *StreamHandshakeHeader) GetIncomingWindowSize() int32 {
+	if m != nil {
+		return m.IncomingWindowSize
+	}
+	return 0
+}
+
+func (m *StreamHandshakeHeader) GetOutgoingWindowSize() int32 {
+	if m != nil {
+		return m.OutgoingWindowSize
+	}
+	return 0
+}
+
+func (m *StreamHandshakeHeader) GetIsKeepaliveHeaderEnabled() bool {
+	if m != nil {
+		return m.IsKeepaliveHeaderEnabled
+	}
+	return false
+}
+
+func (m *StreamHandshakeHeader) GetIsMethodIdEnabled() bool {
+	if m != nil {
+		return m.IsMethodIdEnabled
+	}
+	return false
+}
+
+type KeepaliveHeader struct {
+	Timestamp       int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	EchoedTimestamp int64 `protobuf:"varint,2,opt,name=echoed_timestamp,json=echoedTimestamp,proto3" json:"echoed_timestamp,omitempty"`
+	EchoDelay       int64 `protobuf:"varint,3,opt,name=echo_delay,json=echoDelay,proto3" json:"echo_delay,omitempty"`
+	IsEchoRequested bool  `protobuf:"varint,4,opt,name=is_echo_requested,json=isEchoRequested,proto3" json:"is_echo_requested,omitempty"`
+}
+
+func (m *KeepaliveHeader) Reset()         { *m = KeepaliveHeader{} }
+func (m *KeepaliveHeader) String() string { return proto.CompactTextString(m) }
+func (*KeepaliveHeader) ProtoMessage()    {}
+func (*KeepaliveHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{1}
+}
+func (m *KeepaliveHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *KeepaliveHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_KeepaliveHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *KeepaliveHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeepaliveHeader.Merge(m, src)
+}
+func (m *KeepaliveHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *KeepaliveHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeepaliveHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeepaliveHeader proto.InternalMessageInfo
+
+func (m *KeepaliveHeader) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *KeepaliveHeader) GetEchoedTimestamp() int64 {
+	if m != nil {
+		return m.EchoedTimestamp
+	}
+	return 0
+}
+
+func (m *KeepaliveHeader) GetEchoDelay() int64 {
+	if m != nil {
+		return m.EchoDelay
+	}
+	return 0
+}
+
+func (m *KeepaliveHeader) GetIsEchoRequested() bool {
+	if m != nil {
+		return m.IsEchoRequested
+	}
+	return false
+}
+
 type RequestHeader struct {
 	SequenceNumber int32             `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
 	ServiceName    string            `protobuf:"bytes,2,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
@@ -184,13 +290,16 @@ type RequestHeader struct {
 	ExtraData      map[string][]byte `protobuf:"bytes,4,rep,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	Deadline       int64             `protobuf:"varint,5,opt,name=deadline,proto3" json:"deadline,omitempty"`
 	TraceId        UUID              `protobuf:"bytes,6,opt,name=trace_id,json=traceId,proto3" json:"trace_id"`
+	Timeout        int64             `protobuf:"varint,7,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Priority       int32             `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	MethodId       int32             `protobuf:"varint,9,opt,name=method_id,json=methodId,proto3" json:"method_id,omitempty"`
 }
 
 func (m *RequestHeader) Reset()         { *m = RequestHeader{} }
 func (m *RequestHeader) String() string { return proto.CompactTextString(m) }
 func (*RequestHeader) ProtoMessage()    {}
 func (*RequestHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4187f59d13635016, []int{1}
+	return fileDescriptor_4187f59d13635016, []int{2}
 }
 func (m *RequestHeader) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -261,17 +370,39 @@ func (m *RequestHeader) GetTraceId() UUID {
 	return UUID{}
 }
 
+func (m *RequestHeader) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *RequestHeader) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *RequestHeader) GetMethodId() int32 {
+	if m != nil {
+		return m.MethodId
+	}
+	return 0
+}
+
 type ResponseHeader struct {
 	SequenceNumber int32             `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
 	ExtraData      map[string][]byte `protobuf:"bytes,2,rep,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	RpcError       RPCError          `protobuf:"bytes,3,opt,name=rpc_error,json=rpcError,proto3" json:"rpc_error"`
+	IsRefused      bool              `protobuf:"varint,4,opt,name=is_refused,json=isRefused,proto3" json:"is_refused,omitempty"`
 }
 
 func (m *ResponseHeader) Reset()         { *m = ResponseHeader{} }
 func (m *ResponseHeader) String() string { return proto.CompactTextString(m) }
 func (*ResponseHeader) ProtoMessage()    {}
 func (*ResponseHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4187f59d13635016, []int{2}
+	return fileDescriptor_4187f59d13635016, []int{3}
 }
 func (m *ResponseHeader) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -321,6 +452,237 @@ func (m *ResponseHeader) GetRpcError() RPCError {
 	return RPCError{}
 }
 
+func (m *ResponseHeader) GetIsRefused() bool {
+	if m != nil {
+		return m.IsRefused
+	}
+	return false
+}
+
+type StreamMessageHeader struct {
+	SequenceNumber int32 `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
+	IsResponse     bool  `protobuf:"varint,2,opt,name=is_response,json=isResponse,proto3" json:"is_response,omitempty"`
+}
+
+func (m *StreamMessageHeader) Reset()         { *m = StreamMessageHeader{} }
+func (m *StreamMessageHeader) String() string { return proto.CompactTextString(m) }
+func (*StreamMessageHeader) ProtoMessage()    {}
+func (*StreamMessageHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{4}
+}
+func (m *StreamMessageHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StreamMessageHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StreamMessageHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StreamMessageHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamMessageHeader.Merge(m, src)
+}
+func (m *StreamMessageHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *StreamMessageHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamMessageHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamMessageHeader proto.InternalMessageInfo
+
+func (m *StreamMessageHeader) GetSequenceNumber() int32 {
+	if m != nil {
+		return m.SequenceNumber
+	}
+	return 0
+}
+
+func (m *StreamMessageHeader) GetIsResponse() bool {
+	if m != nil {
+		return m.IsResponse
+	}
+	return false
+}
+
+type StreamEndHeader struct {
+	SequenceNumber int32 `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
+}
+
+func (m *StreamEndHeader) Reset()         { *m = StreamEndHeader{} }
+func (m *StreamEndHeader) String() string { return proto.CompactTextString(m) }
+func (*StreamEndHeader) ProtoMessage()    {}
+func (*StreamEndHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{5}
+}
+func (m *StreamEndHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StreamEndHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StreamEndHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StreamEndHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamEndHeader.Merge(m, src)
+}
+func (m *StreamEndHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *StreamEndHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamEndHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamEndHeader proto.InternalMessageInfo
+
+func (m *StreamEndHeader) GetSequenceNumber() int32 {
+	if m != nil {
+		return m.SequenceNumber
+	}
+	return 0
+}
+
+type CancelHeader struct {
+	SequenceNumber int32 `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
+}
+
+func (m *CancelHeader) Reset()         { *m = CancelHeader{} }
+func (m *CancelHeader) String() string { return proto.CompactTextString(m) }
+func (*CancelHeader) ProtoMessage()    {}
+func (*CancelHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{6}
+}
+func (m *CancelHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CancelHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CancelHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CancelHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelHeader.Merge(m, src)
+}
+func (m *CancelHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *CancelHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelHeader proto.InternalMessageInfo
+
+func (m *CancelHeader) GetSequenceNumber() int32 {
+	if m != nil {
+		return m.SequenceNumber
+	}
+	return 0
+}
+
+type NotificationHeader struct {
+	SequenceNumber int32             `protobuf:"varint,1,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
+	ServiceName    string            `protobuf:"bytes,2,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	MethodName     string            `protobuf:"bytes,3,opt,name=method_name,json=methodName,proto3" json:"method_name,omitempty"`
+	ExtraData      map[string][]byte `protobuf:"bytes,4,rep,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TraceId        UUID              `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id"`
+	MethodId       int32             `protobuf:"varint,6,opt,name=method_id,json=methodId,proto3" json:"method_id,omitempty"`
+}
+
+func (m *NotificationHeader) Reset()         { *m = NotificationHeader{} }
+func (m *NotificationHeader) String() string { return proto.CompactTextString(m) }
+func (*NotificationHeader) ProtoMessage()    {}
+func (*NotificationHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{7}
+}
+func (m *NotificationHeader) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NotificationHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NotificationHeader.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NotificationHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NotificationHeader.Merge(m, src)
+}
+func (m *NotificationHeader) XXX_Size() int {
+	return m.Size()
+}
+func (m *NotificationHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_NotificationHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NotificationHeader proto.InternalMessageInfo
+
+func (m *NotificationHeader) GetSequenceNumber() int32 {
+	if m != nil {
+		return m.SequenceNumber
+	}
+	return 0
+}
+
+func (m *NotificationHeader) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+func (m *NotificationHeader) GetMethodName() string {
+	if m != nil {
+		return m.MethodName
+	}
+	return ""
+}
+
+func (m *NotificationHeader) GetExtraData() map[string][]byte {
+	if m != nil {
+		return m.ExtraData
+	}
+	return nil
+}
+
+func (m *NotificationHeader) GetTraceId() UUID {
+	if m != nil {
+		return m.TraceId
+	}
+	return UUID{}
+}
+
+func (m *NotificationHeader) GetMethodId() int32 {
+	if m != nil {
+		return m.MethodId
+	}
+	return 0
+}
+
 type RPCError struct {
 	Type RPCErrorType `protobuf:"varint,1,opt,name=type,proto3,enum=gogorpc.proto.RPCErrorType" json:"type,omitempty"`
 	Code string       `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
@@ -331,7 +693,7 @@ func (m *RPCError) Reset()         { *m = RPCError{} }
 func (m *RPCError) String() string { return proto.CompactTextString(m) }
 func (*RPCError) ProtoMessage()    {}
 func (*RPCError) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4187f59d13635016, []int{3}
+	return fileDescriptor_4187f59d13635016, []int{8}
 }
 func (m *RPCError) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -382,15 +744,18 @@ func (m *RPCError) GetDesc() string {
 }
 
 type Hangup struct {
-	Code      HangupCode        `protobuf:"varint,1,opt,name=code,proto3,enum=gogorpc.proto.HangupCode" json:"code,omitempty"`
-	ExtraData map[string][]byte `protobuf:"bytes,2,rep,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Code              HangupCode        `protobuf:"varint,1,opt,name=code,proto3,enum=gogorpc.proto.HangupCode" json:"code,omitempty"`
+	ExtraData         map[string][]byte `protobuf:"bytes,2,rep,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Message           string            `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	RetryAfter        int32             `protobuf:"varint,4,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	RedirectServerUrl string            `protobuf:"bytes,5,opt,name=redirect_server_url,json=redirectServerUrl,proto3" json:"redirect_server_url,omitempty"`
 }
 
 func (m *Hangup) Reset()         { *m = Hangup{} }
 func (m *Hangup) String() string { return proto.CompactTextString(m) }
 func (*Hangup) ProtoMessage()    {}
 func (*Hangup) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4187f59d13635016, []int{4}
+	return fileDescriptor_4187f59d13635016, []int{9}
 }
 func (m *Hangup) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -433,88 +798,295 @@ func (m *Hangup) GetExtraData() map[string][]byte {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("gogorpc.proto.RPCErrorType", RPCErrorType_name, RPCErrorType_value)
-	proto.RegisterEnum("gogorpc.proto.HangupCode", HangupCode_name, HangupCode_value)
-	proto.RegisterType((*StreamHandshakeHeader)(nil), "gogorpc.proto.StreamHandshakeHeader")
-	proto.RegisterType((*RequestHeader)(nil), "gogorpc.proto.RequestHeader")
-	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.RequestHeader.ExtraDataEntry")
-	proto.RegisterType((*ResponseHeader)(nil), "gogorpc.proto.ResponseHeader")
-	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.ResponseHeader.ExtraDataEntry")
-	proto.RegisterType((*RPCError)(nil), "gogorpc.proto.RPCError")
-	proto.RegisterType((*Hangup)(nil), "gogorpc.proto.Hangup")
-	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.Hangup.ExtraDataEntry")
+func (m *Hangup) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
 }
 
-func init() {
-	proto.RegisterFile("github.com/let-z-go/gogorpc/internal/proto/stream.proto", fileDescriptor_4187f59d13635016)
+func (m *Hangup) GetRetryAfter() int32 {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return 0
 }
 
-var fileDescriptor_4187f59d13635016 = []byte{
-	// 913 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x95, 0xdf, 0x6e, 0xe3, 0x44,
-	0x14, 0xc6, 0xe3, 0x38, 0x2d, 0xed, 0x69, 0x1b, 0xcc, 0x2c, 0x0b, 0x69, 0xaa, 0xcd, 0x66, 0xa3,
-	0x45, 0x54, 0x85, 0x26, 0x52, 0x01, 0x81, 0x56, 0x2b, 0x24, 0x27, 0x99, 0x4d, 0x4c, 0x13, 0x3b,
-	0x4c, 0x9c, 0xa2, 0xee, 0x8d, 0xe5, 0xda, 0x43, 0x6a, 0x35, 0xb1, 0x8d, 0x63, 0x57, 0x84, 0x2b,
-	0x1e, 0x61, 0xd1, 0x72, 0xcf, 0x13, 0xf0, 0x02, 0x3c, 0xc1, 0x5e, 0x96, 0xbb, 0xbd, 0x42, 0xd0,
-	0xbe, 0x02, 0xff, 0x2e, 0xd1, 0x8c, 0xed, 0xa4, 0x4d, 0xb7, 0x68, 0x57, 0x7b, 0x95, 0x39, 0xe7,
-	0xfc, 0xbe, 0x73, 0xe6, 0x7c, 0x1e, 0x29, 0xf0, 0xe9, 0xd0, 0x09, 0x8f, 0xa3, 0xa3, 0xaa, 0xe5,
-	0x8d, 0x6b, 0x23, 0x1a, 0xee, 0x7e, 0xb7, 0x3b, 0xf4, 0x6a, 0x43, 0x6f, 0xe8, 0x05, 0xbe, 0x55,
-	0x73, 0xdc, 0x90, 0x06, 0xae, 0x39, 0xaa, 0xf9, 0x81, 0x17, 0x7a, 0xb5, 0x49, 0x18, 0x50, 0x73,
-	0x5c, 0xe5, 0x01, 0xda, 0x48, 0xa0, 0x38, 0x2c, 0xee, 0x5e, 0xea, 0xc3, 0x2a, 0xb1, 0xe4, 0x28,
-	0xfa, 0x9a, 0x47, 0xb1, 0x9e, 0x9d, 0x12, 0xfc, 0x93, 0x57, 0x18, 0x1b, 0x45, 0x8e, 0x1d, 0xcb,
-	0x2a, 0x4f, 0xb3, 0x70, 0xbb, 0xcf, 0x6f, 0xd1, 0x36, 0x5d, 0x7b, 0x72, 0x6c, 0x9e, 0xd0, 0x36,
-	0x35, 0x6d, 0x1a, 0xa0, 0xcf, 0x61, 0xcb, 0x71, 0x2d, 0x6f, 0xec, 0xb8, 0x43, 0xe3, 0x84, 0x52,
-	0xdf, 0x1c, 0x39, 0xa7, 0xd4, 0xe0, 0x9d, 0x4e, 0xcd, 0x51, 0x41, 0x28, 0x0b, 0xdb, 0x4b, 0x64,
-	0x33, 0x45, 0xf6, 0x53, 0x42, 0x49, 0x00, 0xa6, 0xf7, 0xa2, 0x70, 0xe8, 0xdd, 0xa0, 0xcf, 0xc6,
-	0xfa, 0x14, 0xb9, 0xae, 0x7f, 0x08, 0xc5, 0xd9, 0x7c, 0xcb, 0x73, 0xad, 0x28, 0x08, 0xa8, 0x6b,
-	0x4d, 0x8d, 0x91, 0x33, 0x76, 0xc2, 0x82, 0xc8, 0xe5, 0x85, 0x94, 0x68, 0xcc, 0x81, 0x0e, 0xab,
-	0x33, 0xf5, 0x6c, 0xfa, 0x75, 0x75, 0x2e, 0x56, 0xa7, 0xc4, 0xa2, 0xba, 0xf2, 0x3c, 0x0b, 0x1b,
-	0x84, 0x7e, 0x13, 0xd1, 0x49, 0x98, 0xb8, 0xf1, 0x3e, 0xbc, 0x39, 0x61, 0x09, 0xd7, 0xa2, 0x86,
-	0x1b, 0x8d, 0x8f, 0x68, 0x90, 0x38, 0x90, 0x4f, 0xd3, 0x2a, 0xcf, 0xa2, 0x7b, 0xb0, 0x3e, 0xa1,
-	0xc1, 0xa9, 0xc3, 0x38, 0x73, 0x4c, 0xf9, 0x9e, 0xab, 0x64, 0x2d, 0xc9, 0xa9, 0xe6, 0x98, 0xa2,
-	0xbb, 0xb0, 0x36, 0xa6, 0xe1, 0xb1, 0x67, 0xc7, 0x84, 0xc8, 0x09, 0x88, 0x53, 0x1c, 0xf8, 0x02,
-	0x80, 0x7e, 0x1b, 0x06, 0xa6, 0x61, 0x9b, 0xa1, 0x59, 0xc8, 0x95, 0xc5, 0xed, 0xb5, 0xbd, 0x0f,
-	0xaa, 0x57, 0x9e, 0x47, 0xf5, 0xca, 0xf5, 0xaa, 0x98, 0xe1, 0x4d, 0x33, 0x34, 0xb1, 0x1b, 0x06,
-	0x53, 0xb2, 0x4a, 0xd3, 0x18, 0x15, 0x61, 0xc5, 0xa6, 0xa6, 0x3d, 0x72, 0x5c, 0x5a, 0x58, 0x2a,
-	0x0b, 0xdb, 0x22, 0x99, 0xc5, 0xe8, 0x63, 0x58, 0x09, 0x03, 0xd3, 0xa2, 0x86, 0x63, 0x17, 0x96,
-	0xcb, 0xc2, 0xf6, 0xda, 0xde, 0xad, 0x85, 0x29, 0x83, 0x81, 0xd2, 0xac, 0xe7, 0x9e, 0xfd, 0x76,
-	0x37, 0x43, 0xde, 0xe0, 0xa8, 0x62, 0x17, 0x1f, 0x42, 0xfe, 0xea, 0x38, 0x24, 0x81, 0x78, 0x42,
-	0xa7, 0xdc, 0x90, 0x55, 0xc2, 0x8e, 0xe8, 0x6d, 0x58, 0x3a, 0x35, 0x47, 0x51, 0xbc, 0xfe, 0x3a,
-	0x89, 0x83, 0x07, 0xd9, 0xcf, 0x84, 0xca, 0xf7, 0x59, 0xc8, 0x13, 0x3a, 0xf1, 0x3d, 0x77, 0x42,
-	0x5f, 0xd5, 0xdb, 0xfd, 0x2b, 0xbe, 0x64, 0xb9, 0x2f, 0x1f, 0x5e, 0xf3, 0xe5, 0x72, 0xef, 0xff,
-	0x31, 0xe6, 0x01, 0xac, 0x06, 0xbe, 0x65, 0xd0, 0x20, 0xf0, 0x02, 0xfe, 0x0d, 0xd6, 0xf6, 0xde,
-	0x5d, 0xec, 0xd5, 0x6b, 0x60, 0x56, 0x4e, 0x1c, 0x58, 0x09, 0x7c, 0x8b, 0xc7, 0xaf, 0x69, 0x81,
-	0x05, 0x2b, 0x69, 0x67, 0x54, 0x83, 0x5c, 0x38, 0xf5, 0x29, 0x17, 0xe6, 0xf7, 0xb6, 0x6e, 0xb8,
-	0x80, 0x3e, 0xf5, 0x29, 0xe1, 0x20, 0x42, 0x90, 0xb3, 0x3c, 0x3b, 0x7d, 0x57, 0xfc, 0xcc, 0x72,
-	0x36, 0x9d, 0x58, 0xc9, 0x4b, 0xe2, 0xe7, 0xca, 0x2f, 0x02, 0x2c, 0xb7, 0x4d, 0x77, 0x18, 0xf9,
-	0x68, 0x37, 0x91, 0xc4, 0x33, 0x36, 0x17, 0x66, 0xc4, 0x50, 0xc3, 0xb3, 0x69, 0xd2, 0xad, 0xf1,
-	0x02, 0x97, 0xef, 0xbf, 0x50, 0x74, 0xb3, 0xbb, 0xaf, 0xe7, 0xd0, 0xce, 0xaf, 0x59, 0x58, 0xbf,
-	0xbc, 0x3b, 0x42, 0x90, 0x27, 0xbd, 0x86, 0x81, 0x09, 0xd1, 0x88, 0xa1, 0x6a, 0x2a, 0x96, 0x32,
-	0xa8, 0x08, 0xb7, 0xe7, 0xb9, 0xba, 0xdc, 0x34, 0x08, 0xfe, 0x72, 0x80, 0xfb, 0xba, 0xf4, 0x44,
-	0x44, 0x5b, 0xf0, 0xce, 0xbc, 0x36, 0x50, 0xe5, 0x81, 0xde, 0xd6, 0x88, 0xf2, 0x18, 0x37, 0xa5,
-	0x1f, 0x44, 0x54, 0x80, 0x5b, 0xf3, 0xe2, 0x23, 0x8d, 0xd4, 0x95, 0x66, 0x13, 0xab, 0xd2, 0xd3,
-	0x85, 0x8a, 0xaa, 0xe9, 0xc6, 0x23, 0x6d, 0xa0, 0x36, 0xa5, 0x1f, 0x45, 0x54, 0x86, 0xad, 0x79,
-	0x45, 0xd7, 0x34, 0xa3, 0x2b, 0xab, 0x87, 0xe9, 0xc4, 0xbe, 0xf4, 0xb3, 0x88, 0x4a, 0xb0, 0x39,
-	0x27, 0x14, 0x55, 0xc7, 0x44, 0x95, 0x3b, 0x46, 0x1f, 0x93, 0x03, 0x4c, 0xa4, 0x3f, 0x17, 0xea,
-	0xac, 0xb7, 0xd2, 0xed, 0x75, 0x70, 0x17, 0xab, 0x3a, 0x6e, 0x4a, 0x7f, 0x89, 0xd7, 0xd7, 0x69,
-	0xc9, 0x3a, 0xfe, 0x4a, 0x3e, 0x94, 0xfe, 0x16, 0x51, 0x05, 0xee, 0xcc, 0x6b, 0xac, 0xa5, 0xd2,
-	0xc0, 0x6c, 0xad, 0x03, 0x59, 0xe9, 0xc8, 0xf5, 0x0e, 0x96, 0xfe, 0x59, 0xe8, 0x9f, 0x68, 0x0d,
-	0x5d, 0xe9, 0x62, 0x6d, 0xa0, 0x4b, 0xff, 0x8a, 0x3b, 0x3f, 0x09, 0x00, 0xf3, 0x6f, 0xcd, 0x1c,
-	0x6d, 0xcb, 0x6a, 0x6b, 0xd0, 0x33, 0xe4, 0xba, 0x46, 0xd8, 0x1d, 0x32, 0xe8, 0x0e, 0x6c, 0x26,
-	0x39, 0x36, 0x5f, 0x51, 0x1b, 0x5a, 0x57, 0x51, 0x5b, 0x06, 0x3e, 0xc0, 0xaa, 0x2e, 0x09, 0xe8,
-	0x3d, 0xb8, 0x97, 0x94, 0x67, 0x06, 0xcc, 0x98, 0x99, 0x13, 0x59, 0x74, 0x1f, 0xca, 0x09, 0xa6,
-	0x0d, 0xf4, 0x96, 0xc6, 0xaa, 0x3d, 0xb9, 0xb1, 0x8f, 0x75, 0x2e, 0xeb, 0xc8, 0xa4, 0x85, 0x25,
-	0x11, 0xbd, 0x05, 0x1b, 0x09, 0xd5, 0x3f, 0xec, 0xeb, 0xb8, 0x2b, 0xe5, 0xea, 0xed, 0xb3, 0x3f,
-	0x4a, 0x99, 0x67, 0xe7, 0x25, 0xe1, 0xec, 0xbc, 0x24, 0xfc, 0x7e, 0x5e, 0x12, 0x9e, 0x5c, 0x94,
-	0x32, 0x67, 0x17, 0xa5, 0xcc, 0xf3, 0x8b, 0x52, 0xe6, 0xf1, 0xce, 0xcb, 0xff, 0xc1, 0x1d, 0x2d,
-	0xf3, 0x9f, 0x8f, 0xfe, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x21, 0x93, 0x96, 0x11, 0x8c, 0x07, 0x00,
-	0x00,
+func (m *Hangup) GetRedirectServerUrl() string {
+	if m != nil {
+		return m.RedirectServerUrl
+	}
+	return ""
 }
 
-func (m *StreamHandshakeHeader) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
+type GoAway struct {
+	LastSequenceNumber int32 `protobuf:"varint,1,opt,name=last_sequence_number,json=lastSequenceNumber,proto3" json:"last_sequence_number,omitempty"`
+}
+
+func (m *GoAway) Reset()         { *m = GoAway{} }
+func (m *GoAway) String() string { return proto.CompactTextString(m) }
+func (*GoAway) ProtoMessage()    {}
+func (*GoAway) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{10}
+}
+func (m *GoAway) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GoAway) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GoAway.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GoAway) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GoAway.Merge(m, src)
+}
+func (m *GoAway) XXX_Size() int {
+	return m.Size()
+}
+func (m *GoAway) XXX_DiscardUnknown() {
+	xxx_messageInfo_GoAway.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GoAway proto.InternalMessageInfo
+
+func (m *GoAway) GetLastSequenceNumber() int32 {
+	if m != nil {
+		return m.LastSequenceNumber
+	}
+	return 0
+}
+
+type WindowUpdate struct {
+	Increment int32 `protobuf:"varint,1,opt,name=increment,proto3" json:"increment,omitempty"`
+}
+
+func (m *WindowUpdate) Reset()         { *m = WindowUpdate{} }
+func (m *WindowUpdate) String() string { return proto.CompactTextString(m) }
+func (*WindowUpdate) ProtoMessage()    {}
+func (*WindowUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{11}
+}
+func (m *WindowUpdate) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *WindowUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_WindowUpdate.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *WindowUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WindowUpdate.Merge(m, src)
+}
+func (m *WindowUpdate) XXX_Size() int {
+	return m.Size()
+}
+func (m *WindowUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_WindowUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WindowUpdate proto.InternalMessageInfo
+
+func (m *WindowUpdate) GetIncrement() int32 {
+	if m != nil {
+		return m.Increment
+	}
+	return 0
+}
+
+type Settings struct {
+	IncomingKeepaliveInterval int32 `protobuf:"varint,1,opt,name=incoming_keepalive_interval,json=incomingKeepaliveInterval,proto3" json:"incoming_keepalive_interval,omitempty"`
+	IncomingConcurrencyLimit  int32 `protobuf:"varint,2,opt,name=incoming_concurrency_limit,json=incomingConcurrencyLimit,proto3" json:"incoming_concurrency_limit,omitempty"`
+	IsAck                     bool  `protobuf:"varint,3,opt,name=is_ack,json=isAck,proto3" json:"is_ack,omitempty"`
+}
+
+func (m *Settings) Reset()         { *m = Settings{} }
+func (m *Settings) String() string { return proto.CompactTextString(m) }
+func (*Settings) ProtoMessage()    {}
+func (*Settings) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4187f59d13635016, []int{12}
+}
+func (m *Settings) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Settings) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Settings.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Settings) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Settings.Merge(m, src)
+}
+func (m *Settings) XXX_Size() int {
+	return m.Size()
+}
+func (m *Settings) XXX_DiscardUnknown() {
+	xxx_messageInfo_Settings.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Settings proto.InternalMessageInfo
+
+func (m *Settings) GetIncomingKeepaliveInterval() int32 {
+	if m != nil {
+		return m.IncomingKeepaliveInterval
+	}
+	return 0
+}
+
+func (m *Settings) GetIncomingConcurrencyLimit() int32 {
+	if m != nil {
+		return m.IncomingConcurrencyLimit
+	}
+	return 0
+}
+
+func (m *Settings) GetIsAck() bool {
+	if m != nil {
+		return m.IsAck
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterEnum("gogorpc.proto.RPCErrorType", RPCErrorType_name, RPCErrorType_value)
+	proto.RegisterEnum("gogorpc.proto.HangupCode", HangupCode_name, HangupCode_value)
+	proto.RegisterType((*StreamHandshakeHeader)(nil), "gogorpc.proto.StreamHandshakeHeader")
+	proto.RegisterType((*KeepaliveHeader)(nil), "gogorpc.proto.KeepaliveHeader")
+	proto.RegisterType((*RequestHeader)(nil), "gogorpc.proto.RequestHeader")
+	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.RequestHeader.ExtraDataEntry")
+	proto.RegisterType((*ResponseHeader)(nil), "gogorpc.proto.ResponseHeader")
+	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.ResponseHeader.ExtraDataEntry")
+	proto.RegisterType((*StreamMessageHeader)(nil), "gogorpc.proto.StreamMessageHeader")
+	proto.RegisterType((*StreamEndHeader)(nil), "gogorpc.proto.StreamEndHeader")
+	proto.RegisterType((*CancelHeader)(nil), "gogorpc.proto.CancelHeader")
+	proto.RegisterType((*NotificationHeader)(nil), "gogorpc.proto.NotificationHeader")
+	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.NotificationHeader.ExtraDataEntry")
+	proto.RegisterType((*RPCError)(nil), "gogorpc.proto.RPCError")
+	proto.RegisterType((*Hangup)(nil), "gogorpc.proto.Hangup")
+	proto.RegisterMapType((map[string][]byte)(nil), "gogorpc.proto.Hangup.ExtraDataEntry")
+	proto.RegisterType((*GoAway)(nil), "gogorpc.proto.GoAway")
+	proto.RegisterType((*WindowUpdate)(nil), "gogorpc.proto.WindowUpdate")
+	proto.RegisterType((*Settings)(nil), "gogorpc.proto.Settings")
+}
+
+func init() {
+	proto.RegisterFile("github.com/let-z-go/gogorpc/internal/proto/stream.proto", fileDescriptor_4187f59d13635016)
+}
+
+var fileDescriptor_4187f59d13635016 = []byte{
+	// 1391 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0x4f, 0x6f, 0xdb, 0x56,
+	0x12, 0xb7, 0x44, 0xff, 0x91, 0xc6, 0x8e, 0x4d, 0x3f, 0x27, 0xbb, 0xb2, 0x9d, 0x28, 0x8e, 0x90,
+	0xc5, 0x66, 0xbd, 0xb1, 0x1d, 0x78, 0x77, 0x91, 0x85, 0x91, 0x5d, 0x80, 0x96, 0x18, 0x5b, 0x6b,
+	0x8b, 0xf2, 0x3e, 0x51, 0x09, 0x92, 0xcb, 0x03, 0x4d, 0x3e, 0xcb, 0x0f, 0x96, 0x48, 0xf5, 0xf1,
+	0xc9, 0xa9, 0xf2, 0x29, 0x52, 0xb4, 0xf7, 0x9c, 0x72, 0x2a, 0xfa, 0x3d, 0x72, 0x4c, 0x0f, 0x05,
+	0x7a, 0x2a, 0xda, 0xf8, 0xd0, 0x2f, 0xd0, 0x7f, 0xc7, 0xe2, 0x3d, 0x92, 0x92, 0x2c, 0x3b, 0x6e,
+	0x8c, 0x14, 0xe8, 0x29, 0x9c, 0x99, 0xdf, 0x6f, 0x66, 0xf4, 0x9b, 0x99, 0x90, 0x86, 0xfb, 0x0d,
+	0x26, 0x0e, 0x3b, 0xfb, 0xab, 0x6e, 0xd0, 0x5a, 0x6b, 0x52, 0xb1, 0xf2, 0x7c, 0xa5, 0x11, 0xac,
+	0x35, 0x82, 0x46, 0xc0, 0xdb, 0xee, 0x1a, 0xf3, 0x05, 0xe5, 0xbe, 0xd3, 0x5c, 0x6b, 0xf3, 0x40,
+	0x04, 0x6b, 0xa1, 0xe0, 0xd4, 0x69, 0xad, 0x2a, 0x03, 0x5d, 0x89, 0x41, 0x91, 0xb9, 0xb0, 0x32,
+	0x90, 0x47, 0x46, 0x22, 0xca, 0x7e, 0xe7, 0x40, 0x59, 0x11, 0x5f, 0x3e, 0xc5, 0xf0, 0x7f, 0x5d,
+	0xa2, 0x6c, 0xa7, 0xc3, 0xbc, 0x88, 0x56, 0xf8, 0x5e, 0x83, 0x6b, 0x35, 0xd5, 0xc5, 0xb6, 0xe3,
+	0x7b, 0xe1, 0xa1, 0x73, 0x44, 0xb7, 0xa9, 0xe3, 0x51, 0x8e, 0xfe, 0x0b, 0x8b, 0xcc, 0x77, 0x83,
+	0x16, 0xf3, 0x1b, 0xe4, 0x88, 0xd2, 0xb6, 0xd3, 0x64, 0xc7, 0x94, 0xa8, 0x4c, 0xc7, 0x4e, 0x33,
+	0x97, 0x5a, 0x4a, 0xdd, 0x19, 0xc3, 0xf3, 0x09, 0x64, 0x27, 0x41, 0x94, 0x63, 0x80, 0xe4, 0x07,
+	0x1d, 0xd1, 0x08, 0xde, 0xc1, 0x4f, 0x47, 0xfc, 0x04, 0x72, 0x96, 0xff, 0x00, 0x16, 0x7a, 0xf5,
+	0xdd, 0xc0, 0x77, 0x3b, 0x9c, 0x53, 0xdf, 0xed, 0x92, 0x26, 0x6b, 0x31, 0x91, 0xd3, 0x14, 0x3d,
+	0x97, 0x20, 0x8a, 0x7d, 0xc0, 0xae, 0x8c, 0x4b, 0x76, 0xaf, 0xfa, 0x59, 0xf6, 0x68, 0xc4, 0x4e,
+	0x10, 0x67, 0xd8, 0xf7, 0xe0, 0x6a, 0xaf, 0xf6, 0x33, 0xe6, 0x7b, 0xc1, 0x33, 0x12, 0xb2, 0xe7,
+	0x34, 0x37, 0xa6, 0x78, 0x28, 0x89, 0x3d, 0x56, 0xa1, 0x1a, 0x7b, 0x4e, 0x25, 0xa3, 0x57, 0x6f,
+	0x90, 0x31, 0x1e, 0x31, 0x92, 0xd8, 0x00, 0xe3, 0x3f, 0xb0, 0xc8, 0xc2, 0x01, 0x65, 0x0e, 0x95,
+	0xec, 0x84, 0xfa, 0xce, 0x7e, 0x93, 0x7a, 0xb9, 0x89, 0xa5, 0xd4, 0x9d, 0x0c, 0xce, 0xb1, 0xb0,
+	0xa7, 0x4c, 0x34, 0x17, 0x33, 0x8a, 0xa3, 0x35, 0xb8, 0xca, 0x42, 0xd2, 0xa2, 0xe2, 0x30, 0xf0,
+	0x08, 0xf3, 0x7a, 0xbc, 0x8c, 0xe2, 0xcd, 0xb2, 0xb0, 0xa2, 0x42, 0x65, 0x2f, 0x26, 0x14, 0x5e,
+	0xa5, 0x60, 0x66, 0x28, 0x17, 0xba, 0x0e, 0x59, 0xc1, 0x5a, 0x34, 0x14, 0x4e, 0xab, 0xad, 0x26,
+	0xaa, 0xe1, 0xbe, 0x03, 0xfd, 0x0d, 0x74, 0xea, 0x1e, 0x06, 0xd4, 0x23, 0x7d, 0x50, 0x5a, 0x81,
+	0x66, 0x22, 0xbf, 0xdd, 0x83, 0xde, 0x00, 0x90, 0x2e, 0xe2, 0xd1, 0xa6, 0xd3, 0x55, 0xc3, 0xd1,
+	0x70, 0x56, 0x7a, 0x4a, 0xd2, 0x81, 0x96, 0x61, 0x96, 0x85, 0x44, 0x21, 0x38, 0xfd, 0xa8, 0x43,
+	0x43, 0x41, 0x3d, 0x35, 0x84, 0x0c, 0x9e, 0x61, 0xa1, 0xe9, 0x1e, 0x06, 0x38, 0x71, 0x17, 0x3e,
+	0xd7, 0xe0, 0x4a, 0x6c, 0xc5, 0x5d, 0xfe, 0x15, 0x66, 0x42, 0xe9, 0xf0, 0x5d, 0x4a, 0xfc, 0x4e,
+	0x6b, 0x9f, 0xf2, 0x78, 0xfb, 0xa6, 0x13, 0xb7, 0xa5, 0xbc, 0xe8, 0x16, 0x4c, 0x85, 0x94, 0x1f,
+	0x33, 0x89, 0x73, 0x5a, 0x54, 0x35, 0x9b, 0xc5, 0x93, 0xb1, 0xcf, 0x72, 0x5a, 0x14, 0xdd, 0x84,
+	0xc9, 0x58, 0x33, 0x85, 0xd0, 0x14, 0x02, 0x22, 0x97, 0x02, 0xfc, 0x0f, 0x80, 0x7e, 0x2c, 0xb8,
+	0x43, 0x3c, 0x47, 0x38, 0xb9, 0xd1, 0x25, 0xed, 0xce, 0xe4, 0xfa, 0xdf, 0x57, 0x4f, 0x9d, 0xe6,
+	0xea, 0xa9, 0xf6, 0x56, 0x4d, 0x09, 0x2f, 0x39, 0xc2, 0x31, 0x7d, 0xc1, 0xbb, 0x38, 0x4b, 0x13,
+	0x1b, 0x2d, 0x40, 0xc6, 0xa3, 0x8e, 0xd7, 0x64, 0x7e, 0xb4, 0x3a, 0x1a, 0xee, 0xd9, 0xe8, 0x9f,
+	0x90, 0x11, 0xdc, 0x71, 0x29, 0x61, 0x9e, 0x5a, 0x92, 0xc9, 0xf5, 0xb9, 0xa1, 0x2a, 0xf5, 0x7a,
+	0xb9, 0xb4, 0x39, 0xfa, 0xfa, 0x9b, 0x9b, 0x23, 0x78, 0x42, 0x41, 0xcb, 0x1e, 0xca, 0xc1, 0x84,
+	0x9c, 0x45, 0xd0, 0x11, 0x6a, 0x41, 0x34, 0x9c, 0x98, 0xb2, 0x56, 0x9b, 0xb3, 0x80, 0x33, 0xd1,
+	0x55, 0x3b, 0x30, 0x86, 0x7b, 0x36, 0x5a, 0x84, 0x6c, 0x6f, 0x51, 0x72, 0xd9, 0x28, 0xd8, 0x8a,
+	0xd7, 0x63, 0xe1, 0x01, 0x4c, 0x9f, 0xfe, 0x05, 0x48, 0x07, 0xed, 0x88, 0x76, 0x95, 0xc6, 0x59,
+	0x2c, 0x1f, 0xd1, 0x55, 0x18, 0x3b, 0x76, 0x9a, 0x9d, 0x48, 0xd1, 0x29, 0x1c, 0x19, 0x1b, 0xe9,
+	0x7f, 0xa7, 0x0a, 0x2f, 0xd3, 0x30, 0x8d, 0x69, 0xd8, 0x0e, 0xfc, 0x90, 0x5e, 0x76, 0x5c, 0x3b,
+	0xa7, 0xa4, 0x4e, 0x2b, 0xa9, 0xef, 0x9e, 0x91, 0x7a, 0x30, 0xf7, 0x05, 0x5a, 0x6f, 0x40, 0x96,
+	0xb7, 0x5d, 0x42, 0x39, 0x0f, 0xb8, 0x1a, 0xeb, 0xe4, 0xfa, 0x9f, 0x87, 0x73, 0xed, 0x15, 0x4d,
+	0x19, 0x8e, 0x45, 0xcd, 0xf0, 0xb6, 0xab, 0x6c, 0xb9, 0xbd, 0x2c, 0x24, 0x9c, 0x1e, 0x74, 0xc2,
+	0xde, 0x5e, 0x66, 0x59, 0x88, 0x23, 0xc7, 0x07, 0x2a, 0x44, 0x60, 0x2e, 0xfa, 0x0f, 0xb6, 0x42,
+	0xc3, 0xd0, 0x69, 0x5c, 0x5a, 0xa5, 0x9b, 0x30, 0xa9, 0x9a, 0x8b, 0x74, 0x50, 0xf9, 0x33, 0x18,
+	0x64, 0x77, 0x91, 0xa7, 0xb0, 0x01, 0x33, 0x51, 0x01, 0xd3, 0xf7, 0x2e, 0x99, 0xbc, 0x70, 0x1f,
+	0xa6, 0x8a, 0x8e, 0xef, 0xd2, 0xe6, 0x65, 0x89, 0x27, 0x69, 0x40, 0x56, 0x20, 0xd8, 0x01, 0x73,
+	0x1d, 0xc1, 0x02, 0xff, 0x8f, 0x38, 0xd5, 0xea, 0x39, 0xa7, 0x7a, 0x6f, 0x68, 0xe6, 0x67, 0x7b,
+	0xbc, 0x60, 0x87, 0x06, 0x6f, 0x72, 0xec, 0xbd, 0x6f, 0xf2, 0xd4, 0x75, 0x8d, 0xff, 0xae, 0xd7,
+	0xe5, 0x42, 0x26, 0x59, 0x5a, 0xb4, 0x06, 0xa3, 0xa2, 0xdb, 0xa6, 0x8a, 0x38, 0xbd, 0xbe, 0xf8,
+	0x8e, 0xdd, 0xb6, 0xbb, 0x6d, 0x8a, 0x15, 0x10, 0x21, 0x18, 0x75, 0x03, 0x2f, 0x91, 0x56, 0x3d,
+	0x4b, 0x9f, 0x47, 0x43, 0x37, 0x16, 0x53, 0x3d, 0x17, 0x5e, 0xa5, 0x61, 0x7c, 0xdb, 0xf1, 0x1b,
+	0x9d, 0x36, 0x5a, 0x89, 0x29, 0x51, 0x8d, 0xf9, 0xa1, 0x1a, 0x11, 0xa8, 0x18, 0x78, 0x34, 0xce,
+	0x56, 0x3c, 0xe7, 0x80, 0x6f, 0x9f, 0x4b, 0xba, 0x40, 0xf4, 0x1c, 0x4c, 0xb4, 0xa2, 0xcb, 0x88,
+	0xbb, 0x4a, 0x4c, 0xb9, 0x00, 0x9c, 0x0a, 0xde, 0x25, 0xce, 0x81, 0xa0, 0x3c, 0x7e, 0x69, 0x83,
+	0x72, 0x19, 0xd2, 0x83, 0x56, 0x61, 0x8e, 0x53, 0x8f, 0x71, 0xea, 0x0a, 0x22, 0x37, 0x87, 0x72,
+	0xd2, 0xe1, 0x4d, 0x35, 0xba, 0x2c, 0x9e, 0x4d, 0x42, 0x35, 0x15, 0xa9, 0xf3, 0xe6, 0x07, 0x0e,
+	0x63, 0x03, 0xc6, 0xb7, 0x02, 0xe3, 0x99, 0xd3, 0x95, 0x2f, 0xfb, 0xa6, 0x13, 0x0a, 0x92, 0xec,
+	0xf4, 0xe9, 0x55, 0x47, 0x32, 0x56, 0x3b, 0x7d, 0x2e, 0x77, 0x61, 0x2a, 0x7a, 0xf5, 0xd7, 0xdb,
+	0x9e, 0x23, 0xa8, 0x7c, 0xf1, 0x32, 0xdf, 0xe5, 0xb4, 0x45, 0x7d, 0x11, 0xd3, 0xfa, 0x8e, 0xc2,
+	0xcb, 0x14, 0x64, 0x6a, 0x54, 0x08, 0xe6, 0x37, 0xc2, 0x0f, 0xfe, 0x0e, 0xbb, 0xf8, 0x3b, 0x2a,
+	0xfd, 0x1b, 0xdf, 0x51, 0xd7, 0x60, 0x9c, 0x85, 0xc4, 0x71, 0x8f, 0xd4, 0x70, 0x32, 0x78, 0x8c,
+	0x85, 0x86, 0x7b, 0xb4, 0xfc, 0x65, 0x1a, 0xa6, 0x06, 0x57, 0x0e, 0x21, 0x98, 0xc6, 0x7b, 0x45,
+	0x62, 0x62, 0x5c, 0xc5, 0xc4, 0xaa, 0x5a, 0xa6, 0x3e, 0x82, 0x16, 0xe0, 0x5a, 0xdf, 0xb7, 0x69,
+	0x94, 0x08, 0x36, 0xff, 0x5f, 0x37, 0x6b, 0xb6, 0xfe, 0x42, 0x43, 0x8b, 0xf0, 0xa7, 0x7e, 0xac,
+	0x6e, 0x19, 0x75, 0x7b, 0xbb, 0x8a, 0xcb, 0x4f, 0xcd, 0x92, 0xfe, 0x89, 0x86, 0x72, 0x30, 0xd7,
+	0x0f, 0x3e, 0xac, 0xe2, 0xcd, 0x72, 0xa9, 0x64, 0x5a, 0xfa, 0xa7, 0x43, 0x11, 0xab, 0x6a, 0x93,
+	0x87, 0xd5, 0xba, 0x55, 0xd2, 0x3f, 0xd3, 0xd0, 0x12, 0x2c, 0xf6, 0x23, 0x76, 0xb5, 0x4a, 0x2a,
+	0x86, 0xf5, 0x24, 0xa9, 0x58, 0xd3, 0xbf, 0xd0, 0x50, 0x1e, 0xe6, 0xfb, 0x88, 0xb2, 0x65, 0x9b,
+	0xd8, 0x32, 0x76, 0x49, 0xcd, 0xc4, 0x8f, 0x4c, 0xac, 0xff, 0x30, 0x14, 0x97, 0xb9, 0xcb, 0x95,
+	0xbd, 0x5d, 0xb3, 0x62, 0x5a, 0xb6, 0x59, 0xd2, 0x7f, 0xd4, 0xce, 0xfe, 0x9c, 0x2d, 0xc3, 0x36,
+	0x1f, 0x1b, 0x4f, 0xf4, 0x9f, 0x34, 0x54, 0x80, 0x1b, 0xfd, 0x98, 0x4c, 0x59, 0x2e, 0x9a, 0xf2,
+	0x67, 0x3d, 0x32, 0xca, 0xbb, 0xc6, 0xe6, 0xae, 0xa9, 0xff, 0x3c, 0x94, 0x3f, 0xe6, 0x12, 0xbb,
+	0x5c, 0x31, 0xab, 0x75, 0x5b, 0xff, 0x45, 0x5b, 0xfe, 0x2a, 0x05, 0xd0, 0x3f, 0x31, 0xa9, 0xe8,
+	0xb6, 0x61, 0x6d, 0xd5, 0xf7, 0x88, 0xb1, 0x59, 0xc5, 0xb2, 0x87, 0x11, 0x74, 0x03, 0xe6, 0x63,
+	0x9f, 0xac, 0x5f, 0xb6, 0x8a, 0xd5, 0x4a, 0xd9, 0xda, 0x22, 0xe6, 0x23, 0xd3, 0xb2, 0xf5, 0x14,
+	0xfa, 0x0b, 0xdc, 0x8a, 0xc3, 0x3d, 0x01, 0x7a, 0x98, 0x9e, 0x12, 0x69, 0x74, 0x1b, 0x96, 0x62,
+	0x58, 0xb5, 0x6e, 0x6f, 0x55, 0x65, 0x74, 0xcf, 0x28, 0xee, 0x98, 0xb6, 0xa2, 0xed, 0x1a, 0x78,
+	0xcb, 0xd4, 0x35, 0x34, 0x0b, 0x57, 0x62, 0x54, 0xed, 0x49, 0xcd, 0x36, 0x2b, 0xfa, 0xe8, 0x40,
+	0x4b, 0x25, 0x6c, 0x94, 0x2d, 0xb3, 0xa4, 0x8f, 0xa1, 0x25, 0xb8, 0x7e, 0x5e, 0x4b, 0xc5, 0x6d,
+	0xb3, 0xb8, 0x53, 0xab, 0x57, 0xf4, 0xf1, 0xcd, 0xed, 0x37, 0xdf, 0xe5, 0x47, 0x5e, 0xbf, 0xcd,
+	0xa7, 0xde, 0xbc, 0xcd, 0xa7, 0xbe, 0x7d, 0x9b, 0x4f, 0xbd, 0x38, 0xc9, 0x8f, 0xbc, 0x39, 0xc9,
+	0x8f, 0x7c, 0x7d, 0x92, 0x1f, 0x79, 0xba, 0xfc, 0xfe, 0x7f, 0xb7, 0xec, 0x8f, 0xab, 0x7f, 0xfe,
+	0xf1, 0x6b, 0x00, 0x00, 0x00, 0xff, 0xff, 0xf7, 0x49, 0x43, 0x61, 0x63, 0x0d, 0x00, 0x00,
+}
+
+func (m *StreamHandshakeHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
 	if err != nil {
 		return nil, err
@@ -532,6 +1104,36 @@ func (m *StreamHandshakeHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.IsMethodIdEnabled {
+		i--
+		if m.IsMethodIdEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.IsKeepaliveHeaderEnabled {
+		i--
+		if m.IsKeepaliveHeaderEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.OutgoingWindowSize != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.OutgoingWindowSize))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.IncomingWindowSize != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.IncomingWindowSize))
+		i--
+		dAtA[i] = 0x28
+	}
 	if m.OutgoingConcurrencyLimit != 0 {
 		i = encodeVarintStream(dAtA, i, uint64(m.OutgoingConcurrencyLimit))
 		i--
@@ -555,6 +1157,54 @@ func (m *StreamHandshakeHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *KeepaliveHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeepaliveHeader) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *KeepaliveHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.IsEchoRequested {
+		i--
+		if m.IsEchoRequested {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.EchoDelay != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.EchoDelay))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.EchoedTimestamp != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.EchoedTimestamp))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Timestamp != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *RequestHeader) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -575,6 +1225,21 @@ func (m *RequestHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.MethodId != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.MethodId))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Priority != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.Priority))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Timeout != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.Timeout))
+		i--
+		dAtA[i] = 0x38
+	}
 	{
 		size, err := m.TraceId.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -653,6 +1318,16 @@ func (m *ResponseHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.IsRefused {
+		i--
+		if m.IsRefused {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	{
 		size, err := m.RpcError.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -692,7 +1367,7 @@ func (m *ResponseHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *RPCError) Marshal() (dAtA []byte, err error) {
+func (m *StreamMessageHeader) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -702,39 +1377,63 @@ func (m *RPCError) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *RPCError) MarshalTo(dAtA []byte) (int, error) {
+func (m *StreamMessageHeader) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *RPCError) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *StreamMessageHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Desc) > 0 {
-		i -= len(m.Desc)
-		copy(dAtA[i:], m.Desc)
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Desc)))
+	if m.IsResponse {
 		i--
-		dAtA[i] = 0x1a
+		if m.IsResponse {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
 	}
-	if len(m.Code) > 0 {
-		i -= len(m.Code)
-		copy(dAtA[i:], m.Code)
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Code)))
+	if m.SequenceNumber != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.SequenceNumber))
 		i--
-		dAtA[i] = 0x12
+		dAtA[i] = 0x8
 	}
-	if m.Type != 0 {
-		i = encodeVarintStream(dAtA, i, uint64(m.Type))
+	return len(dAtA) - i, nil
+}
+
+func (m *StreamEndHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StreamEndHeader) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StreamEndHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.SequenceNumber != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.SequenceNumber))
 		i--
 		dAtA[i] = 0x8
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *Hangup) Marshal() (dAtA []byte, err error) {
+func (m *CancelHeader) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -744,16 +1443,59 @@ func (m *Hangup) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Hangup) MarshalTo(dAtA []byte) (int, error) {
+func (m *CancelHeader) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *Hangup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *CancelHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.SequenceNumber != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.SequenceNumber))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NotificationHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NotificationHeader) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NotificationHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	if m.MethodId != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.MethodId))
+		i--
+		dAtA[i] = 0x30
+	}
+	{
+		size, err := m.TraceId.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStream(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x2a
 	if len(m.ExtraData) > 0 {
 		for k := range m.ExtraData {
 			v := m.ExtraData[k]
@@ -772,10 +1514,134 @@ func (m *Hangup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 			dAtA[i] = 0xa
 			i = encodeVarintStream(dAtA, i, uint64(baseI-i))
 			i--
-			dAtA[i] = 0x12
+			dAtA[i] = 0x22
 		}
 	}
-	if m.Code != 0 {
+	if len(m.MethodName) > 0 {
+		i -= len(m.MethodName)
+		copy(dAtA[i:], m.MethodName)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.MethodName)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ServiceName) > 0 {
+		i -= len(m.ServiceName)
+		copy(dAtA[i:], m.ServiceName)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.ServiceName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.SequenceNumber != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.SequenceNumber))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RPCError) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RPCError) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RPCError) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Desc) > 0 {
+		i -= len(m.Desc)
+		copy(dAtA[i:], m.Desc)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Desc)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Code) > 0 {
+		i -= len(m.Code)
+		copy(dAtA[i:], m.Code)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Code)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Type != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Hangup) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Hangup) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Hangup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.RedirectServerUrl) > 0 {
+		i -= len(m.RedirectServerUrl)
+		copy(dAtA[i:], m.RedirectServerUrl)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.RedirectServerUrl)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.RetryAfter != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.RetryAfter))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ExtraData) > 0 {
+		for k := range m.ExtraData {
+			v := m.ExtraData[k]
+			baseI := i
+			if len(v) > 0 {
+				i -= len(v)
+				copy(dAtA[i:], v)
+				i = encodeVarintStream(dAtA, i, uint64(len(v)))
+				i--
+				dAtA[i] = 0x12
+			}
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintStream(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintStream(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Code != 0 {
 		i = encodeVarintStream(dAtA, i, uint64(m.Code))
 		i--
 		dAtA[i] = 0x8
@@ -783,6 +1649,105 @@ func (m *Hangup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *GoAway) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GoAway) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GoAway) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.LastSequenceNumber != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.LastSequenceNumber))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *WindowUpdate) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WindowUpdate) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WindowUpdate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Increment != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.Increment))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Settings) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Settings) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Settings) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.IsAck {
+		i--
+		if m.IsAck {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.IncomingConcurrencyLimit != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.IncomingConcurrencyLimit))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.IncomingKeepaliveInterval != 0 {
+		i = encodeVarintStream(dAtA, i, uint64(m.IncomingKeepaliveInterval))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintStream(dAtA []byte, offset int, v uint64) int {
 	offset -= sovStream(v)
 	base := offset
@@ -812,6 +1777,39 @@ func (m *StreamHandshakeHeader) Size() (n int) {
 	if m.OutgoingConcurrencyLimit != 0 {
 		n += 1 + sovStream(uint64(m.OutgoingConcurrencyLimit))
 	}
+	if m.IncomingWindowSize != 0 {
+		n += 1 + sovStream(uint64(m.IncomingWindowSize))
+	}
+	if m.OutgoingWindowSize != 0 {
+		n += 1 + sovStream(uint64(m.OutgoingWindowSize))
+	}
+	if m.IsKeepaliveHeaderEnabled {
+		n += 2
+	}
+	if m.IsMethodIdEnabled {
+		n += 2
+	}
+	return n
+}
+
+func (m *KeepaliveHeader) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Timestamp != 0 {
+		n += 1 + sovStream(uint64(m.Timestamp))
+	}
+	if m.EchoedTimestamp != 0 {
+		n += 1 + sovStream(uint64(m.EchoedTimestamp))
+	}
+	if m.EchoDelay != 0 {
+		n += 1 + sovStream(uint64(m.EchoDelay))
+	}
+	if m.IsEchoRequested {
+		n += 2
+	}
 	return n
 }
 
@@ -849,6 +1847,15 @@ func (m *RequestHeader) Size() (n int) {
 	}
 	l = m.TraceId.Size()
 	n += 1 + l + sovStream(uint64(l))
+	if m.Timeout != 0 {
+		n += 1 + sovStream(uint64(m.Timeout))
+	}
+	if m.Priority != 0 {
+		n += 1 + sovStream(uint64(m.Priority))
+	}
+	if m.MethodId != 0 {
+		n += 1 + sovStream(uint64(m.MethodId))
+	}
 	return n
 }
 
@@ -875,57 +1882,189 @@ func (m *ResponseHeader) Size() (n int) {
 	}
 	l = m.RpcError.Size()
 	n += 1 + l + sovStream(uint64(l))
+	if m.IsRefused {
+		n += 2
+	}
 	return n
 }
 
-func (m *RPCError) Size() (n int) {
+func (m *StreamMessageHeader) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.Type != 0 {
-		n += 1 + sovStream(uint64(m.Type))
-	}
-	l = len(m.Code)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	if m.SequenceNumber != 0 {
+		n += 1 + sovStream(uint64(m.SequenceNumber))
 	}
-	l = len(m.Desc)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	if m.IsResponse {
+		n += 2
 	}
 	return n
 }
 
-func (m *Hangup) Size() (n int) {
+func (m *StreamEndHeader) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.Code != 0 {
-		n += 1 + sovStream(uint64(m.Code))
-	}
-	if len(m.ExtraData) > 0 {
-		for k, v := range m.ExtraData {
-			_ = k
-			_ = v
-			l = 0
-			if len(v) > 0 {
-				l = 1 + len(v) + sovStream(uint64(len(v)))
-			}
-			mapEntrySize := 1 + len(k) + sovStream(uint64(len(k))) + l
-			n += mapEntrySize + 1 + sovStream(uint64(mapEntrySize))
-		}
+	if m.SequenceNumber != 0 {
+		n += 1 + sovStream(uint64(m.SequenceNumber))
 	}
 	return n
 }
 
-func sovStream(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
+func (m *CancelHeader) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SequenceNumber != 0 {
+		n += 1 + sovStream(uint64(m.SequenceNumber))
+	}
+	return n
 }
-func sozStream(x uint64) (n int) {
+
+func (m *NotificationHeader) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SequenceNumber != 0 {
+		n += 1 + sovStream(uint64(m.SequenceNumber))
+	}
+	l = len(m.ServiceName)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.MethodName)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if len(m.ExtraData) > 0 {
+		for k, v := range m.ExtraData {
+			_ = k
+			_ = v
+			l = 0
+			if len(v) > 0 {
+				l = 1 + len(v) + sovStream(uint64(len(v)))
+			}
+			mapEntrySize := 1 + len(k) + sovStream(uint64(len(k))) + l
+			n += mapEntrySize + 1 + sovStream(uint64(mapEntrySize))
+		}
+	}
+	l = m.TraceId.Size()
+	n += 1 + l + sovStream(uint64(l))
+	if m.MethodId != 0 {
+		n += 1 + sovStream(uint64(m.MethodId))
+	}
+	return n
+}
+
+func (m *RPCError) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovStream(uint64(m.Type))
+	}
+	l = len(m.Code)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.Desc)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	return n
+}
+
+func (m *Hangup) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Code != 0 {
+		n += 1 + sovStream(uint64(m.Code))
+	}
+	if len(m.ExtraData) > 0 {
+		for k, v := range m.ExtraData {
+			_ = k
+			_ = v
+			l = 0
+			if len(v) > 0 {
+				l = 1 + len(v) + sovStream(uint64(len(v)))
+			}
+			mapEntrySize := 1 + len(k) + sovStream(uint64(len(k))) + l
+			n += mapEntrySize + 1 + sovStream(uint64(mapEntrySize))
+		}
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.RetryAfter != 0 {
+		n += 1 + sovStream(uint64(m.RetryAfter))
+	}
+	l = len(m.RedirectServerUrl)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	return n
+}
+
+func (m *GoAway) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LastSequenceNumber != 0 {
+		n += 1 + sovStream(uint64(m.LastSequenceNumber))
+	}
+	return n
+}
+
+func (m *WindowUpdate) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Increment != 0 {
+		n += 1 + sovStream(uint64(m.Increment))
+	}
+	return n
+}
+
+func (m *Settings) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.IncomingKeepaliveInterval != 0 {
+		n += 1 + sovStream(uint64(m.IncomingKeepaliveInterval))
+	}
+	if m.IncomingConcurrencyLimit != 0 {
+		n += 1 + sovStream(uint64(m.IncomingConcurrencyLimit))
+	}
+	if m.IsAck {
+		n += 2
+	}
+	return n
+}
+
+func sovStream(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozStream(x uint64) (n int) {
 	return sovStream(uint64((x << 1) ^ uint64((int64(x) >> 63))))
 }
 func (m *StreamHandshakeHeader) Unmarshal(dAtA []byte) error {
@@ -995,30 +2134,1100 @@ func (m *StreamHandshakeHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IncomingConcurrencyLimit", wireType)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncomingConcurrencyLimit", wireType)
+			}
+			m.IncomingConcurrencyLimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.IncomingConcurrencyLimit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutgoingConcurrencyLimit", wireType)
+			}
+			m.OutgoingConcurrencyLimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OutgoingConcurrencyLimit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncomingWindowSize", wireType)
+			}
+			m.IncomingWindowSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.IncomingWindowSize |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutgoingWindowSize", wireType)
+			}
+			m.OutgoingWindowSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OutgoingWindowSize |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsKeepaliveHeaderEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsKeepaliveHeaderEnabled = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsMethodIdEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsMethodIdEnabled = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeepaliveHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeepaliveHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeepaliveHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EchoedTimestamp", wireType)
+			}
+			m.EchoedTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EchoedTimestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EchoDelay", wireType)
+			}
+			m.EchoDelay = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EchoDelay |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsEchoRequested", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsEchoRequested = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
+			}
+			m.SequenceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SequenceNumber |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ServiceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MethodName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MethodName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExtraData", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ExtraData == nil {
+				m.ExtraData = make(map[string][]byte)
+			}
+			var mapkey string
+			mapvalue := []byte{}
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthStream
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthStream
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapbyteLen uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapbyteLen |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intMapbyteLen := int(mapbyteLen)
+					if intMapbyteLen < 0 {
+						return ErrInvalidLengthStream
+					}
+					postbytesIndex := iNdEx + intMapbyteLen
+					if postbytesIndex < 0 {
+						return ErrInvalidLengthStream
+					}
+					if postbytesIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = make([]byte, mapbyteLen)
+					copy(mapvalue, dAtA[iNdEx:postbytesIndex])
+					iNdEx = postbytesIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipStream(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthStream
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.ExtraData[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deadline", wireType)
+			}
+			m.Deadline = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Deadline |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceId", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			m.Timeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timeout |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MethodId", wireType)
+			}
+			m.MethodId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MethodId |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
+			}
+			m.SequenceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SequenceNumber |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExtraData", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ExtraData == nil {
+				m.ExtraData = make(map[string][]byte)
+			}
+			var mapkey string
+			mapvalue := []byte{}
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthStream
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthStream
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapbyteLen uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapbyteLen |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intMapbyteLen := int(mapbyteLen)
+					if intMapbyteLen < 0 {
+						return ErrInvalidLengthStream
+					}
+					postbytesIndex := iNdEx + intMapbyteLen
+					if postbytesIndex < 0 {
+						return ErrInvalidLengthStream
+					}
+					if postbytesIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = make([]byte, mapbyteLen)
+					copy(mapvalue, dAtA[iNdEx:postbytesIndex])
+					iNdEx = postbytesIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipStream(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthStream
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.ExtraData[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RpcError", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.RpcError.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsRefused", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsRefused = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StreamMessageHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StreamMessageHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StreamMessageHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
+			}
+			m.SequenceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SequenceNumber |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsResponse", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsResponse = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StreamEndHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StreamEndHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StreamEndHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
+			}
+			m.SequenceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SequenceNumber |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CancelHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
-			m.IncomingConcurrencyLimit = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStream
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.IncomingConcurrencyLimit |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-		case 4:
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CancelHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CancelHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field OutgoingConcurrencyLimit", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
 			}
-			m.OutgoingConcurrencyLimit = 0
+			m.SequenceNumber = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1028,7 +3237,7 @@ func (m *StreamHandshakeHeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.OutgoingConcurrencyLimit |= int32(b&0x7F) << shift
+				m.SequenceNumber |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -1057,7 +3266,7 @@ func (m *StreamHandshakeHeader) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RequestHeader) Unmarshal(dAtA []byte) error {
+func (m *NotificationHeader) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1080,10 +3289,10 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RequestHeader: wiretype end group for non-group")
+			return fmt.Errorf("proto: NotificationHeader: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RequestHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: NotificationHeader: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1298,10 +3507,10 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 			m.ExtraData[mapkey] = mapvalue
 			iNdEx = postIndex
 		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Deadline", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceId", wireType)
 			}
-			m.Deadline = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1311,16 +3520,121 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Deadline |= int64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MethodId", wireType)
+			}
+			m.MethodId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MethodId |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RPCError) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RPCError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RPCError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= RPCErrorType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TraceId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1330,24 +3644,55 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthStream
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.TraceId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Code = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Desc", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.Desc = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1373,7 +3718,7 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
+func (m *Hangup) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1396,17 +3741,17 @@ func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ResponseHeader: wiretype end group for non-group")
+			return fmt.Errorf("proto: Hangup: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ResponseHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Hangup: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SequenceNumber", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
 			}
-			m.SequenceNumber = 0
+			m.Code = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1416,7 +3761,7 @@ func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.SequenceNumber |= int32(b&0x7F) << shift
+				m.Code |= HangupCode(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -1547,13 +3892,64 @@ func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
 					iNdEx += skippy
 				}
 			}
-			m.ExtraData[mapkey] = mapvalue
-			iNdEx = postIndex
-		case 3:
+			m.ExtraData[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStream
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RetryAfter", wireType)
+			}
+			m.RetryAfter = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RetryAfter |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RpcError", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RedirectServerUrl", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1563,24 +3959,23 @@ func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthStream
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.RpcError.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.RedirectServerUrl = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1606,7 +4001,7 @@ func (m *ResponseHeader) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RPCError) Unmarshal(dAtA []byte) error {
+func (m *GoAway) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1629,17 +4024,17 @@ func (m *RPCError) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RPCError: wiretype end group for non-group")
+			return fmt.Errorf("proto: GoAway: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RPCError: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GoAway: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LastSequenceNumber", wireType)
 			}
-			m.Type = 0
+			m.LastSequenceNumber = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1649,48 +4044,69 @@ func (m *RPCError) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= RPCErrorType(b&0x7F) << shift
+				m.LastSequenceNumber |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStream
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthStream
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Code = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Desc", wireType)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WindowUpdate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
 			}
-			var stringLen uint64
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WindowUpdate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WindowUpdate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Increment", wireType)
+			}
+			m.Increment = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1700,24 +4116,11 @@ func (m *RPCError) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Increment |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthStream
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthStream
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Desc = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStream(dAtA[iNdEx:])
@@ -1742,7 +4145,7 @@ func (m *RPCError) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Hangup) Unmarshal(dAtA []byte) error {
+func (m *Settings) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1765,17 +4168,17 @@ func (m *Hangup) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Hangup: wiretype end group for non-group")
+			return fmt.Errorf("proto: Settings: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Hangup: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Settings: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IncomingKeepaliveInterval", wireType)
 			}
-			m.Code = 0
+			m.IncomingKeepaliveInterval = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1785,16 +4188,16 @@ func (m *Hangup) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Code |= HangupCode(b&0x7F) << shift
+				m.IncomingKeepaliveInterval |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ExtraData", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncomingConcurrencyLimit", wireType)
 			}
-			var msglen int
+			m.IncomingConcurrencyLimit = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1804,120 +4207,31 @@ func (m *Hangup) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				m.IncomingConcurrencyLimit |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthStream
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthStream
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.ExtraData == nil {
-				m.ExtraData = make(map[string][]byte)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsAck", wireType)
 			}
-			var mapkey string
-			mapvalue := []byte{}
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowStream
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
 				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowStream
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthStream
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey < 0 {
-						return ErrInvalidLengthStream
-					}
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var mapbyteLen uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowStream
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						mapbyteLen |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intMapbyteLen := int(mapbyteLen)
-					if intMapbyteLen < 0 {
-						return ErrInvalidLengthStream
-					}
-					postbytesIndex := iNdEx + intMapbyteLen
-					if postbytesIndex < 0 {
-						return ErrInvalidLengthStream
-					}
-					if postbytesIndex > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = make([]byte, mapbyteLen)
-					copy(mapvalue, dAtA[iNdEx:postbytesIndex])
-					iNdEx = postbytesIndex
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipStream(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthStream
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
 			}
-			m.ExtraData[mapkey] = mapvalue
-			iNdEx = postIndex
+			m.IsAck = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStream(dAtA[iNdEx:])
@@ -26,24 +26,45 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 type EventType int32
 
 const (
-	EVENT_KEEPALIVE EventType = 0
-	EVENT_REQUEST   EventType = 1
-	EVENT_RESPONSE  EventType = 2
-	EVENT_HANGUP    EventType = 3
+	EVENT_KEEPALIVE      EventType = 0
+	EVENT_REQUEST        EventType = 1
+	EVENT_RESPONSE       EventType = 2
+	EVENT_HANGUP         EventType = 3
+	EVENT_STREAM_MESSAGE EventType = 4
+	EVENT_STREAM_END     EventType = 5
+	EVENT_CANCEL         EventType = 6
+	EVENT_GO_AWAY        EventType = 7
+	EVENT_NOTIFICATION   EventType = 8
+	EVENT_SETTINGS       EventType = 9
+	EVENT_WINDOW_UPDATE  EventType = 10
 )
 
 var EventType_name = map[int32]string{
-	0: "EVENT_KEEPALIVE",
-	1: "EVENT_REQUEST",
-	2: "EVENT_RESPONSE",
-	3: "EVENT_HANGUP",
+	0:  "EVENT_KEEPALIVE",
+	1:  "EVENT_REQUEST",
+	2:  "EVENT_RESPONSE",
+	3:  "EVENT_HANGUP",
+	4:  "EVENT_STREAM_MESSAGE",
+	5:  "EVENT_STREAM_END",
+	6:  "EVENT_CANCEL",
+	7:  "EVENT_GO_AWAY",
+	8:  "EVENT_NOTIFICATION",
+	9:  "EVENT_SETTINGS",
+	10: "EVENT_WINDOW_UPDATE",
 }
 
 var EventType_value = map[string]int32{
-	"EVENT_KEEPALIVE": 0,
-	"EVENT_REQUEST":   1,
-	"EVENT_RESPONSE":  2,
-	"EVENT_HANGUP":    3,
+	"EVENT_KEEPALIVE":      0,
+	"EVENT_REQUEST":        1,
+	"EVENT_RESPONSE":       2,
+	"EVENT_HANGUP":         3,
+	"EVENT_STREAM_MESSAGE": 4,
+	"EVENT_STREAM_END":     5,
+	"EVENT_CANCEL":         6,
+	"EVENT_GO_AWAY":        7,
+	"EVENT_NOTIFICATION":   8,
+	"EVENT_SETTINGS":       9,
+	"EVENT_WINDOW_UPDATE":  10,
 }
 
 func (x EventType) String() string {
@@ -55,9 +76,12 @@ func (EventType) EnumDescriptor() ([]byte, []int) {
 }
 
 type TransportHandshakeHeader struct {
-	Id                    UUID  `protobuf:"bytes,1,opt,name=id,proto3" json:"id"`
-	MaxIncomingPacketSize int32 `protobuf:"varint,2,opt,name=max_incoming_packet_size,json=maxIncomingPacketSize,proto3" json:"max_incoming_packet_size,omitempty"`
-	MaxOutgoingPacketSize int32 `protobuf:"varint,3,opt,name=max_outgoing_packet_size,json=maxOutgoingPacketSize,proto3" json:"max_outgoing_packet_size,omitempty"`
+	Id                        UUID     `protobuf:"bytes,1,opt,name=id,proto3" json:"id"`
+	MaxIncomingPacketSize     int32    `protobuf:"varint,2,opt,name=max_incoming_packet_size,json=maxIncomingPacketSize,proto3" json:"max_incoming_packet_size,omitempty"`
+	MaxOutgoingPacketSize     int32    `protobuf:"varint,3,opt,name=max_outgoing_packet_size,json=maxOutgoingPacketSize,proto3" json:"max_outgoing_packet_size,omitempty"`
+	IsPacketChecksumEnabled   bool     `protobuf:"varint,4,opt,name=is_packet_checksum_enabled,json=isPacketChecksumEnabled,proto3" json:"is_packet_checksum_enabled,omitempty"`
+	IsRecordEncryptionEnabled bool     `protobuf:"varint,5,opt,name=is_record_encryption_enabled,json=isRecordEncryptionEnabled,proto3" json:"is_record_encryption_enabled,omitempty"`
+	CompressionAlgorithms     []string `protobuf:"bytes,6,rep,name=compression_algorithms,json=compressionAlgorithms,proto3" json:"compression_algorithms,omitempty"`
 }
 
 func (m *TransportHandshakeHeader) Reset()         { *m = TransportHandshakeHeader{} }
@@ -114,8 +138,30 @@ func (m *TransportHandshakeHeader) GetMaxOutgoingPacketSize() int32 {
 	return 0
 }
 
+func (m *TransportHandshakeHeader) GetIsPacketChecksumEnabled() bool {
+	if m != nil {
+		return m.IsPacketChecksumEnabled
+	}
+	return false
+}
+
+func (m *TransportHandshakeHeader) GetIsRecordEncryptionEnabled() bool {
+	if m != nil {
+		return m.IsRecordEncryptionEnabled
+	}
+	return false
+}
+
+func (m *TransportHandshakeHeader) GetCompressionAlgorithms() []string {
+	if m != nil {
+		return m.CompressionAlgorithms
+	}
+	return nil
+}
+
 type PacketHeader struct {
-	EventType EventType `protobuf:"varint,1,opt,name=event_type,json=eventType,proto3,enum=gogorpc.proto.EventType" json:"event_type,omitempty"`
+	EventType    EventType `protobuf:"varint,1,opt,name=event_type,json=eventType,proto3,enum=gogorpc.proto.EventType" json:"event_type,omitempty"`
+	IsCompressed bool      `protobuf:"varint,2,opt,name=is_compressed,json=isCompressed,proto3" json:"is_compressed,omitempty"`
 }
 
 func (m *PacketHeader) Reset()         { *m = PacketHeader{} }
@@ -158,6 +204,13 @@ func (m *PacketHeader) GetEventType() EventType {
 	return EVENT_KEEPALIVE
 }
 
+func (m *PacketHeader) GetIsCompressed() bool {
+	if m != nil {
+		return m.IsCompressed
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterEnum("gogorpc.proto.EventType", EventType_name, EventType_value)
 	proto.RegisterType((*TransportHandshakeHeader)(nil), "gogorpc.proto.TransportHandshakeHeader")
@@ -169,32 +222,45 @@ func init() {
 }
 
 var fileDescriptor_e3ca473659fceec7 = []byte{
-	// 387 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0x4f, 0x8b, 0xd3, 0x40,
-	0x18, 0xc6, 0x33, 0x59, 0x15, 0x76, 0xdc, 0x5d, 0xe3, 0x2c, 0x42, 0xf0, 0x10, 0x97, 0x9e, 0x6a,
-	0xa1, 0x09, 0x54, 0xa4, 0xe0, 0xad, 0xc5, 0xa1, 0x29, 0x4a, 0x1b, 0xf3, 0xa7, 0xa0, 0x97, 0x30,
-	0x4d, 0xc6, 0x74, 0x68, 0x93, 0x09, 0xe9, 0x44, 0xda, 0x7e, 0x0a, 0xbf, 0x8d, 0x5f, 0xa1, 0xc7,
-	0x1e, 0x3d, 0x89, 0xb6, 0x5f, 0x44, 0x9a, 0x3f, 0x55, 0x7b, 0x72, 0x4f, 0x79, 0x9f, 0xe7, 0x79,
-	0x7f, 0x6f, 0x1e, 0x18, 0xf8, 0x26, 0x62, 0x62, 0x96, 0x4f, 0xf5, 0x80, 0xc7, 0xc6, 0x82, 0x8a,
-	0xf6, 0xa6, 0x1d, 0x71, 0x23, 0xe2, 0x11, 0xcf, 0xd2, 0xc0, 0x60, 0x89, 0xa0, 0x59, 0x42, 0x16,
-	0x46, 0x9a, 0x71, 0xc1, 0x0d, 0x91, 0x91, 0x64, 0x99, 0xf2, 0x4c, 0xe8, 0x85, 0x46, 0xd7, 0xd5,
-	0x5e, 0x29, 0x9f, 0xb7, 0xff, 0x3a, 0x75, 0x4c, 0x4a, 0x6a, 0x9a, 0x7f, 0x2e, 0x54, 0x79, 0xe2,
-	0x38, 0x55, 0xeb, 0xaf, 0xef, 0xf1, 0xe7, 0x3c, 0x67, 0x61, 0x89, 0x35, 0xbe, 0x01, 0xa8, 0xba,
-	0x75, 0x11, 0x93, 0x24, 0xe1, 0x72, 0x46, 0xe6, 0xd4, 0xa4, 0x24, 0xa4, 0x19, 0x7a, 0x09, 0x65,
-	0x16, 0xaa, 0xe0, 0x0e, 0x34, 0x1f, 0x77, 0x6e, 0xf5, 0x7f, 0xea, 0xe9, 0x9e, 0x37, 0x7c, 0xdb,
-	0x7f, 0xb0, 0xfd, 0xf1, 0x42, 0xb2, 0x65, 0x16, 0xa2, 0x2e, 0x54, 0x63, 0xb2, 0xf2, 0x59, 0x12,
-	0xf0, 0x98, 0x25, 0x91, 0x9f, 0x92, 0x60, 0x4e, 0x85, 0xbf, 0x64, 0x1b, 0xaa, 0xca, 0x77, 0xa0,
-	0xf9, 0xd0, 0x7e, 0x16, 0x93, 0xd5, 0xb0, 0x8a, 0xad, 0x22, 0x75, 0xd8, 0x86, 0xd6, 0x20, 0xcf,
-	0x45, 0xc4, 0xcf, 0xc1, 0x8b, 0x13, 0x38, 0xae, 0xe2, 0x3f, 0x60, 0x63, 0x00, 0xaf, 0x4a, 0x55,
-	0x95, 0xed, 0x42, 0x48, 0xbf, 0xd0, 0x44, 0xf8, 0x62, 0x9d, 0xd2, 0xa2, 0xf4, 0x4d, 0x47, 0x3d,
-	0x2b, 0x8d, 0x8f, 0x0b, 0xee, 0x3a, 0xa5, 0xf6, 0x25, 0xad, 0xc7, 0xd6, 0x47, 0x78, 0x79, 0xf2,
-	0xd1, 0x2d, 0x7c, 0x82, 0x27, 0x78, 0xe4, 0xfa, 0xef, 0x30, 0xb6, 0x7a, 0xef, 0x87, 0x13, 0xac,
-	0x48, 0xe8, 0x29, 0xbc, 0x2e, 0x4d, 0x1b, 0x7f, 0xf0, 0xb0, 0xe3, 0x2a, 0x00, 0x21, 0x78, 0x53,
-	0x5b, 0x8e, 0x35, 0x1e, 0x39, 0x58, 0x91, 0x91, 0x02, 0xaf, 0x4a, 0xcf, 0xec, 0x8d, 0x06, 0x9e,
-	0xa5, 0x5c, 0xf4, 0xcd, 0xdd, 0x2f, 0x4d, 0xda, 0xee, 0x35, 0xb0, 0xdb, 0x6b, 0xe0, 0xe7, 0x5e,
-	0x03, 0x5f, 0x0f, 0x9a, 0xb4, 0x3b, 0x68, 0xd2, 0xf7, 0x83, 0x26, 0x7d, 0x6a, 0xfd, 0xff, 0x93,
-	0x4d, 0x1f, 0x15, 0x9f, 0x57, 0xbf, 0x03, 0x00, 0x00, 0xff, 0xff, 0x0e, 0x78, 0xd5, 0x68, 0x61,
-	0x02, 0x00, 0x00,
+	// 594 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0x86, 0xe3, 0xf4, 0x42, 0x33, 0xb4, 0x65, 0x98, 0xde, 0x4c, 0x85, 0x4c, 0x54, 0x36, 0xa1,
+	0x52, 0x13, 0xa9, 0xa8, 0xaa, 0x04, 0x0b, 0xe4, 0x26, 0x43, 0x62, 0xd1, 0x3a, 0xc1, 0x76, 0x5a,
+	0xc1, 0xc6, 0x72, 0xec, 0xc1, 0x19, 0x35, 0xf6, 0x58, 0x9e, 0x09, 0x6a, 0xfb, 0x14, 0x3c, 0x56,
+	0x97, 0x5d, 0xb2, 0x42, 0xd0, 0x6e, 0xd9, 0xf1, 0x02, 0x28, 0x9e, 0xd8, 0xbd, 0xac, 0x60, 0xe5,
+	0x39, 0xff, 0xff, 0x7f, 0xe7, 0x1c, 0x1d, 0xc9, 0xe0, 0x4d, 0x48, 0xc5, 0x70, 0x3c, 0xa8, 0xfb,
+	0x2c, 0x6a, 0x8c, 0x88, 0xd8, 0xb9, 0xd8, 0x09, 0x59, 0x23, 0x64, 0x21, 0x4b, 0x13, 0xbf, 0x41,
+	0x63, 0x41, 0xd2, 0xd8, 0x1b, 0x35, 0x92, 0x94, 0x09, 0xd6, 0x10, 0xa9, 0x17, 0xf3, 0x84, 0xa5,
+	0xa2, 0x9e, 0xd5, 0x68, 0x69, 0x9a, 0x93, 0xe5, 0xe6, 0xce, 0x9d, 0x56, 0x13, 0x47, 0x52, 0x83,
+	0xf1, 0x97, 0xac, 0x92, 0x2d, 0x26, 0xaf, 0x69, 0x7c, 0xef, 0x3f, 0x26, 0x8f, 0xc7, 0x34, 0x90,
+	0xd8, 0xd6, 0xef, 0x32, 0x50, 0x9d, 0x7c, 0x91, 0x8e, 0x17, 0x07, 0x7c, 0xe8, 0x9d, 0x92, 0x0e,
+	0xf1, 0x02, 0x92, 0xa2, 0x57, 0xa0, 0x4c, 0x03, 0x55, 0xa9, 0x2a, 0xb5, 0xc7, 0xbb, 0x2b, 0xf5,
+	0x7b, 0xeb, 0xd5, 0xfb, 0x7d, 0xa3, 0x75, 0x30, 0x7b, 0xf9, 0xe3, 0x45, 0xc9, 0x2a, 0xd3, 0x00,
+	0xed, 0x03, 0x35, 0xf2, 0xce, 0x5c, 0x1a, 0xfb, 0x2c, 0xa2, 0x71, 0xe8, 0x26, 0x9e, 0x7f, 0x4a,
+	0x84, 0xcb, 0xe9, 0x05, 0x51, 0xcb, 0x55, 0xa5, 0x36, 0x67, 0xad, 0x45, 0xde, 0x99, 0x31, 0xb5,
+	0x7b, 0x99, 0x6b, 0xd3, 0x0b, 0x92, 0x83, 0x6c, 0x2c, 0x42, 0xf6, 0x10, 0x9c, 0x29, 0xc0, 0xee,
+	0xd4, 0xbe, 0x03, 0xbe, 0x05, 0x9b, 0x94, 0xe7, 0x71, 0x7f, 0x48, 0xfc, 0x53, 0x3e, 0x8e, 0x5c,
+	0x12, 0x7b, 0x83, 0x11, 0x09, 0xd4, 0xd9, 0xaa, 0x52, 0x5b, 0xb0, 0x36, 0x28, 0x97, 0x44, 0x73,
+	0xea, 0x63, 0x69, 0xa3, 0x77, 0xe0, 0x39, 0xe5, 0x6e, 0x4a, 0x7c, 0x96, 0x06, 0x2e, 0x89, 0xfd,
+	0xf4, 0x3c, 0x11, 0x94, 0xc5, 0x05, 0x3e, 0x97, 0xe1, 0xcf, 0x28, 0xb7, 0xb2, 0x08, 0x2e, 0x12,
+	0x79, 0x83, 0x3d, 0xb0, 0xee, 0xb3, 0x28, 0x49, 0x09, 0xe7, 0x13, 0xce, 0x1b, 0x85, 0x2c, 0xa5,
+	0x62, 0x18, 0x71, 0x75, 0xbe, 0x3a, 0x53, 0xab, 0x58, 0x6b, 0x77, 0x5c, 0xbd, 0x30, 0xb7, 0x46,
+	0x60, 0x51, 0x2e, 0x34, 0xbd, 0xf0, 0x3e, 0x00, 0xe4, 0x2b, 0x89, 0x85, 0x2b, 0xce, 0x13, 0x92,
+	0x5d, 0x7a, 0x79, 0x57, 0x7d, 0x70, 0x69, 0x3c, 0x09, 0x38, 0xe7, 0x09, 0xb1, 0x2a, 0x24, 0x7f,
+	0xa2, 0x97, 0x60, 0x89, 0x72, 0x37, 0x1f, 0x42, 0x82, 0xec, 0xc8, 0x0b, 0xd6, 0x22, 0xe5, 0xcd,
+	0x42, 0xdb, 0xfe, 0xa3, 0x80, 0x4a, 0x41, 0xa3, 0x15, 0xf0, 0x04, 0x1f, 0x63, 0xd3, 0x71, 0x3f,
+	0x60, 0xdc, 0xd3, 0x0f, 0x8d, 0x63, 0x0c, 0x4b, 0xe8, 0x29, 0x58, 0x92, 0xa2, 0x85, 0x3f, 0xf6,
+	0xb1, 0xed, 0x40, 0x05, 0x21, 0xb0, 0x9c, 0x4b, 0x76, 0xaf, 0x6b, 0xda, 0x18, 0x96, 0x11, 0x04,
+	0x8b, 0x52, 0xeb, 0xe8, 0x66, 0xbb, 0xdf, 0x83, 0x33, 0x48, 0x05, 0xab, 0x52, 0xb1, 0x1d, 0x0b,
+	0xeb, 0x47, 0xee, 0x11, 0xb6, 0x6d, 0xbd, 0x8d, 0xe1, 0x2c, 0x5a, 0x05, 0xf0, 0x9e, 0x83, 0xcd,
+	0x16, 0x9c, 0xbb, 0xed, 0xd0, 0xd4, 0xcd, 0x26, 0x3e, 0x84, 0xf3, 0xb7, 0xa3, 0xdb, 0x5d, 0x57,
+	0x3f, 0xd1, 0x3f, 0xc1, 0x47, 0x68, 0x1d, 0x20, 0x29, 0x99, 0x5d, 0xc7, 0x78, 0x6f, 0x34, 0x75,
+	0xc7, 0xe8, 0x9a, 0x70, 0xe1, 0x76, 0x25, 0x1b, 0x3b, 0x8e, 0x61, 0xb6, 0x6d, 0x58, 0x41, 0x1b,
+	0x60, 0x45, 0x6a, 0x27, 0x86, 0xd9, 0xea, 0x9e, 0xb8, 0xfd, 0x5e, 0x4b, 0x77, 0x30, 0x04, 0x07,
+	0x9d, 0xab, 0x5f, 0x5a, 0xe9, 0xf2, 0x5a, 0x53, 0xae, 0xae, 0x35, 0xe5, 0xe7, 0xb5, 0xa6, 0x7c,
+	0xbb, 0xd1, 0x4a, 0x57, 0x37, 0x5a, 0xe9, 0xfb, 0x8d, 0x56, 0xfa, 0xbc, 0xfd, 0xef, 0xff, 0xc9,
+	0x60, 0x3e, 0xfb, 0xbc, 0xfe, 0x1b, 0x00, 0x00, 0xff, 0xff, 0xd0, 0x4a, 0x03, 0xb2, 0xd6, 0x03,
+	0x00, 0x00,
 }
 
 func (m *TransportHandshakeHeader) Marshal() (dAtA []byte, err error) {
@@ -217,6 +283,35 @@ func (m *TransportHandshakeHeader) MarshalToSizedBuffer(dAtA []byte) (int, error
 	_ = i
 	var l int
 	_ = l
+	if len(m.CompressionAlgorithms) > 0 {
+		for iNdEx := len(m.CompressionAlgorithms) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.CompressionAlgorithms[iNdEx])
+			copy(dAtA[i:], m.CompressionAlgorithms[iNdEx])
+			i = encodeVarintTransport(dAtA, i, uint64(len(m.CompressionAlgorithms[iNdEx])))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if m.IsRecordEncryptionEnabled {
+		i--
+		if m.IsRecordEncryptionEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.IsPacketChecksumEnabled {
+		i--
+		if m.IsPacketChecksumEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.MaxOutgoingPacketSize != 0 {
 		i = encodeVarintTransport(dAtA, i, uint64(m.MaxOutgoingPacketSize))
 		i--
@@ -260,6 +355,16 @@ func (m *PacketHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.IsCompressed {
+		i--
+		if m.IsCompressed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
 	if m.EventType != 0 {
 		i = encodeVarintTransport(dAtA, i, uint64(m.EventType))
 		i--
@@ -293,6 +398,18 @@ func (m *TransportHandshakeHeader) Size() (n int) {
 	if m.MaxOutgoingPacketSize != 0 {
 		n += 1 + sovTransport(uint64(m.MaxOutgoingPacketSize))
 	}
+	if m.IsPacketChecksumEnabled {
+		n += 2
+	}
+	if m.IsRecordEncryptionEnabled {
+		n += 2
+	}
+	if len(m.CompressionAlgorithms) > 0 {
+		for _, s := range m.CompressionAlgorithms {
+			l = len(s)
+			n += 1 + l + sovTransport(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -305,6 +422,9 @@ func (m *PacketHeader) Size() (n int) {
 	if m.EventType != 0 {
 		n += 1 + sovTransport(uint64(m.EventType))
 	}
+	if m.IsCompressed {
+		n += 2
+	}
 	return n
 }
 
@@ -414,6 +534,78 @@ func (m *TransportHandshakeHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsPacketChecksumEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTransport
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsPacketChecksumEnabled = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsRecordEncryptionEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTransport
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsRecordEncryptionEnabled = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompressionAlgorithms", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTransport
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTransport
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTransport
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CompressionAlgorithms = append(m.CompressionAlgorithms, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTransport(dAtA[iNdEx:])
@@ -486,6 +678,26 @@ func (m *PacketHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsCompressed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTransport
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsCompressed = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTransport(dAtA[iNdEx:])
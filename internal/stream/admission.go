@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/let-z-go/intrusive"
+	"github.com/let-z-go/toolkit/deque"
+)
+
+// DequeOfPendingRequests is a deque of pending requests which admits blocked senders
+// by priority: once the deque is full, the highest-priority sender (the earliest one
+// among equals) takes the next free slot.
+type DequeOfPendingRequests struct {
+	deque.Deque
+
+	admissionQueue admissionQueue
+}
+
+func (dopr *DequeOfPendingRequests) Init(capacity int) *DequeOfPendingRequests {
+	dopr.Deque.Init(capacity)
+	return dopr
+}
+
+func (dopr *DequeOfPendingRequests) AppendNode(ctx context.Context, listNode *intrusive.ListNode, priority int32) error {
+	admission := admission{Priority: priority}
+
+	for {
+		if err := dopr.admissionQueue.WaitForTurn(ctx, &admission); err != nil {
+			return err
+		}
+
+		// only the sender holding the turn blocks on the deque, a higher-priority
+		// sender preempts it by cancelling ctx2.
+		ctx2, cancel := context.WithCancel(ctx)
+		dopr.admissionQueue.BeginTurn(&admission, cancel)
+		err := dopr.Deque.AppendNode(ctx2, listNode)
+		cancel()
+
+		if dopr.admissionQueue.EndTurn(&admission, err != nil && ctx.Err() == nil) {
+			continue
+		}
+
+		return err
+	}
+}
+
+type admissionQueue struct {
+	mutex      sync.Mutex
+	admissions admissionHeap
+	turnHolder *admission
+	nextOrder  uint64
+}
+
+func (aq *admissionQueue) WaitForTurn(ctx context.Context, admission_ *admission) error {
+	aq.mutex.Lock()
+
+	if admission_.order == 0 {
+		aq.nextOrder++
+		admission_.order = aq.nextOrder
+	}
+
+	if aq.turnHolder == nil && len(aq.admissions) == 0 {
+		aq.turnHolder = admission_
+		aq.mutex.Unlock()
+		return nil
+	}
+
+	if admission_.turn == nil {
+		admission_.turn = make(chan struct{}, 1)
+	}
+
+	heap.Push(&aq.admissions, admission_)
+
+	if turnHolder := aq.turnHolder; turnHolder != nil && turnHolder.Priority < admission_.Priority {
+		turnHolder.preempt()
+	}
+
+	aq.mutex.Unlock()
+
+	select {
+	case <-admission_.turn:
+		return nil
+	case <-ctx.Done():
+		aq.mutex.Lock()
+
+		if admission_.index >= 0 {
+			heap.Remove(&aq.admissions, admission_.index)
+			aq.mutex.Unlock()
+			return ctx.Err()
+		}
+
+		aq.mutex.Unlock()
+		// the turn has been given meanwhile, pass it on.
+		<-admission_.turn
+		aq.EndTurn(admission_, false)
+		return ctx.Err()
+	}
+}
+
+func (aq *admissionQueue) BeginTurn(admission_ *admission, cancel context.CancelFunc) {
+	aq.mutex.Lock()
+	admission_.cancel = cancel
+
+	if len(aq.admissions) >= 1 && aq.admissions[0].Priority > admission_.Priority {
+		admission_.preempt()
+	}
+
+	aq.mutex.Unlock()
+}
+
+func (aq *admissionQueue) EndTurn(admission_ *admission, isFailed bool) bool {
+	aq.mutex.Lock()
+	aq.turnHolder = nil
+	admission_.cancel = nil
+	isPreempted := isFailed && admission_.isPreempted
+	admission_.isPreempted = false
+
+	if len(aq.admissions) >= 1 {
+		turnHolder := heap.Pop(&aq.admissions).(*admission)
+		aq.turnHolder = turnHolder
+		turnHolder.turn <- struct{}{}
+	}
+
+	aq.mutex.Unlock()
+	return isPreempted
+}
+
+type admission struct {
+	Priority int32
+
+	order       uint64
+	index       int
+	turn        chan struct{}
+	cancel      context.CancelFunc
+	isPreempted bool
+}
+
+func (a *admission) preempt() {
+	if a.cancel != nil && !a.isPreempted {
+		a.isPreempted = true
+		a.cancel()
+	}
+}
+
+type admissionHeap []*admission
+
+var _ = heap.Interface((*admissionHeap)(nil))
+
+func (ah admissionHeap) Len() int {
+	return len(ah)
+}
+
+func (ah admissionHeap) Less(i, j int) bool {
+	if ah[i].Priority == ah[j].Priority {
+		return ah[i].order < ah[j].order
+	}
+
+	return ah[i].Priority > ah[j].Priority
+}
+
+func (ah admissionHeap) Swap(i, j int) {
+	ah[i], ah[j] = ah[j], ah[i]
+	ah[i].index = i
+	ah[j].index = j
+}
+
+func (ah *admissionHeap) Push(x interface{}) {
+	admission_ := x.(*admission)
+	admission_.index = len(*ah)
+	*ah = append(*ah, admission_)
+}
+
+func (ah *admissionHeap) Pop() interface{} {
+	n := len(*ah) - 1
+	admission_ := (*ah)[n]
+	(*ah)[n] = nil
+	*ah = (*ah)[:n]
+	admission_.index = -1
+	return admission_
+}
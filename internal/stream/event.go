@@ -24,19 +24,37 @@ const (
 	EventResponse  = proto2.EVENT_RESPONSE
 	EventHangup    = proto2.EVENT_HANGUP
 
-	NumberOfEventTypes = 4
+	EventStreamMessage = proto2.EVENT_STREAM_MESSAGE
+	EventStreamEnd     = proto2.EVENT_STREAM_END
+	EventCancel        = proto2.EVENT_CANCEL
+	EventGoAway        = proto2.EVENT_GO_AWAY
+	EventNotification  = proto2.EVENT_NOTIFICATION
+	EventSettings      = proto2.EVENT_SETTINGS
+	EventWindowUpdate  = proto2.EVENT_WINDOW_UPDATE
+
+	NumberOfEventTypes = 11
 )
 
 type Event struct {
-	RequestHeader  proto2.RequestHeader
-	ResponseHeader proto2.ResponseHeader
-	Message        Message
-	Hangup         proto2.Hangup
-	Err            error
-
-	stream    *Stream
-	direction EventDirection
-	type_     EventType
+	KeepaliveHeader     proto2.KeepaliveHeader
+	RequestHeader       proto2.RequestHeader
+	ResponseHeader      proto2.ResponseHeader
+	StreamMessageHeader proto2.StreamMessageHeader
+	StreamEndHeader     proto2.StreamEndHeader
+	CancelHeader        proto2.CancelHeader
+	NotificationHeader  proto2.NotificationHeader
+	Message             Message
+	Hangup              proto2.Hangup
+	GoAway              proto2.GoAway
+	Settings            proto2.Settings
+	WindowUpdate        proto2.WindowUpdate
+	Err                 error
+
+	stream              *Stream
+	direction           EventDirection
+	type_               EventType
+	windowCost          int
+	isWindowCreditTaken bool
 }
 
 func (e *Event) Stream() RestrictedStream {
@@ -51,6 +69,15 @@ func (e *Event) Type() EventType {
 	return e.type_
 }
 
+// TakeWindowCredit takes over the window credit of an incoming message, which by default
+// is given back to the peer as soon as the event has been handled. The taker gives it back
+// with ReturnWindowCredit once the message has been consumed, so the peer can't send
+// more than what is consumed plus the window.
+func (e *Event) TakeWindowCredit() int {
+	e.isWindowCreditTaken = true
+	return e.windowCost
+}
+
 type RestrictedStream struct {
 	underlying *Stream
 }
@@ -63,6 +90,26 @@ func (rs RestrictedStream) SendResponse(responseHeader *proto2.ResponseHeader, r
 	return rs.underlying.SendResponse(responseHeader, response)
 }
 
+func (rs RestrictedStream) SendStreamMessage(ctx context.Context, streamMessageHeader *proto2.StreamMessageHeader, streamMessage Message) error {
+	return rs.underlying.SendStreamMessage(ctx, streamMessageHeader, streamMessage)
+}
+
+func (rs RestrictedStream) SendStreamEnd(streamEndHeader *proto2.StreamEndHeader) error {
+	return rs.underlying.SendStreamEnd(streamEndHeader)
+}
+
+func (rs RestrictedStream) SendCancel(cancelHeader *proto2.CancelHeader) error {
+	return rs.underlying.SendCancel(cancelHeader)
+}
+
+func (rs RestrictedStream) SendNotification(ctx context.Context, notificationHeader *proto2.NotificationHeader, notification Message) error {
+	return rs.underlying.SendNotification(ctx, notificationHeader, notification)
+}
+
+func (rs RestrictedStream) ReturnWindowCredit(windowCredit int) {
+	rs.underlying.ReturnWindowCredit(windowCredit)
+}
+
 func (rs RestrictedStream) Abort(extraData ExtraData) {
 	rs.underlying.Abort(extraData)
 }
@@ -103,6 +150,9 @@ type Message interface {
 
 type EventFilter func(event *Event)
 
+// RawMessage is written out without being copied, so its bytes are referenced until the
+// packet carrying it is flushed and must not be modified once sent. Requests and
+// notifications complete only after that.
 type RawMessage []byte
 
 var _ = Message((*RawMessage)(nil))
@@ -121,6 +171,53 @@ func (rm RawMessage) MarshalTo(buffer []byte) (int, error) {
 	return copy(buffer, rm), nil
 }
 
+// BorrowedMessage refers to its payload in the transport input buffer instead of
+// copying it out. The payload stays valid until Release is called. Like RawMessage,
+// once sent it is referenced until the packet carrying it is flushed, so it must not
+// be released before then.
+type BorrowedMessage struct {
+	Payload []byte
+
+	unpin func()
+}
+
+var _ = Message((*BorrowedMessage)(nil))
+
+func (bm *BorrowedMessage) Unmarshal(data []byte) error {
+	// not coming from the input buffer, so it has to be copied after all.
+	bm.Payload = make([]byte, len(data))
+	copy(bm.Payload, data)
+	return nil
+}
+
+func (bm *BorrowedMessage) Size() int {
+	return len(bm.Payload)
+}
+
+func (bm *BorrowedMessage) MarshalTo(buffer []byte) (int, error) {
+	return copy(buffer, bm.Payload), nil
+}
+
+func (bm *BorrowedMessage) Release() {
+	if bm.unpin != nil {
+		bm.unpin()
+		bm.unpin = nil
+	}
+
+	bm.Payload = nil
+}
+
+func (bm *BorrowedMessage) borrow(payload []byte, unpin func()) {
+	bm.Payload = payload
+	bm.unpin = unpin
+}
+
+func releaseMessage(message Message) {
+	if borrowedMessage, ok := message.(*BorrowedMessage); ok {
+		borrowedMessage.Release()
+	}
+}
+
 var ErrEventDropped = errors.New("gogorpc/stream: event dropped")
 
 var NullMessage nullMessage
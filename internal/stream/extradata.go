@@ -2,10 +2,30 @@ package stream
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 )
 
 type ExtraData map[string][]byte
 
+const RetryAfterKey = "retry_after"
+
+func GetRetryAfter(extraData ExtraDataRef) (time.Duration, bool) {
+	value, ok := extraData.TryGet(RetryAfterKey)
+
+	if !ok {
+		return 0, false
+	}
+
+	milliseconds, err := strconv.ParseInt(string(value), 10, 64)
+
+	if err != nil || milliseconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(milliseconds) * time.Millisecond, true
+}
+
 func (ed ExtraData) TryGet(key string) ([]byte, bool) {
 	value, ok := ed[key]
 	return value, ok
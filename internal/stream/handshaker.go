@@ -75,6 +75,8 @@ func (th *transportHandshaker) HandleHandshake(ctx context.Context, rawHandshake
 		Int32("outgoing_keepalive_interval", th.handshakeHeader.OutgoingKeepaliveInterval).
 		Int32("incoming_concurrency_limit", th.handshakeHeader.IncomingConcurrencyLimit).
 		Int32("outgoing_concurrency_limit", th.handshakeHeader.OutgoingConcurrencyLimit).
+		Bool("is_keepalive_header_enabled", th.handshakeHeader.IsKeepaliveHeaderEnabled).
+		Bool("is_method_id_enabled", th.handshakeHeader.IsMethodIdEnabled).
 		Msg("stream_incoming_handshake")
 	handshakePayload := th.Underlying.NewHandshake()
 
@@ -117,15 +119,35 @@ func (th *transportHandshaker) HandleHandshake(ctx context.Context, rawHandshake
 			th.handshakeHeader.OutgoingConcurrencyLimit = int32(th.stream.options.IncomingConcurrencyLimit)
 		}
 
+		if int(th.handshakeHeader.IncomingWindowSize) < minWindowSize {
+			th.handshakeHeader.IncomingWindowSize = minWindowSize
+		} else if int(th.handshakeHeader.IncomingWindowSize) > th.stream.options.OutgoingWindowSize {
+			th.handshakeHeader.IncomingWindowSize = int32(th.stream.options.OutgoingWindowSize)
+		}
+
+		if int(th.handshakeHeader.OutgoingWindowSize) < minWindowSize {
+			th.handshakeHeader.OutgoingWindowSize = minWindowSize
+		} else if int(th.handshakeHeader.OutgoingWindowSize) > th.stream.options.IncomingWindowSize {
+			th.handshakeHeader.OutgoingWindowSize = int32(th.stream.options.IncomingWindowSize)
+		}
+
 		th.stream.incomingKeepaliveInterval = time.Duration(th.handshakeHeader.OutgoingKeepaliveInterval) * time.Millisecond
 		th.stream.outgoingKeepaliveInterval = time.Duration(th.handshakeHeader.IncomingKeepaliveInterval) * time.Millisecond
 		th.stream.incomingConcurrencyLimit = int(th.handshakeHeader.OutgoingConcurrencyLimit)
 		th.stream.outgoingConcurrencyLimit = int(th.handshakeHeader.IncomingConcurrencyLimit)
+		th.stream.incomingWindowSize = int(th.handshakeHeader.OutgoingWindowSize)
+		th.stream.outgoingWindowSize = int(th.handshakeHeader.IncomingWindowSize)
+		th.stream.isKeepaliveHeaderEnabled = th.handshakeHeader.IsKeepaliveHeaderEnabled
+		th.stream.isMethodIDEnabled = th.handshakeHeader.IsMethodIdEnabled
 	} else {
 		th.stream.incomingKeepaliveInterval = time.Duration(th.handshakeHeader.IncomingKeepaliveInterval) * time.Millisecond
 		th.stream.outgoingKeepaliveInterval = time.Duration(th.handshakeHeader.OutgoingKeepaliveInterval) * time.Millisecond
 		th.stream.incomingConcurrencyLimit = int(th.handshakeHeader.IncomingConcurrencyLimit)
 		th.stream.outgoingConcurrencyLimit = int(th.handshakeHeader.OutgoingConcurrencyLimit)
+		th.stream.incomingWindowSize = int(th.handshakeHeader.IncomingWindowSize)
+		th.stream.outgoingWindowSize = int(th.handshakeHeader.OutgoingWindowSize)
+		th.stream.isKeepaliveHeaderEnabled = th.handshakeHeader.IsKeepaliveHeaderEnabled
+		th.stream.isMethodIDEnabled = th.handshakeHeader.IsMethodIdEnabled
 	}
 
 	return ok, nil
@@ -138,6 +160,10 @@ func (th *transportHandshaker) SizeHandshake() int {
 			OutgoingKeepaliveInterval: int32(th.stream.options.OutgoingKeepaliveInterval / time.Millisecond),
 			IncomingConcurrencyLimit:  int32(th.stream.options.IncomingConcurrencyLimit),
 			OutgoingConcurrencyLimit:  int32(th.stream.options.OutgoingConcurrencyLimit),
+			IncomingWindowSize:        int32(th.stream.options.IncomingWindowSize),
+			OutgoingWindowSize:        int32(th.stream.options.OutgoingWindowSize),
+			IsKeepaliveHeaderEnabled:  true,
+			IsMethodIdEnabled:         true,
 		}
 	}
 
@@ -171,6 +197,8 @@ func (th *transportHandshaker) EmitHandshake(buffer []byte) error {
 		Int32("outgoing_keepalive_interval", th.handshakeHeader.OutgoingKeepaliveInterval).
 		Int32("incoming_concurrency_limit", th.handshakeHeader.IncomingConcurrencyLimit).
 		Int32("outgoing_concurrency_limit", th.handshakeHeader.OutgoingConcurrencyLimit).
+		Bool("is_keepalive_header_enabled", th.handshakeHeader.IsKeepaliveHeaderEnabled).
+		Bool("is_method_id_enabled", th.handshakeHeader.IsMethodIdEnabled).
 		Msg("stream_outgoing_handshake")
 	binary.BigEndian.PutUint32(buffer, uint32(th.handshakeHeaderSize))
 	th.handshakeHeader.MarshalTo(buffer[4:])
@@ -2,6 +2,7 @@ package stream
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/let-z-go/gogorpc/internal/proto"
 )
@@ -12,12 +13,15 @@ const (
 	HangupTooManyIncomingRequests = proto.HANGUP_TOO_MANY_INCOMING_REQUESTS
 	HangupOutgoingPacketTooLarge  = proto.HANGUP_OUTGOING_PACKET_TOO_LARGE
 	HangupSystem                  = proto.HANGUP_SYSTEM
+	HangupDrained                 = proto.HANGUP_DRAINED
+	HangupBadIncomingChecksum     = proto.HANGUP_BAD_INCOMING_CHECKSUM
 )
 
 type Hangup struct {
 	IsPassive bool
 	Code      HangupCode
 	ExtraData ExtraData
+	Reason    HangupReason
 }
 
 func (h *Hangup) Error() string {
@@ -40,11 +44,25 @@ func (h *Hangup) Error() string {
 		message += "outgoing packet too large"
 	case HangupSystem:
 		message += "system"
+	case HangupDrained:
+		message += "drained"
+	case HangupBadIncomingChecksum:
+		message += "bad incoming checksum"
 	default:
 		message += fmt.Sprintf("hangup %d", h.Code)
 	}
 
+	if h.Reason.Message != "" {
+		message += ": " + h.Reason.Message
+	}
+
 	return message
 }
 
+type HangupReason struct {
+	Message           string
+	RetryAfter        time.Duration
+	RedirectServerURL string
+}
+
 type HangupCode = proto.HangupCode
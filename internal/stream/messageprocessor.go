@@ -29,6 +29,20 @@ type MessageFactory interface {
 	//   event.Message
 	//   event.Err
 	NewResponse(event *Event)
+
+	// Input:
+	//   event.StreamMessageHeader
+	// Output:
+	//   event.Message
+	//   event.Err
+	NewStreamMessage(event *Event)
+
+	// Input:
+	//   event.NotificationHeader
+	// Output:
+	//   event.Message
+	//   event.Err
+	NewNotification(event *Event)
 }
 
 type MessageHandler interface {
@@ -55,6 +69,40 @@ type MessageHandler interface {
 	// Output:
 	//   event.Err
 	HandleResponse(ctx context.Context, event *Event)
+
+	// Input:
+	//   event.StreamMessageHeader
+	//   event.Message
+	//   event.Err
+	// Output:
+	//   event.Err
+	HandleStreamMessage(ctx context.Context, event *Event)
+
+	// Input:
+	//   event.StreamEndHeader
+	//   event.Err
+	// Output:
+	//   event.Err
+	HandleStreamEnd(ctx context.Context, event *Event)
+
+	// Input:
+	//   event.CancelHeader
+	//   event.Err
+	// Output:
+	//   event.Err
+	HandleCancel(ctx context.Context, event *Event)
+
+	// Input:
+	//   event.NotificationHeader
+	//   event.Message
+	//   event.Err
+	// Output:
+	//   event.Err
+	HandleNotification(ctx context.Context, event *Event)
+
+	// Input:
+	//   event.GoAway
+	HandleGoAway(ctx context.Context, event *Event)
 }
 
 type MessageEmitter interface {
@@ -82,4 +130,24 @@ type MessageEmitter interface {
 	//   event.Message
 	//   event.Err
 	PostEmitResponse(event *Event)
+
+	// Input:
+	//   event.StreamMessageHeader
+	//   event.Message
+	//   event.Err
+	// Output:
+	//   event.StreamMessageHeader
+	//   event.Message
+	//   event.Err
+	PostEmitStreamMessage(event *Event)
+
+	// Input:
+	//   event.NotificationHeader
+	//   event.Message
+	//   event.Err
+	// Output:
+	//   event.NotificationHeader
+	//   event.Message
+	//   event.Err
+	PostEmitNotification(event *Event)
 }
@@ -0,0 +1,56 @@
+package stream
+
+type outgoingMethodTable struct {
+	methodIDs map[methodKey]int32
+}
+
+func (omt *outgoingMethodTable) Init() *outgoingMethodTable {
+	omt.methodIDs = map[methodKey]int32{}
+	return omt
+}
+
+func (omt *outgoingMethodTable) Lookup(serviceName string, methodName string) (int32, bool) {
+	if methodID, ok := omt.methodIDs[methodKey{serviceName, methodName}]; ok {
+		return methodID, true
+	}
+
+	if len(omt.methodIDs) >= maxNumberOfMethodIDs {
+		return 0, false
+	}
+
+	return int32(len(omt.methodIDs) + 1), false
+}
+
+func (omt *outgoingMethodTable) Add(serviceName string, methodName string, methodID int32) {
+	omt.methodIDs[methodKey{serviceName, methodName}] = methodID
+}
+
+type incomingMethodTable struct {
+	methodKeys []methodKey
+}
+
+func (imt *incomingMethodTable) Resolve(serviceName *string, methodName *string, methodID int32) bool {
+	if methodID == 0 {
+		return true
+	}
+
+	if i := int(methodID) - 1; i >= 0 && i < len(imt.methodKeys) {
+		*serviceName = imt.methodKeys[i].ServiceName
+		*methodName = imt.methodKeys[i].MethodName
+		return true
+	}
+
+	if int(methodID) != len(imt.methodKeys)+1 || methodID > maxNumberOfMethodIDs {
+		return false
+	}
+
+	imt.methodKeys = append(imt.methodKeys, methodKey{*serviceName, *methodName})
+	return true
+}
+
+type methodKey struct {
+	ServiceName string
+	MethodName  string
+}
+
+const maxNumberOfMethodIDs = 1 << 12
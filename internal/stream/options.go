@@ -12,14 +12,18 @@ import (
 )
 
 type Options struct {
-	Transport                 *transport.Options
-	Logger                    *zerolog.Logger
-	EventFilters              [1 + NumberOfEventDirections + NumberOfEventDirections*NumberOfEventTypes][]EventFilter
-	ActiveHangupTimeout       time.Duration
-	IncomingKeepaliveInterval time.Duration
-	OutgoingKeepaliveInterval time.Duration
-	IncomingConcurrencyLimit  int
-	OutgoingConcurrencyLimit  int
+	Transport                       *transport.Options
+	Logger                          *zerolog.Logger
+	EventFilters                    [1 + NumberOfEventDirections + NumberOfEventDirections*NumberOfEventTypes][]EventFilter
+	ActiveHangupTimeout             time.Duration
+	IncomingKeepaliveInterval       time.Duration
+	OutgoingKeepaliveInterval       time.Duration
+	IncomingConcurrencyLimit        int
+	OutgoingConcurrencyLimit        int
+	IncomingWindowSize              int
+	OutgoingWindowSize              int
+	ShedExcessIncomingRequests      bool
+	ExcessIncomingRequestRetryAfter time.Duration
 
 	normalizeOnce sync.Once
 }
@@ -41,6 +45,8 @@ func (o *Options) Normalize() *Options {
 		normalizeDurValue(&o.OutgoingKeepaliveInterval, defaultKeepaliveInterval, minKeepaliveInterval, maxKeepaliveInterval)
 		normalizeIntValue(&o.IncomingConcurrencyLimit, defaultConcurrencyLimit, minConcurrencyLimit, maxConcurrencyLimit)
 		normalizeIntValue(&o.OutgoingConcurrencyLimit, defaultConcurrencyLimit, minConcurrencyLimit, maxConcurrencyLimit)
+		normalizeIntValue(&o.IncomingWindowSize, defaultWindowSize, minWindowSize, maxWindowSize)
+		normalizeIntValue(&o.OutgoingWindowSize, defaultWindowSize, minWindowSize, maxWindowSize)
 	})
 
 	return o
@@ -122,6 +128,12 @@ const (
 	maxConcurrencyLimit     = 1 << 20
 )
 
+const (
+	defaultWindowSize = 1 << 24
+	minWindowSize     = 1 << 16
+	maxWindowSize     = 1 << 30
+)
+
 var defaultTransportOptions transport.Options
 
 func insertEventFilter(eventFilter EventFilter, eventFilters *[]EventFilter, i int) {
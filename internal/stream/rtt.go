@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/let-z-go/gogorpc/internal/proto"
+)
+
+type rttMeter struct {
+	epoch                  time.Time
+	mutex                  sync.Mutex
+	peerTimestamp          int64
+	peerTimestampReceiptAt int64
+	smoothedRTT            time.Duration
+	jitter                 time.Duration
+	isMeasured             bool
+	probes                 []chan time.Duration
+}
+
+func (rm *rttMeter) Init() *rttMeter {
+	rm.epoch = time.Now()
+	return rm
+}
+
+func (rm *rttMeter) AddProbe() <-chan time.Duration {
+	probe := make(chan time.Duration, 1)
+	rm.mutex.Lock()
+	rm.probes = append(rm.probes, probe)
+	rm.mutex.Unlock()
+	return probe
+}
+
+func (rm *rttMeter) DropProbes() {
+	rm.mutex.Lock()
+
+	for _, probe := range rm.probes {
+		close(probe)
+	}
+
+	rm.probes = nil
+	rm.mutex.Unlock()
+}
+
+func (rm *rttMeter) FillKeepaliveHeader(keepaliveHeader *proto.KeepaliveHeader) {
+	now := rm.now()
+	rm.mutex.Lock()
+	keepaliveHeader.Timestamp = now
+	keepaliveHeader.IsEchoRequested = len(rm.probes) >= 1
+
+	if rm.peerTimestamp == 0 {
+		keepaliveHeader.EchoedTimestamp = 0
+		keepaliveHeader.EchoDelay = 0
+	} else {
+		keepaliveHeader.EchoedTimestamp = rm.peerTimestamp
+		keepaliveHeader.EchoDelay = now - rm.peerTimestampReceiptAt
+		rm.peerTimestamp = 0
+	}
+
+	rm.mutex.Unlock()
+}
+
+func (rm *rttMeter) HandleKeepaliveHeader(keepaliveHeader *proto.KeepaliveHeader) bool {
+	now := rm.now()
+	rm.mutex.Lock()
+
+	if keepaliveHeader.Timestamp != 0 {
+		rm.peerTimestamp = keepaliveHeader.Timestamp
+		rm.peerTimestampReceiptAt = now
+	}
+
+	if keepaliveHeader.EchoedTimestamp != 0 {
+		rtt := time.Duration(now - keepaliveHeader.EchoedTimestamp - keepaliveHeader.EchoDelay)
+
+		if rtt < 0 {
+			rtt = 0
+		}
+
+		// As per RFC 6298: alpha = 1/8, beta = 1/4.
+		if rm.isMeasured {
+			deviation := rm.smoothedRTT - rtt
+
+			if deviation < 0 {
+				deviation = -deviation
+			}
+
+			rm.jitter = (3*rm.jitter + deviation) / 4
+			rm.smoothedRTT = (7*rm.smoothedRTT + rtt) / 8
+		} else {
+			rm.smoothedRTT = rtt
+			rm.jitter = rtt / 2
+			rm.isMeasured = true
+		}
+
+		for _, probe := range rm.probes {
+			probe <- rtt
+		}
+
+		rm.probes = nil
+	}
+
+	rm.mutex.Unlock()
+	return keepaliveHeader.IsEchoRequested
+}
+
+func (rm *rttMeter) SmoothedRTT() time.Duration {
+	rm.mutex.Lock()
+	smoothedRTT := rm.smoothedRTT
+	rm.mutex.Unlock()
+	return smoothedRTT
+}
+
+func (rm *rttMeter) Jitter() time.Duration {
+	rm.mutex.Lock()
+	jitter := rm.jitter
+	rm.mutex.Unlock()
+	return jitter
+}
+
+func (rm *rttMeter) now() int64 {
+	// timestamps only ever come back to their sender, so a monotonic clock is enough;
+	// zero is reserved for no timestamp.
+	return int64(time.Since(rm.epoch)) + 1
+}
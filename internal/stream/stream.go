@@ -5,6 +5,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"net"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,14 +25,43 @@ type Stream struct {
 	options                   *Options
 	transport                 transport.Transport
 	userData                  interface{}
-	dequeOfPendingRequests    *deque.Deque
+	dequeOfPendingRequests    *DequeOfPendingRequests
 	dequeOfPendingResponses   deque.Deque
+	pendingResponsesClosure   sync.Once
+	unflushedResponses        []*pendingResponse
+	withdrawalMutex           sync.Mutex
+	messageEmitter            MessageEmitter
+	withdrawnRequests         []*pendingRequest
 	isHungUp_                 int32
 	pendingHangup             chan *Hangup
+	isDraining_               int32
+	isPeerGoingAway_          int32
+	goAwayMutex               sync.Mutex
+	isGoingAway               bool
+	lastSequenceNumber        int32
+	pendingGoAway             chan struct{}
+	settingsMutex             sync.Mutex
+	settings                  *proto.Settings
+	settingsAck               *proto.Settings
+	pendingSettings           chan struct{}
+	rttMeter                  rttMeter
+	isKeepaliveHeaderEnabled  bool
+	isMethodIDEnabled         bool
+	sortedPendingRequests     []*pendingRequest
+	pendingKeepalive          chan struct{}
+	outgoingWindow            outgoingWindow
+	pendingWindowIncrement    int64
+	deferredRequestWindowCost int64
+	pendingWindowUpdate       chan struct{}
+	outgoingMethodTable       outgoingMethodTable
+	incomingMethodTable       incomingMethodTable
+	closure                   chan struct{}
 	incomingKeepaliveInterval time.Duration
 	outgoingKeepaliveInterval time.Duration
 	incomingConcurrencyLimit  int
 	outgoingConcurrencyLimit  int
+	incomingWindowSize        int
+	outgoingWindowSize        int
 	incomingConcurrency       int32
 }
 
@@ -39,7 +70,7 @@ func (s *Stream) Init(
 	isServerSide bool,
 	transportID uuid.UUID,
 	userData interface{},
-	dequeOfPendingRequests *deque.Deque,
+	dequeOfPendingRequests *DequeOfPendingRequests,
 ) *Stream {
 	s.options = options.Normalize()
 	s.transport.Init(s.options.Transport, isServerSide, transportID)
@@ -47,14 +78,30 @@ func (s *Stream) Init(
 	s.dequeOfPendingRequests = dequeOfPendingRequests
 	s.dequeOfPendingResponses.Init(0)
 	s.pendingHangup = make(chan *Hangup, 1)
+	s.lastSequenceNumber = -1
+	s.pendingGoAway = make(chan struct{}, 1)
+	s.pendingSettings = make(chan struct{}, 1)
+	s.pendingKeepalive = make(chan struct{}, 1)
+	s.rttMeter.Init()
+	s.outgoingWindow.Init()
+	s.pendingWindowUpdate = make(chan struct{}, 1)
+	s.outgoingMethodTable.Init()
+	s.closure = make(chan struct{})
 	return s
 }
 
 func (s *Stream) Close() error {
 	err := s.transport.Close()
-	listOfPendingResponses := deque.NewList()
-	s.dequeOfPendingResponses.Close(listOfPendingResponses)
-	putPooledPendingResponses(listOfPendingResponses)
+	s.closePendingResponses()
+	s.withdrawalMutex.Lock()
+
+	for _, pendingRequest_ := range s.withdrawnRequests {
+		releasePendingRequest(pendingRequest_)
+	}
+
+	s.withdrawnRequests = nil
+	s.withdrawalMutex.Unlock()
+	close(s.closure)
 	return err
 }
 
@@ -73,6 +120,12 @@ func (s *Stream) Process(
 	trafficCrypter transport.TrafficCrypter,
 	messageProcessor MessageProcessor,
 ) error {
+	defer s.transport.ReleaseBuffers()
+	s.withdrawalMutex.Lock()
+	s.messageEmitter = messageProcessor
+	s.postWithdrawnRequests()
+	s.withdrawalMutex.Unlock()
+
 	if err := s.prepare(trafficCrypter, messageProcessor); err != nil {
 		return err
 	}
@@ -104,17 +157,24 @@ func (s *Stream) Process(
 	cancel()
 	err := <-errs
 	<-errs
+	// nothing is sent any more, so don't keep senders waiting for flushes until the stream is closed.
+	s.closePendingResponses()
 	return err
 }
 
 func (s *Stream) SendRequest(ctx context.Context, requestHeader *proto.RequestHeader, request Message) error {
 	pendingRequest_ := pendingRequestPool.Get().(*pendingRequest)
+	pendingRequest_.Ctx = ctx
 	pendingRequest_.Header = *requestHeader
 	pendingRequest_.Underlying = request
 
+	if ctx.Done() != nil {
+		pendingRequest_.StopWithdrawal = context.AfterFunc(ctx, s.withdrawPendingRequests)
+	}
+
 	// dequeOfPendingRequests.length += 1
-	if err := s.dequeOfPendingRequests.AppendNode(ctx, &pendingRequest_.ListNode); err != nil {
-		pendingRequestPool.Put(pendingRequest_)
+	if err := s.dequeOfPendingRequests.AppendNode(ctx, &pendingRequest_.ListNode, requestHeader.Priority); err != nil {
+		releasePendingRequest(pendingRequest_)
 
 		switch err {
 		case deque.ErrDequeClosed:
@@ -128,30 +188,144 @@ func (s *Stream) SendRequest(ctx context.Context, requestHeader *proto.RequestHe
 }
 
 func (s *Stream) SendResponse(responseHeader *proto.ResponseHeader, response Message) error {
+	responseSize := response.Size()
+	windowCost := messageWindowCost(responseSize)
+
+	// empty responses are never held back, so that they can be sent while receiving.
+	if responseSize == 0 {
+		s.outgoingWindow.ForceAcquire(windowCost)
+	} else if err := s.outgoingWindow.Acquire(context.Background(), s.closure, windowCost); err != nil {
+		return err
+	}
+
 	pendingResponse_ := pendingResponsePool.Get().(*pendingResponse)
+	pendingResponse_.EventType = EventResponse
 	pendingResponse_.Header = *responseHeader
 	pendingResponse_.Underlying = response
+	pendingResponse_.WindowCost = windowCost
+	return s.putPendingResponse(pendingResponse_)
+}
 
-	// dequeOfPendingResponses.length += 1
-	// dequeOfPendingResponses.capacity += 1
-	if err := s.dequeOfPendingResponses.DiscardNodeRemoval(&pendingResponse_.ListNode, false); err != nil {
-		pendingResponsePool.Put(pendingResponse_)
+func (s *Stream) SendStreamMessage(ctx context.Context, streamMessageHeader *proto.StreamMessageHeader, streamMessage Message) error {
+	windowCost := messageWindowCost(streamMessage.Size())
 
-		switch err {
-		case deque.ErrDequeClosed:
-			return ErrClosed
+	if err := s.outgoingWindow.Acquire(ctx, s.closure, windowCost); err != nil {
+		return err
+	}
+
+	pendingResponse_ := pendingResponsePool.Get().(*pendingResponse)
+	pendingResponse_.EventType = EventStreamMessage
+	pendingResponse_.StreamMessageHeader = *streamMessageHeader
+	pendingResponse_.Underlying = streamMessage
+	pendingResponse_.WindowCost = windowCost
+	return s.putPendingResponse(pendingResponse_)
+}
+
+func (s *Stream) SendStreamEnd(streamEndHeader *proto.StreamEndHeader) error {
+	pendingResponse_ := pendingResponsePool.Get().(*pendingResponse)
+	pendingResponse_.EventType = EventStreamEnd
+	pendingResponse_.StreamEndHeader = *streamEndHeader
+	pendingResponse_.Underlying = nil
+	pendingResponse_.WindowCost = 0
+	return s.putPendingResponse(pendingResponse_)
+}
+
+func (s *Stream) SendCancel(cancelHeader *proto.CancelHeader) error {
+	pendingResponse_ := pendingResponsePool.Get().(*pendingResponse)
+	pendingResponse_.EventType = EventCancel
+	pendingResponse_.CancelHeader = *cancelHeader
+	pendingResponse_.Underlying = nil
+	pendingResponse_.WindowCost = 0
+	return s.putPendingResponse(pendingResponse_)
+}
+
+func (s *Stream) SendNotification(ctx context.Context, notificationHeader *proto.NotificationHeader, notification Message) error {
+	windowCost := messageWindowCost(notification.Size())
+
+	if err := s.outgoingWindow.Acquire(ctx, s.closure, windowCost); err != nil {
+		return err
+	}
+
+	pendingResponse_ := pendingResponsePool.Get().(*pendingResponse)
+	pendingResponse_.EventType = EventNotification
+	pendingResponse_.NotificationHeader = *notificationHeader
+	pendingResponse_.Underlying = notification
+	pendingResponse_.WindowCost = windowCost
+	flush := make(chan error, 1)
+	pendingResponse_.Flush = flush
+
+	if err := s.putPendingResponse(pendingResponse_); err != nil {
+		return err
+	}
+
+	// the notification is referenced until flushed.
+	return <-flush
+}
+
+func (s *Stream) ReturnWindowCredit(windowCredit int) {
+	if windowCredit >= 1 {
+		atomic.AddInt64(&s.pendingWindowIncrement, int64(windowCredit))
+
+		select {
+		case s.pendingWindowUpdate <- struct{}{}:
 		default:
-			return err
 		}
 	}
-
-	return nil
 }
 
 func (s *Stream) Abort(extraData ExtraData) {
 	s.hangUp(HangupAborted, extraData)
 }
 
+func (s *Stream) AbortWithReason(extraData ExtraData, reason HangupReason) {
+	s.hangUpWithReason(HangupAborted, extraData, reason)
+}
+
+func (s *Stream) Drain() {
+	atomic.StoreInt32(&s.isDraining_, 1)
+	s.goAway()
+}
+
+func (s *Stream) UpdateSettings(incomingKeepaliveInterval time.Duration, incomingConcurrencyLimit int) {
+	normalizeDurValue(&incomingKeepaliveInterval, s.options.IncomingKeepaliveInterval, minKeepaliveInterval, maxKeepaliveInterval)
+	normalizeIntValue(&incomingConcurrencyLimit, s.options.IncomingConcurrencyLimit, minConcurrencyLimit, maxConcurrencyLimit)
+
+	s.putSettings(&proto.Settings{
+		IncomingKeepaliveInterval: int32(incomingKeepaliveInterval / time.Millisecond),
+		IncomingConcurrencyLimit:  int32(incomingConcurrencyLimit),
+	})
+}
+
+func (s *Stream) Ping(ctx context.Context) (time.Duration, error) {
+	probe := s.rttMeter.AddProbe()
+	s.keepAlive()
+
+	select {
+	case rtt, ok := <-probe:
+		if !ok {
+			return 0, ErrPingUnsupported
+		}
+
+		return rtt, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.closure:
+		return 0, ErrClosed
+	}
+}
+
+func (s *Stream) SmoothedRTT() time.Duration {
+	return s.rttMeter.SmoothedRTT()
+}
+
+func (s *Stream) RTTJitter() time.Duration {
+	return s.rttMeter.Jitter()
+}
+
+func (s *Stream) Closure() <-chan struct{} {
+	return s.closure
+}
+
 func (s *Stream) IsServerSide() bool {
 	return s.transport.IsServerSide()
 }
@@ -164,10 +338,32 @@ func (s *Stream) UserData() interface{} {
 	return s.userData
 }
 
+func (s *Stream) putPendingResponse(pendingResponse_ *pendingResponse) error {
+	// dequeOfPendingResponses.length += 1
+	// dequeOfPendingResponses.capacity += 1
+	if err := s.dequeOfPendingResponses.DiscardNodeRemoval(&pendingResponse_.ListNode, false); err != nil {
+		s.outgoingWindow.Release(pendingResponse_.WindowCost)
+		releasePendingResponse(pendingResponse_, ErrClosed)
+
+		switch err {
+		case deque.ErrDequeClosed:
+			return ErrClosed
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *Stream) prepare(trafficDecrypter transport.TrafficDecrypter, messageEmitter MessageEmitter) error {
 	s.transport.Prepare(trafficDecrypter)
+	s.outgoingWindow.Open(s.outgoingWindowSize)
+	return s.resizeDequeOfPendingRequests(s.outgoingConcurrencyLimit-s.dequeOfPendingRequests.Capacity(), messageEmitter)
+}
 
-	switch n := s.outgoingConcurrencyLimit - s.dequeOfPendingRequests.Capacity(); {
+func (s *Stream) resizeDequeOfPendingRequests(n int, messageEmitter MessageEmitter) error {
+	switch {
 	case n < 0:
 		listOfPendingRequests := deque.NewList()
 		// dequeOfPendingRequests.capacity += n
@@ -204,7 +400,7 @@ func (s *Stream) prepare(trafficDecrypter transport.TrafficDecrypter, messageEmi
 			listNode.Remove()
 			listOfPendingRequests.Length--
 			listNode.Reset()
-			pendingRequestPool.Put(pendingRequest_)
+			releasePendingRequest(pendingRequest_)
 		}
 
 		if err != nil {
@@ -221,6 +417,69 @@ func (s *Stream) prepare(trafficDecrypter transport.TrafficDecrypter, messageEmi
 	}
 }
 
+func (s *Stream) withdrawPendingRequests() {
+	s.withdrawalMutex.Lock()
+	defer s.withdrawalMutex.Unlock()
+	listOfPendingRequests := deque.NewList()
+
+	// dequeOfPendingRequests.length -= listOfPendingRequests.Length
+	// dequeOfPendingRequests.capacity -= listOfPendingRequests.Length
+	if s.dequeOfPendingRequests.RemoveNodes(context.Background(), false, listOfPendingRequests) != nil {
+		return
+	}
+
+	getListNode := listOfPendingRequests.Underlying.GetNodesSafely()
+	withdrawnRequestCount := 0
+
+	for listNode := getListNode(); listNode != nil; listNode = getListNode() {
+		pendingRequest_ := (*pendingRequest)(listNode.GetContainer(unsafe.Offsetof(pendingRequest{}.ListNode)))
+
+		if pendingRequest_.Ctx.Err() == nil {
+			continue
+		}
+
+		listNode.Remove()
+		listOfPendingRequests.Length--
+		listNode.Reset()
+		s.withdrawnRequests = append(s.withdrawnRequests, pendingRequest_)
+		withdrawnRequestCount++
+	}
+
+	// dequeOfPendingRequests.length += listOfPendingRequests.Length
+	// dequeOfPendingRequests.capacity += listOfPendingRequests.Length
+	if s.dequeOfPendingRequests.DiscardNodesRemoval(listOfPendingRequests, true) != nil {
+		PutPooledPendingRequests(listOfPendingRequests)
+	}
+
+	// the slots of the withdrawn requests are given back at once, even if no one is emitting requests.
+	// dequeOfPendingRequests.capacity += withdrawnRequestCount
+	s.dequeOfPendingRequests.CommitNodesRemoval(withdrawnRequestCount)
+	s.postWithdrawnRequests()
+}
+
+func (s *Stream) postWithdrawnRequests() {
+	if s.messageEmitter == nil {
+		return
+	}
+
+	event := Event{
+		stream:    s,
+		direction: EventOutgoing,
+		type_:     EventRequest,
+	}
+
+	for i, pendingRequest_ := range s.withdrawnRequests {
+		s.withdrawnRequests[i] = nil
+		event.RequestHeader = pendingRequest_.Header
+		event.Message = pendingRequest_.Underlying
+		event.Err = ErrRequestWithdrawn
+		s.messageEmitter.PostEmitRequest(&event)
+		releasePendingRequest(pendingRequest_)
+	}
+
+	s.withdrawnRequests = s.withdrawnRequests[:0]
+}
+
 func (s *Stream) receiveEvents(
 	ctx context.Context,
 	trafficDecrypter transport.TrafficDecrypter,
@@ -246,6 +505,8 @@ func (s *Stream) receiveEvents(
 		oldIncomingConcurrency := int(atomic.LoadInt32(&s.incomingConcurrency))
 		newIncomingConcurrency := oldIncomingConcurrency
 		handledResponseCount := 0
+		isPeerGoingAway := false
+		windowIncrement := 0
 
 		if err := s.handleEvent(
 			ctx,
@@ -253,6 +514,8 @@ func (s *Stream) receiveEvents(
 			messageHandler,
 			&newIncomingConcurrency,
 			&handledResponseCount,
+			&isPeerGoingAway,
+			&windowIncrement,
 		); err != nil {
 			return err
 		}
@@ -274,6 +537,8 @@ func (s *Stream) receiveEvents(
 				messageHandler,
 				&newIncomingConcurrency,
 				&handledResponseCount,
+				&isPeerGoingAway,
+				&windowIncrement,
 			); err != nil {
 				return err
 			}
@@ -282,6 +547,14 @@ func (s *Stream) receiveEvents(
 		atomic.AddInt32(&s.incomingConcurrency, int32(newIncomingConcurrency-oldIncomingConcurrency))
 		// dequeOfPendingRequests.capacity += handledResponseCount
 		s.dequeOfPendingRequests.CommitNodesRemoval(handledResponseCount)
+
+		s.ReturnWindowCredit(windowIncrement)
+
+		if isPeerGoingAway {
+			atomic.StoreInt32(&s.isPeerGoingAway_, 1)
+			s.goAway()
+			s.checkDrainage()
+		}
 	}
 }
 
@@ -295,7 +568,12 @@ func (s *Stream) peek(
 	var packet transport.Packet
 
 	if err := s.transport.Peek(ctx, timeout, trafficDecrypter, &packet); err != nil {
-		return err
+		if err != transport.ErrBadChecksum {
+			return err
+		}
+
+		event.Err = errBadChecksum
+		return nil
 	}
 
 	s.loadEvent(event, &packet, messageFactory)
@@ -307,7 +585,12 @@ func (s *Stream) peekNext(messageFactory MessageFactory, event *Event) (bool, er
 	ok, err := s.transport.PeekNext(&packet)
 
 	if err != nil {
-		return false, err
+		if err != transport.ErrBadChecksum {
+			return false, err
+		}
+
+		event.Err = errBadChecksum
+		return true, nil
 	}
 
 	if !ok {
@@ -320,15 +603,54 @@ func (s *Stream) peekNext(messageFactory MessageFactory, event *Event) (bool, er
 
 func (s *Stream) loadEvent(event *Event, packet *transport.Packet, messageFactory MessageFactory) {
 	event.type_ = packet.Header.EventType
+	event.windowCost = 0
+	// events without messages must not be left with the one of a previous event.
+	event.Message = nil
 
 	switch event.type_ {
 	case EventKeepalive:
+		rawEvent := packet.Payload
+		keepaliveHeader := &event.KeepaliveHeader
+		keepaliveHeader.Reset()
+
+		if !s.isKeepaliveHeaderEnabled {
+			event.Message = nil
+			event.Err = nil
+			messageFactory.NewKeepalive(event)
+
+			if event.Err == nil {
+				event.Err = event.Message.Unmarshal(rawEvent)
+			}
+
+			return
+		}
+
+		rawEventSize := len(rawEvent)
+
+		if rawEventSize < 4 {
+			event.Err = errBadEvent
+			return
+		}
+
+		keepaliveHeaderSize := int(int32(binary.BigEndian.Uint32(rawEvent)))
+		rawKeepaliveOffset := 4 + keepaliveHeaderSize
+
+		if rawKeepaliveOffset < 4 || rawKeepaliveOffset > rawEventSize {
+			event.Err = errBadEvent
+			return
+		}
+
+		if keepaliveHeader.Unmarshal(rawEvent[4:rawKeepaliveOffset]) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
 		event.Message = nil
 		event.Err = nil
 		messageFactory.NewKeepalive(event)
 
 		if event.Err == nil {
-			event.Err = event.Message.Unmarshal(packet.Payload)
+			event.Err = event.Message.Unmarshal(rawEvent[rawKeepaliveOffset:])
 		}
 	case EventRequest:
 		if s.isHungUp() {
@@ -360,12 +682,23 @@ func (s *Stream) loadEvent(event *Event, packet *transport.Packet, messageFactor
 			return
 		}
 
+		if !s.incomingMethodTable.Resolve(&requestHeader.ServiceName, &requestHeader.MethodName, requestHeader.MethodId) {
+			event.Err = errBadEvent
+			return
+		}
+
+		if requestHeader.Timeout >= 1 {
+			requestHeader.Deadline = time.Now().UnixNano() + requestHeader.Timeout
+		}
+
+		event.windowCost = messageWindowCost(rawEventSize - rawRequestOffset)
+
 		event.Message = nil
 		event.Err = nil
 		messageFactory.NewRequest(event)
 
 		if event.Err == nil {
-			event.Err = event.Message.Unmarshal(rawEvent[rawRequestOffset:])
+			event.Err = s.unmarshalMessage(event.Message, rawEvent[rawRequestOffset:])
 		}
 	case EventResponse:
 		rawEvent := packet.Payload
@@ -392,13 +725,117 @@ func (s *Stream) loadEvent(event *Event, packet *transport.Packet, messageFactor
 			return
 		}
 
+		event.windowCost = messageWindowCost(rawEventSize - rawResponseOffset)
+
 		event.Message = nil
 		event.Err = nil
 		messageFactory.NewResponse(event)
 
 		if event.Err == nil {
-			event.Err = event.Message.Unmarshal(rawEvent[rawResponseOffset:])
+			if responseHeader.IsRefused {
+				event.Err = ErrRequestRefused
+			} else {
+				event.Err = event.Message.Unmarshal(rawEvent[rawResponseOffset:])
+			}
 		}
+	case EventStreamMessage:
+		rawEvent := packet.Payload
+		rawEventSize := len(rawEvent)
+
+		if rawEventSize < 4 {
+			event.Err = errBadEvent
+			return
+		}
+
+		streamMessageHeaderSize := int(int32(binary.BigEndian.Uint32(rawEvent)))
+		rawStreamMessageOffset := 4 + streamMessageHeaderSize
+
+		if rawStreamMessageOffset < 4 || rawStreamMessageOffset > rawEventSize {
+			event.Err = errBadEvent
+			return
+		}
+
+		streamMessageHeader := &event.StreamMessageHeader
+		streamMessageHeader.Reset()
+
+		if streamMessageHeader.Unmarshal(rawEvent[4:rawStreamMessageOffset]) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.windowCost = messageWindowCost(rawEventSize - rawStreamMessageOffset)
+
+		event.Message = nil
+		event.Err = nil
+		messageFactory.NewStreamMessage(event)
+
+		if event.Err == nil {
+			event.Err = event.Message.Unmarshal(rawEvent[rawStreamMessageOffset:])
+		}
+	case EventNotification:
+		if s.isHungUp() {
+			event.Err = ErrEventDropped
+			return
+		}
+
+		rawEvent := packet.Payload
+		rawEventSize := len(rawEvent)
+
+		if rawEventSize < 4 {
+			event.Err = errBadEvent
+			return
+		}
+
+		notificationHeaderSize := int(int32(binary.BigEndian.Uint32(rawEvent)))
+		rawNotificationOffset := 4 + notificationHeaderSize
+
+		if rawNotificationOffset < 4 || rawNotificationOffset > rawEventSize {
+			event.Err = errBadEvent
+			return
+		}
+
+		notificationHeader := &event.NotificationHeader
+		notificationHeader.Reset()
+
+		if notificationHeader.Unmarshal(rawEvent[4:rawNotificationOffset]) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		if !s.incomingMethodTable.Resolve(&notificationHeader.ServiceName, &notificationHeader.MethodName, notificationHeader.MethodId) {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.windowCost = messageWindowCost(rawEventSize - rawNotificationOffset)
+
+		event.Message = nil
+		event.Err = nil
+		messageFactory.NewNotification(event)
+
+		if event.Err == nil {
+			event.Err = s.unmarshalMessage(event.Message, rawEvent[rawNotificationOffset:])
+		}
+	case EventStreamEnd:
+		streamEndHeader := &event.StreamEndHeader
+		streamEndHeader.Reset()
+
+		if streamEndHeader.Unmarshal(packet.Payload) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.Err = nil
+	case EventCancel:
+		cancelHeader := &event.CancelHeader
+		cancelHeader.Reset()
+
+		if cancelHeader.Unmarshal(packet.Payload) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.Err = nil
 	case EventHangup:
 		hangup := &event.Hangup
 		hangup.Reset()
@@ -408,6 +845,36 @@ func (s *Stream) loadEvent(event *Event, packet *transport.Packet, messageFactor
 			return
 		}
 
+		event.Err = nil
+	case EventGoAway:
+		goAway := &event.GoAway
+		goAway.Reset()
+
+		if goAway.Unmarshal(packet.Payload) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.Err = nil
+	case EventSettings:
+		settings := &event.Settings
+		settings.Reset()
+
+		if settings.Unmarshal(packet.Payload) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
+		event.Err = nil
+	case EventWindowUpdate:
+		windowUpdate := &event.WindowUpdate
+		windowUpdate.Reset()
+
+		if windowUpdate.Unmarshal(packet.Payload) != nil {
+			event.Err = errBadEvent
+			return
+		}
+
 		event.Err = nil
 	default:
 		event.Err = errBadEvent
@@ -420,71 +887,291 @@ func (s *Stream) handleEvent(
 	messageHandler MessageHandler,
 	incomingConcurrency *int,
 	handledResponseCount *int,
+	isPeerGoingAway *bool,
+	windowIncrement *int,
 ) error {
+	if event.Err == errBadChecksum {
+		s.hangUp(HangupBadIncomingChecksum, nil)
+		return nil
+	}
+
 	if event.Err == errBadEvent {
 		s.hangUp(HangupBadIncomingEvent, nil)
 		return nil
 	}
 
+	event.isWindowCreditTaken = false
+
 	if event.Err == nil {
 		s.filterEvent(event)
 	}
 
 	if event.Err == ErrEventDropped {
+		releaseMessage(event.Message)
+		*windowIncrement += event.windowCost
+
+		if event.type_ == EventResponse {
+			// the request slot is given back whether the response is wanted or not.
+			*handledResponseCount++
+		}
+
 		return nil
 	}
 
 	switch event.type_ {
 	case EventKeepalive:
+		if s.isKeepaliveHeaderEnabled && s.rttMeter.HandleKeepaliveHeader(&event.KeepaliveHeader) {
+			s.keepAlive()
+		}
+
 		messageHandler.HandleKeepalive(ctx, event)
 
 		if event.Err == nil {
 			s.transport.ShrinkInputBuffer()
 		}
 	case EventRequest:
-		if *incomingConcurrency == s.incomingConcurrencyLimit {
-			s.hangUp(HangupTooManyIncomingRequests, nil)
-			return nil
+		if *incomingConcurrency >= s.incomingConcurrencyLimit {
+			if !s.options.ShedExcessIncomingRequests {
+				releaseMessage(event.Message)
+				*windowIncrement += event.windowCost
+				s.hangUp(HangupTooManyIncomingRequests, nil)
+				return nil
+			}
+
+			s.shedRequest(event)
+		} else if s.acceptRequest(event.RequestHeader.SequenceNumber) {
+			messageHandler.HandleRequest(ctx, event)
+		} else {
+			s.refuseRequest(event)
 		}
 
-		messageHandler.HandleRequest(ctx, event)
 		*incomingConcurrency++
 	case EventResponse:
 		messageHandler.HandleResponse(ctx, event)
 		*handledResponseCount++
+	case EventStreamMessage:
+		messageHandler.HandleStreamMessage(ctx, event)
+	case EventStreamEnd:
+		messageHandler.HandleStreamEnd(ctx, event)
+	case EventCancel:
+		messageHandler.HandleCancel(ctx, event)
+	case EventNotification:
+		messageHandler.HandleNotification(ctx, event)
 	case EventHangup:
 		if event.Err == nil {
+			hangup := &event.Hangup
 			s.options.Logger.Info().Err(event.Err).
 				Str("transport_id", s.TransportID().String()).
+				Str("code", hangup.Code.String()).
+				Str("message", hangup.Message).
+				Int32("retry_after", hangup.RetryAfter).
+				Str("redirect_server_url", hangup.RedirectServerUrl).
 				Msg("stream_passive_hangup")
-			hangup := &event.Hangup
 
 			return &Hangup{
 				IsPassive: true,
 				Code:      hangup.Code,
 				ExtraData: hangup.ExtraData,
+
+				Reason: HangupReason{
+					Message:           hangup.Message,
+					RetryAfter:        time.Duration(hangup.RetryAfter) * time.Millisecond,
+					RedirectServerURL: hangup.RedirectServerUrl,
+				},
 			}
 		}
+	case EventSettings:
+		if event.Err == nil {
+			s.handleSettings(&event.Settings)
+		}
+	case EventWindowUpdate:
+		if event.Err == nil {
+			s.outgoingWindow.Release(int(event.WindowUpdate.Increment))
+		}
+	case EventGoAway:
+		if event.Err == nil {
+			s.options.Logger.Info().
+				Str("transport_id", s.TransportID().String()).
+				Int("last_sequence_number", int(event.GoAway.LastSequenceNumber)).
+				Msg("stream_peer_going_away")
+			*isPeerGoingAway = true
+			messageHandler.HandleGoAway(ctx, event)
+		}
 	default:
 		panic("unreachable code")
 	}
 
-	if event.Err != nil {
-		s.options.Logger.Error().Err(event.Err).
-			Str("transport_id", s.TransportID().String()).
-			Msg("stream_system_error")
-		s.hangUp(HangupSystem, nil)
+	if !event.isWindowCreditTaken {
+		*windowIncrement += event.windowCost
+	}
+
+	if event.Err != nil {
+		s.options.Logger.Error().Err(event.Err).
+			Str("transport_id", s.TransportID().String()).
+			Msg("stream_system_error")
+		s.hangUp(HangupSystem, nil)
+	}
+
+	return nil
+}
+
+func (s *Stream) acceptRequest(sequenceNumber int32) bool {
+	s.goAwayMutex.Lock()
+	defer s.goAwayMutex.Unlock()
+
+	if s.isGoingAway {
+		return false
+	}
+
+	// requests may be emitted out of order, the peer re-sends those after the greatest one accepted.
+	if sequenceNumber > s.lastSequenceNumber {
+		s.lastSequenceNumber = sequenceNumber
+	}
+
+	return true
+}
+
+func (s *Stream) refuseRequest(event *Event) {
+	releaseMessage(event.Message)
+	s.options.Logger.Info().
+		Str("transport_id", s.TransportID().String()).
+		Int("sequence_number", int(event.RequestHeader.SequenceNumber)).
+		Msg("stream_refused_request")
+
+	s.SendResponse(&proto.ResponseHeader{
+		SequenceNumber: event.RequestHeader.SequenceNumber,
+		IsRefused:      true,
+	}, NullMessage)
+}
+
+func (s *Stream) shedRequest(event *Event) {
+	releaseMessage(event.Message)
+	s.options.Logger.Warn().
+		Str("transport_id", s.TransportID().String()).
+		Int("sequence_number", int(event.RequestHeader.SequenceNumber)).
+		Msg("stream_shed_request")
+	var extraData ExtraData
+
+	if retryAfter := s.options.ExcessIncomingRequestRetryAfter; retryAfter >= 1 {
+		extraData.Set(RetryAfterKey, []byte(strconv.FormatInt(int64(retryAfter/time.Millisecond), 10)))
+	}
+
+	s.SendResponse(&proto.ResponseHeader{
+		SequenceNumber: event.RequestHeader.SequenceNumber,
+		ExtraData:      extraData,
+
+		RpcError: proto.RPCError{
+			Type: proto.RPC_ERROR_TOO_MANY_REQUESTS,
+			Code: "TooManyRequests",
+		},
+	}, NullMessage)
+}
+
+func (s *Stream) goAway() {
+	s.goAwayMutex.Lock()
+	defer s.goAwayMutex.Unlock()
+
+	if s.isGoingAway {
+		return
+	}
+
+	s.isGoingAway = true
+	s.pendingGoAway <- struct{}{}
+}
+
+func (s *Stream) keepAlive() {
+	select {
+	case s.pendingKeepalive <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Stream) handleSettings(settings *proto.Settings) {
+	s.options.Logger.Info().
+		Str("transport_id", s.TransportID().String()).
+		Int32("incoming_keepalive_interval", settings.IncomingKeepaliveInterval).
+		Int32("incoming_concurrency_limit", settings.IncomingConcurrencyLimit).
+		Bool("is_ack", settings.IsAck).
+		Msg("stream_incoming_settings")
+
+	if settings.IsAck {
+		s.incomingKeepaliveInterval = time.Duration(settings.IncomingKeepaliveInterval) * time.Millisecond
+
+		// the peer has applied the ack before sending it, so requests following the ack
+		// respect the new limit, whether it has been raised or lowered.
+		s.incomingConcurrencyLimit = int(settings.IncomingConcurrencyLimit)
+		return
+	}
+
+	settingsAck := *settings
+	settingsAck.IsAck = true
+	maxKeepaliveIntervalMs := int32(maxKeepaliveInterval / time.Millisecond)
+
+	if keepaliveIntervalMs := int32(s.options.OutgoingKeepaliveInterval / time.Millisecond);
+	/*   */ settingsAck.IncomingKeepaliveInterval < keepaliveIntervalMs {
+		settingsAck.IncomingKeepaliveInterval = keepaliveIntervalMs
+	} else if settingsAck.IncomingKeepaliveInterval > maxKeepaliveIntervalMs {
+		settingsAck.IncomingKeepaliveInterval = maxKeepaliveIntervalMs
+	}
+
+	if int(settingsAck.IncomingConcurrencyLimit) < minConcurrencyLimit {
+		settingsAck.IncomingConcurrencyLimit = minConcurrencyLimit
+	} else if int(settingsAck.IncomingConcurrencyLimit) > s.options.OutgoingConcurrencyLimit {
+		settingsAck.IncomingConcurrencyLimit = int32(s.options.OutgoingConcurrencyLimit)
+	}
+
+	s.putSettings(&settingsAck)
+}
+
+func (s *Stream) putSettings(settings *proto.Settings) {
+	s.settingsMutex.Lock()
+
+	if settings.IsAck {
+		s.settingsAck = settings
+	} else {
+		s.settings = settings
+	}
+
+	s.settingsMutex.Unlock()
+
+	select {
+	case s.pendingSettings <- struct{}{}:
+	default:
 	}
+}
 
-	return nil
+func (s *Stream) takeSettings() (*proto.Settings, *proto.Settings) {
+	s.settingsMutex.Lock()
+	settings, settingsAck := s.settings, s.settingsAck
+	s.settings, s.settingsAck = nil, nil
+	s.settingsMutex.Unlock()
+	return settings, settingsAck
+}
+
+func (s *Stream) applySettingsAck(settingsAck *proto.Settings, messageEmitter MessageEmitter) error {
+	s.outgoingKeepaliveInterval = time.Duration(settingsAck.IncomingKeepaliveInterval) * time.Millisecond
+	n := int(settingsAck.IncomingConcurrencyLimit) - s.outgoingConcurrencyLimit
+	s.outgoingConcurrencyLimit = int(settingsAck.IncomingConcurrencyLimit)
+	return s.resizeDequeOfPendingRequests(n, messageEmitter)
+}
+
+func (s *Stream) checkDrainage() {
+	if s.isDraining() && s.isPeerGoingAway() && atomic.LoadInt32(&s.incomingConcurrency) == 0 {
+		s.hangUp(HangupDrained, nil)
+	}
 }
 
 func (s *Stream) hangUp(hangupCode HangupCode, extraData ExtraData) {
+	s.hangUpWithReason(hangupCode, extraData, HangupReason{})
+}
+
+func (s *Stream) hangUpWithReason(hangupCode HangupCode, extraData ExtraData, reason HangupReason) {
 	if atomic.CompareAndSwapInt32(&s.isHungUp_, 0, 1) {
 		s.pendingHangup <- &Hangup{
 			IsPassive: false,
 			Code:      hangupCode,
 			ExtraData: extraData,
+			Reason:    reason,
 		}
 	}
 }
@@ -505,7 +1192,7 @@ func (s *Stream) sendEvents(
 	}
 
 	for {
-		listOfPendingRequests, listOfPendingResponses, pendingHangup, err := s.checkPendingMessages(
+		listOfPendingRequests, listOfPendingResponses, pendingGoAway, pendingSettings, pendingKeepalive, pendingWindowUpdate, pendingHangup, err := s.checkPendingMessages(
 			errs,
 			pendingRequests,
 			pendingResponses,
@@ -518,7 +1205,26 @@ func (s *Stream) sendEvents(
 			return err
 		}
 
-		err = s.emitEvents(listOfPendingRequests, listOfPendingResponses, pendingHangup, &event, messageEmitter)
+		// hold back writing while the memory budget is used up.
+		err = s.transport.BorrowOutputBuffer(ctx)
+
+		if err == nil {
+			err = s.emitEvents(
+				listOfPendingRequests,
+				listOfPendingResponses,
+				pendingGoAway,
+				pendingSettings,
+				pendingKeepalive,
+				pendingWindowUpdate,
+				pendingHangup,
+				&event,
+				messageEmitter,
+			)
+		}
+
+		if pendingGoAway {
+			pendingRequests = nil
+		}
 
 		if err != nil {
 			if listOfPendingRequests != nil {
@@ -530,11 +1236,16 @@ func (s *Stream) sendEvents(
 			if listOfPendingResponses != nil {
 				// dequeOfPendingResponses.length += listOfPendingResponses.Length
 				// dequeOfPendingResponses.capacity += listOfPendingResponses.Length
-				s.dequeOfPendingResponses.DiscardNodesRemoval(listOfPendingResponses, true)
+				if s.dequeOfPendingResponses.DiscardNodesRemoval(listOfPendingResponses, true) != nil {
+					releasePendingResponses(listOfPendingResponses, ErrClosed)
+				}
 			}
 		}
 
-		if err2 := s.flush(ctx, s.outgoingKeepaliveInterval*4/3, trafficEncrypter); err2 != nil {
+		err2 := s.flush(ctx, s.outgoingKeepaliveInterval*4/3, trafficEncrypter)
+		s.releaseUnflushedResponses(err2)
+
+		if err2 != nil {
 			err = err2
 		}
 
@@ -562,6 +1273,12 @@ func (s *Stream) putPendingRequests(ctx context.Context, pendingRequests chan *d
 	var lists [2]deque.List
 
 	for i := 0; ; i ^= 1 {
+		if windowCost := int(atomic.SwapInt64(&s.deferredRequestWindowCost, 0)); windowCost >= 1 {
+			if err := s.outgoingWindow.WaitFor(ctx, windowCost); err != nil {
+				return err
+			}
+		}
+
 		listOfPendingRequests := lists[i].Reset()
 		// dequeOfPendingRequests.length -= listOfPendingRequests.Length
 		// dequeOfPendingRequests.capacity -= listOfPendingRequests.Length
@@ -610,7 +1327,10 @@ func (s *Stream) putPendingResponses(ctx context.Context, pendingResponses chan
 		case <-ctx.Done():
 			// dequeOfPendingResponses.length += pendingResponses.Length
 			// dequeOfPendingResponses.capacity += pendingResponses.Length
-			s.dequeOfPendingResponses.DiscardNodesRemoval(listOfPendingResponses, true)
+			if s.dequeOfPendingResponses.DiscardNodesRemoval(listOfPendingResponses, true) != nil {
+				releasePendingResponses(listOfPendingResponses, ErrClosed)
+			}
+
 			return ctx.Err()
 		case pendingResponses <- listOfPendingResponses:
 		}
@@ -622,9 +1342,13 @@ func (s *Stream) checkPendingMessages(
 	pendingRequests chan *deque.List,
 	pendingResponses chan *deque.List,
 	timeout time.Duration,
-) (*deque.List, *deque.List, *Hangup, error) {
+) (*deque.List, *deque.List, bool, bool, bool, bool, *Hangup, error) {
 	var listOfPendingRequests *deque.List
 	var listOfPendingResponses *deque.List
+	pendingGoAway := false
+	pendingSettings := false
+	pendingKeepalive := false
+	pendingWindowUpdate := false
 	var pendingHangup *Hangup
 	n := 0
 
@@ -640,6 +1364,34 @@ func (s *Stream) checkPendingMessages(
 	default:
 	}
 
+	select {
+	case <-s.pendingGoAway:
+		pendingGoAway = true
+		n++
+	default:
+	}
+
+	select {
+	case <-s.pendingSettings:
+		pendingSettings = true
+		n++
+	default:
+	}
+
+	select {
+	case <-s.pendingKeepalive:
+		pendingKeepalive = true
+		n++
+	default:
+	}
+
+	select {
+	case <-s.pendingWindowUpdate:
+		pendingWindowUpdate = true
+		n++
+	default:
+	}
+
 	select {
 	case pendingHangup = <-s.pendingHangup:
 		n++
@@ -652,11 +1404,23 @@ func (s *Stream) checkPendingMessages(
 		select {
 		case err := <-errs:
 			timerpool.StopAndPutTimer(timer)
-			return nil, nil, nil, err
+			return nil, nil, false, false, false, false, nil, err
 		case listOfPendingRequests = <-pendingRequests:
 			timerpool.StopAndPutTimer(timer)
 		case listOfPendingResponses = <-pendingResponses:
 			timerpool.StopAndPutTimer(timer)
+		case <-s.pendingGoAway:
+			pendingGoAway = true
+			timerpool.StopAndPutTimer(timer)
+		case <-s.pendingSettings:
+			pendingSettings = true
+			timerpool.StopAndPutTimer(timer)
+		case <-s.pendingKeepalive:
+			pendingKeepalive = true
+			timerpool.StopAndPutTimer(timer)
+		case <-s.pendingWindowUpdate:
+			pendingWindowUpdate = true
+			timerpool.StopAndPutTimer(timer)
 		case pendingHangup = <-s.pendingHangup:
 			timerpool.StopAndPutTimer(timer)
 		case <-timer.C:
@@ -664,12 +1428,16 @@ func (s *Stream) checkPendingMessages(
 		}
 	}
 
-	return listOfPendingRequests, listOfPendingResponses, pendingHangup, nil
+	return listOfPendingRequests, listOfPendingResponses, pendingGoAway, pendingSettings, pendingKeepalive, pendingWindowUpdate, pendingHangup, nil
 }
 
 func (s *Stream) emitEvents(
 	listOfPendingRequests *deque.List,
 	listOfPendingResponses *deque.List,
+	pendingGoAway bool,
+	pendingSettings bool,
+	pendingKeepalive bool,
+	pendingWindowUpdate bool,
 	pendingHangup *Hangup,
 	event *Event,
 	messageEmitter MessageEmitter,
@@ -678,13 +1446,14 @@ func (s *Stream) emitEvents(
 	emittedEventCount := 0
 
 	if listOfPendingRequests != nil {
-		getListNode := listOfPendingRequests.Underlying.GetNodesSafely()
+		sortedPendingRequests := s.sortPendingRequests(listOfPendingRequests)
 		event.type_ = EventRequest
 		now := time.Now().UnixNano()
 		droppedRequestCount := 0
 
-		for listNode := getListNode(); listNode != nil; listNode = getListNode() {
-			pendingRequest_ := (*pendingRequest)(listNode.GetContainer(unsafe.Offsetof(pendingRequest{}.ListNode)))
+		for i, pendingRequest_ := range sortedPendingRequests {
+			sortedPendingRequests[i] = nil
+			listNode := &pendingRequest_.ListNode
 			event.RequestHeader = pendingRequest_.Header
 			event.Message = pendingRequest_.Underlying
 			var ok bool
@@ -698,7 +1467,28 @@ func (s *Stream) emitEvents(
 				if err2 == ErrEventDropped {
 					err2 = nil
 				}
+			} else if pendingRequest_.Ctx.Err() != nil {
+				event.Err = ErrRequestWithdrawn
+				messageEmitter.PostEmitRequest(event)
+				ok, err2 = false, event.Err
+
+				if err2 == ErrEventDropped {
+					err2 = nil
+				}
+			} else if windowCost := messageWindowCost(event.Message.Size()); !s.outgoingWindow.TryAcquire(windowCost) {
+				// out of window, this request and the ones after it wait for window updates.
+				for j := i + 1; j < len(sortedPendingRequests); j++ {
+					sortedPendingRequests[j] = nil
+				}
+
+				atomic.StoreInt64(&s.deferredRequestWindowCost, int64(windowCost))
+				break
 			} else {
+				if deadline := pendingRequest_.Header.Deadline; deadline != 0 {
+					event.RequestHeader.Timeout = deadline - now
+				}
+
+				event.windowCost = windowCost
 				ok, err2 = s.write(event, messageEmitter)
 			}
 
@@ -717,7 +1507,7 @@ func (s *Stream) emitEvents(
 			listNode.Remove()
 			listOfPendingRequests.Length--
 			listNode.Reset()
-			pendingRequestPool.Put(pendingRequest_)
+			releasePendingRequest(pendingRequest_)
 
 			if ok {
 				emittedEventCount++
@@ -728,17 +1518,37 @@ func (s *Stream) emitEvents(
 
 		// dequeOfPendingRequests.capacity += droppedRequestCount
 		s.dequeOfPendingRequests.CommitNodesRemoval(droppedRequestCount)
+
+		if err == nil && listOfPendingRequests.Length >= 1 {
+			// dequeOfPendingRequests.length += listOfPendingRequests.Length
+			// dequeOfPendingRequests.capacity += listOfPendingRequests.Length
+			s.dequeOfPendingRequests.DiscardNodesRemoval(listOfPendingRequests, true)
+		}
 	}
 
 	if listOfPendingResponses != nil {
 		getListNode := listOfPendingResponses.Underlying.GetNodesSafely()
-		event.type_ = EventResponse
 		emittedResponseCount := 0
 
 		for listNode := getListNode(); listNode != nil; listNode = getListNode() {
 			pendingResponse_ := (*pendingResponse)(listNode.GetContainer(unsafe.Offsetof(pendingResponse{}.ListNode)))
-			event.ResponseHeader = pendingResponse_.Header
+			event.type_ = pendingResponse_.EventType
+
+			switch event.type_ {
+			case EventResponse:
+				event.ResponseHeader = pendingResponse_.Header
+			case EventStreamMessage:
+				event.StreamMessageHeader = pendingResponse_.StreamMessageHeader
+			case EventStreamEnd:
+				event.StreamEndHeader = pendingResponse_.StreamEndHeader
+			case EventCancel:
+				event.CancelHeader = pendingResponse_.CancelHeader
+			case EventNotification:
+				event.NotificationHeader = pendingResponse_.NotificationHeader
+			}
+
 			event.Message = pendingResponse_.Underlying
+			event.windowCost = pendingResponse_.WindowCost
 			ok, err2 := s.write(event, messageEmitter)
 
 			if err2 != nil {
@@ -756,24 +1566,118 @@ func (s *Stream) emitEvents(
 			listNode.Remove()
 			listOfPendingResponses.Length--
 			listNode.Reset()
-			pendingResponsePool.Put(pendingResponse_)
+
+			if pendingResponse_.Flush != nil {
+				s.unflushedResponses = append(s.unflushedResponses, pendingResponse_)
+			} else {
+				releasePendingResponse(pendingResponse_, nil)
+			}
 
 			if ok {
 				emittedEventCount++
-				emittedResponseCount++
+
+				if event.type_ == EventResponse {
+					emittedResponseCount++
+				}
 			}
 		}
 
 		atomic.AddInt32(&s.incomingConcurrency, -int32(emittedResponseCount))
+		s.checkDrainage()
+	}
+
+	if pendingGoAway {
+		s.options.Logger.Info().
+			Str("transport_id", s.TransportID().String()).
+			Msg("stream_going_away")
+		event.type_ = EventGoAway
+		s.goAwayMutex.Lock()
+		event.GoAway.LastSequenceNumber = s.lastSequenceNumber
+		s.goAwayMutex.Unlock()
+
+		if ok, err2 := s.write(event, messageEmitter); err2 != nil {
+			if err == nil {
+				err = err2
+			} else {
+				s.options.Logger.Error().Err(err2).
+					Str("transport_id", s.TransportID().String()).
+					Msg("stream_system_error")
+			}
+		} else if ok {
+			emittedEventCount++
+		}
+	}
+
+	if pendingWindowUpdate {
+		if increment := atomic.SwapInt64(&s.pendingWindowIncrement, 0); increment >= 1 {
+			event.type_ = EventWindowUpdate
+			event.WindowUpdate.Increment = int32(increment)
+
+			if ok, err2 := s.write(event, messageEmitter); err2 != nil {
+				if err == nil {
+					err = err2
+				} else {
+					s.options.Logger.Error().Err(err2).
+						Str("transport_id", s.TransportID().String()).
+						Msg("stream_system_error")
+				}
+			} else if ok {
+				emittedEventCount++
+			}
+		}
+	}
+
+	if pendingSettings {
+		settings, settingsAck := s.takeSettings()
+
+		if settingsAck != nil {
+			if err2 := s.applySettingsAck(settingsAck, messageEmitter); err2 != nil {
+				if err == nil {
+					err = err2
+				} else {
+					s.options.Logger.Error().Err(err2).
+						Str("transport_id", s.TransportID().String()).
+						Msg("stream_system_error")
+				}
+			}
+		}
+
+		for _, settings2 := range [...]*proto.Settings{settingsAck, settings} {
+			if settings2 == nil {
+				continue
+			}
+
+			event.type_ = EventSettings
+			event.Settings = *settings2
+
+			if ok, err2 := s.write(event, messageEmitter); err2 != nil {
+				if err == nil {
+					err = err2
+				} else {
+					s.options.Logger.Error().Err(err2).
+						Str("transport_id", s.TransportID().String()).
+						Msg("stream_system_error")
+				}
+			} else if ok {
+				emittedEventCount++
+			}
+		}
 	}
 
 	if pendingHangup != nil {
 		s.options.Logger.Info().
 			Str("transport_id", s.TransportID().String()).
+			Str("code", pendingHangup.Code.String()).
+			Str("message", pendingHangup.Reason.Message).
+			Dur("retry_after", pendingHangup.Reason.RetryAfter).
+			Str("redirect_server_url", pendingHangup.Reason.RedirectServerURL).
 			Msg("stream_active_hangup")
 		event.type_ = EventHangup
 		event.Hangup.Code = pendingHangup.Code
 		event.Hangup.ExtraData = pendingHangup.ExtraData
+		event.Hangup.Message = pendingHangup.Reason.Message
+		event.Hangup.RetryAfter = int32(pendingHangup.Reason.RetryAfter / time.Millisecond)
+		event.Hangup.RedirectServerUrl = pendingHangup.Reason.RedirectServerURL
 
 		if _, err := s.write(event, messageEmitter); err != nil {
 			return err
@@ -782,7 +1686,7 @@ func (s *Stream) emitEvents(
 		return pendingHangup
 	}
 
-	if err == nil && emittedEventCount == 0 {
+	if err == nil && (emittedEventCount == 0 || pendingKeepalive) {
 		event.type_ = EventKeepalive
 		_, err = s.write(event, messageEmitter)
 	}
@@ -801,6 +1705,31 @@ func (s *Stream) emitEvents(
 	return nil
 }
 
+func (s *Stream) sortPendingRequests(listOfPendingRequests *deque.List) []*pendingRequest {
+	sortedPendingRequests := s.sortedPendingRequests[:0]
+	getListNode := listOfPendingRequests.Underlying.GetNodesSafely()
+	isSorted := true
+
+	for listNode := getListNode(); listNode != nil; listNode = getListNode() {
+		pendingRequest_ := (*pendingRequest)(listNode.GetContainer(unsafe.Offsetof(pendingRequest{}.ListNode)))
+
+		if n := len(sortedPendingRequests); n >= 1 && sortedPendingRequests[n-1].Header.Priority < pendingRequest_.Header.Priority {
+			isSorted = false
+		}
+
+		sortedPendingRequests = append(sortedPendingRequests, pendingRequest_)
+	}
+
+	if !isSorted {
+		sort.SliceStable(sortedPendingRequests, func(i, j int) bool {
+			return sortedPendingRequests[i].Header.Priority > sortedPendingRequests[j].Header.Priority
+		})
+	}
+
+	s.sortedPendingRequests = sortedPendingRequests
+	return sortedPendingRequests
+}
+
 func (s *Stream) write(event *Event, messageEmitter MessageEmitter) (bool, error) {
 	packet := transport.Packet{
 		Header: proto.PacketHeader{
@@ -816,15 +1745,36 @@ func (s *Stream) write(event *Event, messageEmitter MessageEmitter) (bool, error
 		messageEmitter.EmitKeepalive(event)
 
 		if event.Err == nil {
+			if s.isKeepaliveHeaderEnabled {
+				s.rttMeter.FillKeepaliveHeader(&event.KeepaliveHeader)
+			} else {
+				// the peer predates keepalive headers, so rtts can't be measured.
+				event.KeepaliveHeader.Reset()
+				s.rttMeter.DropProbes()
+			}
+
 			s.filterEvent(event)
 
 			if event.Err == nil {
-				packet.PayloadSize = event.Message.Size()
+				if s.isKeepaliveHeaderEnabled {
+					keepaliveHeader := &event.KeepaliveHeader
+					keepaliveHeaderSize := keepaliveHeader.Size()
+					packet.PayloadSize = 4 + keepaliveHeaderSize + event.Message.Size()
+
+					event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+						binary.BigEndian.PutUint32(buffer, uint32(keepaliveHeaderSize))
+						keepaliveHeader.MarshalTo(buffer[4:])
+						_, err := event.Message.MarshalTo(buffer[4+keepaliveHeaderSize:])
+						return err
+					})
+				} else {
+					packet.PayloadSize = event.Message.Size()
 
-				event.Err = s.transport.Write(&packet, func(buffer []byte) error {
-					_, err := event.Message.MarshalTo(buffer)
-					return err
-				})
+					event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+						_, err := event.Message.MarshalTo(buffer)
+						return err
+					})
+				}
 
 				if event.Err == nil {
 					s.transport.ShrinkOutputBuffer()
@@ -835,16 +1785,24 @@ func (s *Stream) write(event *Event, messageEmitter MessageEmitter) (bool, error
 		s.filterEvent(event)
 
 		if event.Err == nil {
-			requestHeader := &event.RequestHeader
-			requestHeaderSize := requestHeader.Size()
-			packet.PayloadSize = 4 + requestHeaderSize + event.Message.Size()
+			requestHeader := event.RequestHeader
+			methodID, methodIDIsKnown := s.lookUpMethodID(requestHeader.ServiceName, requestHeader.MethodName)
+			requestHeader.MethodId = methodID
 
-			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
-				binary.BigEndian.PutUint32(buffer, uint32(requestHeaderSize))
-				requestHeader.MarshalTo(buffer[4:])
-				_, err := event.Message.MarshalTo(buffer[4+requestHeaderSize:])
-				return err
-			})
+			if methodIDIsKnown {
+				requestHeader.ServiceName = ""
+				requestHeader.MethodName = ""
+			}
+
+			requestSize := event.Message.Size()
+			s.outgoingWindow.Release(event.windowCost - messageWindowCost(requestSize))
+			event.Err = s.writeMessage(&packet, &requestHeader, event.Message, requestSize)
+
+			if event.Err == nil && !methodIDIsKnown && methodID != 0 {
+				s.outgoingMethodTable.Add(event.RequestHeader.ServiceName, event.RequestHeader.MethodName, methodID)
+			}
+		} else {
+			s.outgoingWindow.Release(event.windowCost)
 		}
 
 		messageEmitter.PostEmitRequest(event)
@@ -852,19 +1810,111 @@ func (s *Stream) write(event *Event, messageEmitter MessageEmitter) (bool, error
 		s.filterEvent(event)
 
 		if event.Err == nil {
-			responseHeader := &event.ResponseHeader
-			responseHeaderSize := responseHeader.Size()
-			packet.PayloadSize = 4 + responseHeaderSize + event.Message.Size()
+			responseSize := event.Message.Size()
+			s.outgoingWindow.Release(event.windowCost - messageWindowCost(responseSize))
+			event.Err = s.writeMessage(&packet, &event.ResponseHeader, event.Message, responseSize)
+		} else {
+			s.outgoingWindow.Release(event.windowCost)
+		}
+
+		messageEmitter.PostEmitResponse(event)
+	case EventStreamMessage:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			streamMessageSize := event.Message.Size()
+			s.outgoingWindow.Release(event.windowCost - messageWindowCost(streamMessageSize))
+			event.Err = s.writeMessage(&packet, &event.StreamMessageHeader, event.Message, streamMessageSize)
+		} else {
+			s.outgoingWindow.Release(event.windowCost)
+		}
+
+		messageEmitter.PostEmitStreamMessage(event)
+	case EventNotification:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			notificationHeader := event.NotificationHeader
+			methodID, methodIDIsKnown := s.lookUpMethodID(notificationHeader.ServiceName, notificationHeader.MethodName)
+			notificationHeader.MethodId = methodID
+
+			if methodIDIsKnown {
+				notificationHeader.ServiceName = ""
+				notificationHeader.MethodName = ""
+			}
+
+			notificationSize := event.Message.Size()
+			s.outgoingWindow.Release(event.windowCost - messageWindowCost(notificationSize))
+			event.Err = s.writeMessage(&packet, &notificationHeader, event.Message, notificationSize)
+
+			if event.Err == nil && !methodIDIsKnown && methodID != 0 {
+				s.outgoingMethodTable.Add(event.NotificationHeader.ServiceName, event.NotificationHeader.MethodName, methodID)
+			}
+		} else {
+			s.outgoingWindow.Release(event.windowCost)
+		}
+
+		messageEmitter.PostEmitNotification(event)
+	case EventStreamEnd:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			streamEndHeader := &event.StreamEndHeader
+			packet.PayloadSize = streamEndHeader.Size()
 
 			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
-				binary.BigEndian.PutUint32(buffer, uint32(responseHeaderSize))
-				responseHeader.MarshalTo(buffer[4:])
-				_, err := event.Message.MarshalTo(buffer[4+responseHeaderSize:])
-				return err
+				streamEndHeader.MarshalTo(buffer)
+				return nil
 			})
 		}
+	case EventCancel:
+		s.filterEvent(event)
 
-		messageEmitter.PostEmitResponse(event)
+		if event.Err == nil {
+			cancelHeader := &event.CancelHeader
+			packet.PayloadSize = cancelHeader.Size()
+
+			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+				cancelHeader.MarshalTo(buffer)
+				return nil
+			})
+		}
+	case EventWindowUpdate:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			windowUpdate := &event.WindowUpdate
+			packet.PayloadSize = windowUpdate.Size()
+
+			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+				windowUpdate.MarshalTo(buffer)
+				return nil
+			})
+		}
+	case EventSettings:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			settings := &event.Settings
+			packet.PayloadSize = settings.Size()
+
+			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+				settings.MarshalTo(buffer)
+				return nil
+			})
+		}
+	case EventGoAway:
+		s.filterEvent(event)
+
+		if event.Err == nil {
+			goAway := &event.GoAway
+			packet.PayloadSize = goAway.Size()
+
+			event.Err = s.transport.Write(&packet, func(buffer []byte) error {
+				goAway.MarshalTo(buffer)
+				return nil
+			})
+		}
 	case EventHangup:
 		s.filterEvent(event)
 
@@ -892,10 +1942,72 @@ func (s *Stream) write(event *Event, messageEmitter MessageEmitter) (bool, error
 	return true, nil
 }
 
+func (s *Stream) lookUpMethodID(serviceName string, methodName string) (int32, bool) {
+	// a peer unaware of method ids always needs the names.
+	if !s.isMethodIDEnabled {
+		return 0, false
+	}
+
+	return s.outgoingMethodTable.Lookup(serviceName, methodName)
+}
+
+func (s *Stream) unmarshalMessage(message Message, rawMessage []byte) error {
+	if borrowedMessage, ok := message.(*BorrowedMessage); ok {
+		borrowedMessage.borrow(rawMessage, s.transport.PinInputBuffer())
+		return nil
+	}
+
+	return message.Unmarshal(rawMessage)
+}
+
+func (s *Stream) writeMessage(packet *transport.Packet, messageHeader messageHeader, message Message, messageSize int) error {
+	messageHeaderSize := messageHeader.Size()
+	packet.PayloadSize = 4 + messageHeaderSize
+
+	// raw messages are handed to the transport as they are, so that large ones go out without a copy.
+	switch message := message.(type) {
+	case *RawMessage:
+		packet.ExternalPayload = *message
+	case *BorrowedMessage:
+		packet.ExternalPayload = message.Payload
+	default:
+		packet.PayloadSize += messageSize
+	}
+
+	return s.transport.Write(packet, func(buffer []byte) error {
+		binary.BigEndian.PutUint32(buffer, uint32(messageHeaderSize))
+		messageHeader.MarshalTo(buffer[4:])
+
+		if packet.ExternalPayload != nil {
+			return nil
+		}
+
+		_, err := message.MarshalTo(buffer[4+messageHeaderSize:])
+		return err
+	})
+}
+
 func (s *Stream) flush(ctx context.Context, timeout time.Duration, trafficEncrypter transport.TrafficEncrypter) error {
 	return s.transport.Flush(ctx, timeout, trafficEncrypter)
 }
 
+func (s *Stream) releaseUnflushedResponses(err error) {
+	for i, pendingResponse_ := range s.unflushedResponses {
+		s.unflushedResponses[i] = nil
+		releasePendingResponse(pendingResponse_, err)
+	}
+
+	s.unflushedResponses = s.unflushedResponses[:0]
+}
+
+func (s *Stream) closePendingResponses() {
+	s.pendingResponsesClosure.Do(func() {
+		listOfPendingResponses := deque.NewList()
+		s.dequeOfPendingResponses.Close(listOfPendingResponses)
+		releasePendingResponses(listOfPendingResponses, ErrClosed)
+	})
+}
+
 func (s *Stream) filterEvent(event *Event) {
 	eventFilters := s.options.DoGetEventFilters(event.direction, event.type_)
 
@@ -912,10 +2024,21 @@ func (s *Stream) isHungUp() bool {
 	return atomic.LoadInt32(&s.isHungUp_) == 1
 }
 
+func (s *Stream) isDraining() bool {
+	return atomic.LoadInt32(&s.isDraining_) == 1
+}
+
+func (s *Stream) isPeerGoingAway() bool {
+	return atomic.LoadInt32(&s.isPeerGoingAway_) == 1
+}
+
 var (
 	ErrClosed                  = errors.New("gogorpc/stream: closed")
 	ErrTooManyOutgoingRequests = errors.New("gogorpc/stream: too many outgoing requests")
 	ErrRequestExpired          = errors.New("gogorpc/stream: request expired")
+	ErrRequestWithdrawn        = errors.New("gogorpc/stream: request withdrawn")
+	ErrRequestRefused          = errors.New("gogorpc/stream: request refused")
+	ErrPingUnsupported         = errors.New("gogorpc/stream: ping unsupported")
 )
 
 func PutPooledPendingRequests(listOfPendingRequests *deque.List) {
@@ -925,36 +2048,77 @@ func PutPooledPendingRequests(listOfPendingRequests *deque.List) {
 		listNode.Remove()
 		listNode.Reset()
 		pendingRequest_ := (*pendingRequest)(listNode.GetContainer(unsafe.Offsetof(pendingRequest{}.ListNode)))
-		pendingRequestPool.Put(pendingRequest_)
+		releasePendingRequest(pendingRequest_)
 	}
 }
 
 type pendingRequest struct {
-	ListNode   intrusive.ListNode
-	Header     proto.RequestHeader
-	Underlying Message
+	ListNode       intrusive.ListNode
+	Ctx            context.Context
+	Header         proto.RequestHeader
+	Underlying     Message
+	StopWithdrawal func() bool
 }
 
 type pendingResponse struct {
-	ListNode   intrusive.ListNode
-	Header     proto.ResponseHeader
-	Underlying Message
+	ListNode            intrusive.ListNode
+	EventType           EventType
+	Header              proto.ResponseHeader
+	StreamMessageHeader proto.StreamMessageHeader
+	StreamEndHeader     proto.StreamEndHeader
+	CancelHeader        proto.CancelHeader
+	NotificationHeader  proto.NotificationHeader
+	Underlying          Message
+	WindowCost          int
+	Flush               chan<- error
+}
+
+type messageHeader interface {
+	Size() int
+	MarshalTo([]byte) (int, error)
 }
 
-var errBadEvent = errors.New("gogorpc/stream: bad event")
+var (
+	errBadEvent    = errors.New("gogorpc/stream: bad event")
+	errBadChecksum = errors.New("gogorpc/stream: bad checksum")
+)
 
 var (
 	pendingRequestPool  = sync.Pool{New: func() interface{} { return new(pendingRequest) }}
 	pendingResponsePool = sync.Pool{New: func() interface{} { return new(pendingResponse) }}
 )
 
-func putPooledPendingResponses(listOfPendingResponses *deque.List) {
+func releasePendingRequest(pendingRequest_ *pendingRequest) {
+	if stopWithdrawal := pendingRequest_.StopWithdrawal; stopWithdrawal != nil {
+		stopWithdrawal()
+		pendingRequest_.StopWithdrawal = nil
+	}
+
+	// drop the references so that pooled requests keep neither contexts nor messages alive.
+	pendingRequest_.Ctx = nil
+	pendingRequest_.Underlying = nil
+	pendingRequestPool.Put(pendingRequest_)
+}
+
+func releasePendingResponse(pendingResponse_ *pendingResponse, err error) {
+	if flush := pendingResponse_.Flush; flush != nil {
+		flush <- err
+		pendingResponse_.Flush = nil
+	}
+
+	pendingResponse_.Underlying = nil
+	pendingResponsePool.Put(pendingResponse_)
+}
+
+func releasePendingResponses(listOfPendingResponses *deque.List, err error) {
 	getListNode := listOfPendingResponses.Underlying.GetNodesSafely()
 
 	for listNode := getListNode(); listNode != nil; listNode = getListNode() {
 		listNode.Remove()
 		listNode.Reset()
 		pendingResponse_ := (*pendingResponse)(listNode.GetContainer(unsafe.Offsetof(pendingResponse{}.ListNode)))
-		pendingResponsePool.Put(pendingResponse_)
+		releasePendingResponse(pendingResponse_, err)
 	}
+
+	listOfPendingResponses.Length = 0
 }
@@ -11,7 +11,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/let-z-go/toolkit/deque"
+	"github.com/let-z-go/intrusive"
 	"github.com/let-z-go/toolkit/uuid"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +27,8 @@ func TestOptions(t *testing.T) {
 		OutgoingKeepaliveInterval time.Duration
 		IncomingConcurrencyLimit  int
 		OutgoingConcurrencyLimit  int
+		IncomingWindowSize        int
+		OutgoingWindowSize        int
 	}
 	makePureOptions := func(opts *Options) PureOptions {
 		return PureOptions{
@@ -35,6 +37,8 @@ func TestOptions(t *testing.T) {
 			OutgoingKeepaliveInterval: opts.OutgoingKeepaliveInterval,
 			IncomingConcurrencyLimit:  opts.IncomingConcurrencyLimit,
 			OutgoingConcurrencyLimit:  opts.OutgoingConcurrencyLimit,
+			IncomingWindowSize:        opts.IncomingWindowSize,
+			OutgoingWindowSize:        opts.OutgoingWindowSize,
 		}
 	}
 	{
@@ -44,6 +48,8 @@ func TestOptions(t *testing.T) {
 			OutgoingKeepaliveInterval: -1,
 			IncomingConcurrencyLimit:  -1,
 			OutgoingConcurrencyLimit:  -1,
+			IncomingWindowSize:        -1,
+			OutgoingWindowSize:        -1,
 		}
 		opts1.Normalize()
 		opts2 := Options{
@@ -52,6 +58,8 @@ func TestOptions(t *testing.T) {
 			OutgoingKeepaliveInterval: minKeepaliveInterval,
 			IncomingConcurrencyLimit:  minConcurrencyLimit,
 			OutgoingConcurrencyLimit:  minConcurrencyLimit,
+			IncomingWindowSize:        minWindowSize,
+			OutgoingWindowSize:        minWindowSize,
 		}
 		assert.Equal(t, makePureOptions(&opts2), makePureOptions(&opts1))
 	}
@@ -62,6 +70,8 @@ func TestOptions(t *testing.T) {
 			OutgoingKeepaliveInterval: math.MaxInt64,
 			IncomingConcurrencyLimit:  math.MaxInt32,
 			OutgoingConcurrencyLimit:  math.MaxInt32,
+			IncomingWindowSize:        math.MaxInt32,
+			OutgoingWindowSize:        math.MaxInt32,
 		}
 		opts1.Normalize()
 		opts2 := Options{
@@ -70,6 +80,8 @@ func TestOptions(t *testing.T) {
 			OutgoingKeepaliveInterval: maxKeepaliveInterval,
 			IncomingConcurrencyLimit:  maxConcurrencyLimit,
 			OutgoingConcurrencyLimit:  maxConcurrencyLimit,
+			IncomingWindowSize:        maxWindowSize,
+			OutgoingWindowSize:        maxWindowSize,
 		}
 		assert.Equal(t, makePureOptions(&opts2), makePureOptions(&opts1))
 	}
@@ -79,7 +91,7 @@ func TestHandshake1(t *testing.T) {
 	testSetup(
 		t,
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{Logger: &logger}}, false, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{Logger: &logger}}, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{
 				CbEmitHandshake: func() (Message, error) {
@@ -103,7 +115,7 @@ func TestHandshake1(t *testing.T) {
 			assert.True(t, ok)
 		},
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{Logger: &logger}}, true, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{Logger: &logger}}, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{
 				CbNewHandshake: func() Message {
@@ -133,7 +145,7 @@ func TestHandshake2(t *testing.T) {
 	testSetup(
 		t,
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, false, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
 			if !assert.Regexp(t, "i/o timeout", err) {
@@ -142,7 +154,7 @@ func TestHandshake2(t *testing.T) {
 			assert.False(t, ok)
 		},
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, true, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{
 				CbHandleHandshake: func(ctx context.Context, h Message) (bool, error) {
@@ -163,7 +175,7 @@ func TestHandshake3(t *testing.T) {
 	testSetup(
 		t,
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, false, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{
 				CbHandleHandshake: func(ctx context.Context, h Message) (bool, error) {
@@ -178,7 +190,7 @@ func TestHandshake3(t *testing.T) {
 			assert.False(t, ok)
 		},
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, true, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(&Options{Transport: &transport.Options{HandshakeTimeout: -1}}, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
 			if !assert.NoError(t, err) {
@@ -565,6 +577,757 @@ func TestKeepalive(t *testing.T) {
 	assert.Greater(t, j, 0)
 }
 
+func TestKeepaliveWithoutHeader(t *testing.T) {
+	opts := Options{IncomingKeepaliveInterval: -1, OutgoingKeepaliveInterval: -1}
+	n := int32(0)
+	mp := testMessageProcessor{
+		CbNewKeepalive: func(ev *Event) {
+			ev.Message = NullMessage
+		},
+		CbHandleKeepalive: func(ctx context.Context, ev *Event) {
+			atomic.AddInt32(&n, 1)
+		},
+		CbEmitKeepalive: func(ev *Event) {
+			ev.Message = NullMessage
+		},
+	}.Init()
+	cb := func(ctx context.Context, conn net.Conn, isServerSide bool) {
+		st := new(Stream).Init(&opts, isServerSide, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+		defer st.Close()
+		ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+		if !assert.NoError(t, err) || !assert.True(t, ok) {
+			t.FailNow()
+		}
+		assert.True(t, st.isKeepaliveHeaderEnabled)
+		// act as a peer predating keepalive headers.
+		st.isKeepaliveHeaderEnabled = false
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp)
+			t.Log(err)
+		}()
+		defer wg.Wait()
+		if !isServerSide {
+			_, err := st.Ping(ctx)
+			assert.Equal(t, ErrPingUnsupported, err)
+			time.Sleep(100 * time.Millisecond)
+			st.Abort(nil)
+		}
+	}
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			cb(ctx, conn, false)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			cb(ctx, conn, true)
+		},
+	)
+	assert.Greater(t, atomic.LoadInt32(&n), int32(0))
+}
+
+func TestSettings1(t *testing.T) {
+	mp1 := testMessageProcessor{}.Init()
+	mp2 := testMessageProcessor{}.Init()
+	testSetup2(
+		t,
+		&Options{OutgoingKeepaliveInterval: minKeepaliveInterval, IncomingConcurrencyLimit: minConcurrencyLimit},
+		&Options{OutgoingKeepaliveInterval: minKeepaliveInterval, OutgoingConcurrencyLimit: minConcurrencyLimit + 100},
+		&mp1,
+		&mp2,
+		func(ctx context.Context, st *Stream) {
+			st.UpdateSettings(minKeepaliveInterval+2*time.Second, minConcurrencyLimit+200)
+			time.Sleep(100 * time.Millisecond)
+			st.Abort(nil)
+		},
+		func(ctx context.Context, st *Stream) {
+		},
+	)
+	assert.Equal(t, minKeepaliveInterval+2*time.Second, mp1.Stream.incomingKeepaliveInterval)
+	assert.Equal(t, minConcurrencyLimit+100, mp1.Stream.incomingConcurrencyLimit)
+	assert.Equal(t, minKeepaliveInterval+2*time.Second, mp2.Stream.outgoingKeepaliveInterval)
+	assert.Equal(t, minConcurrencyLimit+100, mp2.Stream.outgoingConcurrencyLimit)
+}
+
+func TestSettings2(t *testing.T) {
+	mp1 := testMessageProcessor{}.Init()
+	mp2 := testMessageProcessor{}.Init()
+	testSetup2(
+		t,
+		&Options{IncomingConcurrencyLimit: minConcurrencyLimit + 100},
+		&Options{OutgoingConcurrencyLimit: minConcurrencyLimit + 100},
+		&mp1,
+		&mp2,
+		func(ctx context.Context, st *Stream) {
+			st.UpdateSettings(0, minConcurrencyLimit)
+			time.Sleep(100 * time.Millisecond)
+			st.Abort(nil)
+		},
+		func(ctx context.Context, st *Stream) {
+		},
+	)
+	assert.Equal(t, minConcurrencyLimit, mp1.Stream.incomingConcurrencyLimit)
+	assert.Equal(t, minConcurrencyLimit, mp2.Stream.outgoingConcurrencyLimit)
+}
+
+func TestStreamMessage(t *testing.T) {
+	const N = 100
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbNewStreamMessage: func(ev *Event) {
+			ev.Message = new(RawMessage)
+		},
+		CbHandleStreamMessage: func(ctx context.Context, ev *Event) {
+			assert.True(t, ev.StreamMessageHeader.IsResponse)
+			assert.Equal(t, int32(1), ev.StreamMessageHeader.SequenceNumber)
+			assert.Equal(t, "pong", string(*ev.Message.(*RawMessage)))
+		},
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, int32(1), ev.ResponseHeader.SequenceNumber)
+			mp1.Stream.Abort(nil)
+		},
+	}.Init()
+	cb1 := func(ctx context.Context, st *Stream) {
+		err := st.SendRequest(ctx, &proto.RequestHeader{SequenceNumber: 1}, NullMessage)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for i := 0; i < N; i++ {
+			msg := RawMessage("ping")
+			err := st.SendStreamMessage(ctx, &proto.StreamMessageHeader{SequenceNumber: 1}, &msg)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		err = st.SendStreamEnd(&proto.StreamEndHeader{SequenceNumber: 1})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	n := 0
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbNewStreamMessage: func(ev *Event) {
+			ev.Message = new(RawMessage)
+		},
+		CbHandleStreamMessage: func(ctx context.Context, ev *Event) {
+			assert.False(t, ev.StreamMessageHeader.IsResponse)
+			assert.Equal(t, int32(1), ev.StreamMessageHeader.SequenceNumber)
+			assert.Equal(t, "ping", string(*ev.Message.(*RawMessage)))
+			n++
+			msg := RawMessage("pong")
+			ev.Err = mp2.Stream.SendStreamMessage(ctx, &proto.StreamMessageHeader{
+				SequenceNumber: ev.StreamMessageHeader.SequenceNumber,
+				IsResponse:     true,
+			}, &msg)
+		},
+		CbHandleStreamEnd: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, int32(1), ev.StreamEndHeader.SequenceNumber)
+			assert.Equal(t, N, n)
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.StreamEndHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	cb2 := func(ctx context.Context, st *Stream) {
+	}
+	testSetup2(t, &opts1, &opts2, &mp1, &mp2, cb1, cb2)
+	assert.Equal(t, N, n)
+}
+
+func TestFlowControl1(t *testing.T) {
+	const N = 64
+	const M = 8 * 1024
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}, IncomingWindowSize: minWindowSize}
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, int32(1), ev.ResponseHeader.SequenceNumber)
+			mp1.Stream.Abort(nil)
+		},
+	}.Init()
+	cb1 := func(ctx context.Context, st *Stream) {
+		assert.Equal(t, minWindowSize, st.outgoingWindowSize)
+		err := st.SendRequest(ctx, &proto.RequestHeader{SequenceNumber: 1}, NullMessage)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for i := 0; i < N; i++ {
+			msg := RawMessage(make([]byte, M))
+			err := st.SendStreamMessage(ctx, &proto.StreamMessageHeader{SequenceNumber: 1}, &msg)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		err = st.SendStreamEnd(&proto.StreamEndHeader{SequenceNumber: 1})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	n := 0
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbNewStreamMessage: func(ev *Event) {
+			ev.Message = new(RawMessage)
+		},
+		CbHandleStreamMessage: func(ctx context.Context, ev *Event) {
+			assert.Len(t, *ev.Message.(*RawMessage), M)
+			n++
+			time.Sleep(time.Millisecond)
+		},
+		CbHandleStreamEnd: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, N, n)
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.StreamEndHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	cb2 := func(ctx context.Context, st *Stream) {
+	}
+	testSetup2(t, &opts1, &opts2, &mp1, &mp2, cb1, cb2)
+	assert.Equal(t, N, n)
+}
+
+func TestFlowControl2(t *testing.T) {
+	const N = 10
+	const M = 8 * 1024
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}, IncomingWindowSize: minWindowSize}
+	mp1 := testMessageProcessor{}.Init()
+	cb1 := func(ctx context.Context, st *Stream) {
+		for i := 0; i < N; i++ {
+			msg := RawMessage(make([]byte, M))
+			err := st.SendRequest(ctx, &proto.RequestHeader{SequenceNumber: int32(i + 1)}, &msg)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+	}
+	n := int32(0)
+	windowCredit := int64(0)
+	mp2 := testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			// hold the requests as if they were still being handled.
+			atomic.AddInt64(&windowCredit, int64(ev.TakeWindowCredit()))
+			atomic.AddInt32(&n, 1)
+		},
+	}.Init()
+	cb2 := func(ctx context.Context, st *Stream) {
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, int32(minWindowSize/messageWindowCost(M)), atomic.LoadInt32(&n))
+		st.ReturnWindowCredit(int(atomic.SwapInt64(&windowCredit, 0)))
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, int32(N), atomic.LoadInt32(&n))
+		st.Abort(nil)
+	}
+	testSetup2(t, &opts1, &opts2, &mp1, &mp2, cb1, cb2)
+}
+
+func TestMethodID1(t *testing.T) {
+	const N = 10
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	methodNames := [...]string{"Foo", "Bar"}
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			if ev.ResponseHeader.SequenceNumber == N-1 {
+				mp1.Stream.Abort(nil)
+			}
+		},
+	}.Init()
+	cb1 := func(ctx context.Context, st *Stream) {
+		for i := 0; i < N; i++ {
+			err := st.SendRequest(ctx, &proto.RequestHeader{
+				SequenceNumber: int32(i),
+				ServiceName:    "Test",
+				MethodName:     methodNames[i%len(methodNames)],
+			}, NullMessage)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+	}
+	n := 0
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, "Test", ev.RequestHeader.ServiceName)
+			assert.Equal(t, methodNames[int(ev.RequestHeader.SequenceNumber)%len(methodNames)], ev.RequestHeader.MethodName)
+			n++
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.RequestHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	cb2 := func(ctx context.Context, st *Stream) {
+	}
+	testSetup2(t, &opts1, &opts2, &mp1, &mp2, cb1, cb2)
+	assert.Equal(t, N, n)
+	assert.Len(t, mp1.Stream.outgoingMethodTable.methodIDs, len(methodNames))
+	assert.Len(t, mp2.Stream.incomingMethodTable.methodKeys, len(methodNames))
+}
+
+func TestMethodID2(t *testing.T) {
+	const N = 10
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	methodIDs := []int32(nil)
+	opts2.AddEventFilter(EventIncoming, EventRequest, func(ev *Event) {
+		methodIDs = append(methodIDs, ev.RequestHeader.MethodId)
+	})
+	n := 0
+	mp := testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			assert.Equal(t, "Test", ev.RequestHeader.ServiceName)
+			assert.Equal(t, "Foo", ev.RequestHeader.MethodName)
+			n++
+			if n == N {
+				ev.Stream().Abort(nil)
+			}
+		},
+	}.Init()
+	cb := func(ctx context.Context, conn net.Conn, opts *Options, isServerSide bool) {
+		st := new(Stream).Init(opts, isServerSide, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+		defer st.Close()
+		ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+		if !assert.NoError(t, err) || !assert.True(t, ok) {
+			t.FailNow()
+		}
+		assert.True(t, st.isMethodIDEnabled)
+		if !isServerSide {
+			// act as a peer predating method ids.
+			st.isMethodIDEnabled = false
+		}
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp)
+			t.Log(err)
+		}()
+		defer wg.Wait()
+		if !isServerSide {
+			for i := 0; i < N; i++ {
+				err := st.SendRequest(ctx, &proto.RequestHeader{
+					SequenceNumber: int32(i),
+					ServiceName:    "Test",
+					MethodName:     "Foo",
+				}, NullMessage)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+		}
+	}
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			cb(ctx, conn, &opts1, false)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			cb(ctx, conn, &opts2, true)
+		},
+	)
+	assert.Equal(t, N, n)
+	assert.Equal(t, make([]int32, N), methodIDs)
+}
+
+func TestRequestWithdrawal1(t *testing.T) {
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	sns := []int32(nil)
+	withdrawnSNs := []int32(nil)
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbPostEmitRequest: func(ev *Event) {
+			if ev.Err == ErrRequestWithdrawn {
+				withdrawnSNs = append(withdrawnSNs, ev.RequestHeader.SequenceNumber)
+			}
+		},
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			if ev.ResponseHeader.SequenceNumber == 3 {
+				mp1.Stream.Abort(nil)
+			}
+		},
+	}.Init()
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			sns = append(sns, ev.RequestHeader.SequenceNumber)
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.RequestHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts1, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(3))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			// queue the requests before processing starts, so the second one is withdrawn while waiting.
+			ctx2, cancel2 := context.WithCancel(ctx)
+			for i, ctx_ := range []context.Context{ctx, ctx2, ctx} {
+				err := st.SendRequest(ctx_, &proto.RequestHeader{SequenceNumber: int32(i + 1)}, NullMessage)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			cancel2()
+			mp1.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp1)
+			t.Log(err)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts2, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			mp2.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp2)
+			t.Log(err)
+		},
+	)
+	assert.Equal(t, []int32{2}, withdrawnSNs)
+	assert.Equal(t, []int32{1, 3}, sns)
+}
+
+func TestRequestWithdrawal2(t *testing.T) {
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	sns := []int32(nil)
+	withdrawnSNs := []int32(nil)
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbPostEmitRequest: func(ev *Event) {
+			if ev.Err == ErrRequestWithdrawn {
+				withdrawnSNs = append(withdrawnSNs, ev.RequestHeader.SequenceNumber)
+			}
+		},
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			if ev.ResponseHeader.SequenceNumber == 2 {
+				mp1.Stream.Abort(nil)
+			}
+		},
+	}.Init()
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			sns = append(sns, ev.RequestHeader.SequenceNumber)
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.RequestHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts1, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(1))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			// the withdrawn request gives its slot back before processing starts.
+			ctx2, cancel2 := context.WithCancel(ctx)
+			err = st.SendRequest(ctx2, &proto.RequestHeader{SequenceNumber: 1}, NullMessage)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			cancel2()
+			ctx3, cancel3 := context.WithTimeout(ctx, time.Second)
+			defer cancel3()
+			err = st.SendRequest(ctx3, &proto.RequestHeader{SequenceNumber: 2}, NullMessage)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			mp1.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp1)
+			t.Log(err)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts2, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			mp2.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp2)
+			t.Log(err)
+		},
+	)
+	assert.Equal(t, []int32{1}, withdrawnSNs)
+	assert.Equal(t, []int32{2}, sns)
+}
+
+func TestRequestDeadline(t *testing.T) {
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	// the wire deadline is off by an hour as if the clocks were skewed, the timeout is what counts.
+	opts1.AddEventFilter(EventOutgoing, EventRequest, func(ev *Event) {
+		ev.RequestHeader.Deadline += int64(time.Hour)
+	})
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	expiredSNs := []int32(nil)
+	var timeout time.Duration
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbPostEmitRequest: func(ev *Event) {
+			if ev.Err == ErrRequestExpired {
+				expiredSNs = append(expiredSNs, ev.RequestHeader.SequenceNumber)
+			}
+		},
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			mp1.Stream.Abort(nil)
+		},
+	}.Init()
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			timeout = time.Until(time.Unix(0, ev.RequestHeader.Deadline))
+			ev.Err = mp2.Stream.SendResponse(&proto.ResponseHeader{
+				SequenceNumber: ev.RequestHeader.SequenceNumber,
+			}, NullMessage)
+		},
+	}.Init()
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts1, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(2))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			// the first request expires while queued.
+			now := time.Now()
+			for i, timeout := range []time.Duration{100 * time.Millisecond, 2 * time.Second} {
+				err := st.SendRequest(ctx, &proto.RequestHeader{
+					SequenceNumber: int32(i + 1),
+					Deadline:       now.Add(timeout).UnixNano(),
+				}, NullMessage)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			time.Sleep(200 * time.Millisecond)
+			mp1.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp1)
+			t.Log(err)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts2, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			mp2.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp2)
+			t.Log(err)
+		},
+	)
+	assert.Equal(t, []int32{1}, expiredSNs)
+	assert.True(t, timeout > time.Second && timeout <= 1800*time.Millisecond, "timeout=%v", timeout)
+}
+
+func TestShedExcessIncomingRequests(t *testing.T) {
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{
+		Transport:                       &transport.Options{Logger: &logger},
+		ShedExcessIncomingRequests:      true,
+		ExcessIncomingRequestRetryAfter: 1500 * time.Millisecond,
+	}
+	sns := []int32(nil)
+	var rpcErr proto.RPCError
+	var retryAfter time.Duration
+	var mp1 testMessageProcessor
+	mp1 = testMessageProcessor{
+		CbHandleResponse: func(ctx context.Context, ev *Event) {
+			rpcErr = ev.ResponseHeader.RpcError
+			retryAfter, _ = GetRetryAfter(ExtraData(ev.ResponseHeader.ExtraData).Ref(false))
+			mp1.Stream.Abort(nil)
+		},
+	}.Init()
+	mp2 := testMessageProcessor{
+		CbHandleRequest: func(ctx context.Context, ev *Event) {
+			// hold the request so that the next one exceeds the concurrency limit.
+			sns = append(sns, ev.RequestHeader.SequenceNumber)
+		},
+	}.Init()
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts1, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			mp1.Stream = st
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp1)
+				t.Log(err)
+			}()
+			defer wg.Wait()
+			for i := 0; i < 2; i++ {
+				err := st.SendRequest(ctx, &proto.RequestHeader{SequenceNumber: int32(i + 1)}, NullMessage)
+				assert.NoError(t, err)
+			}
+		},
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts2, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			// the peer still believes in the negotiated limit.
+			st.incomingConcurrencyLimit = 1
+			mp2.Stream = st
+			err = st.Process(ctx, &testTrafficCrypter{"admin", 0}, mp2)
+			t.Log(err)
+		},
+	)
+	assert.Equal(t, []int32{1}, sns)
+	assert.Equal(t, proto.RPC_ERROR_TOO_MANY_REQUESTS, rpcErr.Type)
+	assert.Equal(t, 1500*time.Millisecond, retryAfter)
+}
+
+func TestNotification1(t *testing.T) {
+	const N = 20
+	opts1 := Options{Transport: &transport.Options{Logger: &logger}}
+	opts2 := Options{Transport: &transport.Options{Logger: &logger}}
+	mp1 := testMessageProcessor{}.Init()
+	cb1 := func(ctx context.Context, st *Stream) {
+		// the buffer is reused right away, which is only safe once the notification is flushed.
+		msg := make(RawMessage, 64*1024)
+		for i := 0; i < N; i++ {
+			for j := range msg {
+				msg[j] = byte(i)
+			}
+			err := st.SendNotification(ctx, &proto.NotificationHeader{SequenceNumber: int32(i)}, &msg)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+	}
+	n := 0
+	var mp2 testMessageProcessor
+	mp2 = testMessageProcessor{
+		CbNewNotification: func(ev *Event) {
+			ev.Message = new(RawMessage)
+		},
+		CbHandleNotification: func(ctx context.Context, ev *Event) {
+			msg := *ev.Message.(*RawMessage)
+			assert.Len(t, msg, 64*1024)
+			for _, b := range msg {
+				if !assert.Equal(t, byte(ev.NotificationHeader.SequenceNumber), b) {
+					break
+				}
+			}
+			n++
+			if n == N {
+				mp2.Stream.Abort(nil)
+			}
+		},
+	}.Init()
+	cb2 := func(ctx context.Context, st *Stream) {
+	}
+	testSetup2(t, &opts1, &opts2, &mp1, &mp2, cb1, cb2)
+	assert.Equal(t, N, n)
+}
+
+func TestNotification2(t *testing.T) {
+	opts := Options{Transport: &transport.Options{Logger: &logger}}
+	testSetup(
+		t,
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+			// the window is opened on processing otherwise.
+			st.outgoingWindow.Open(1024)
+			errs := make(chan error, 1)
+			go func() {
+				errs <- st.SendNotification(ctx, &proto.NotificationHeader{}, NullMessage)
+			}()
+			// never processed, so the notification waits for being flushed until the stream is closed.
+			for st.dequeOfPendingResponses.Length() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			select {
+			case err := <-errs:
+				assert.Failf(t, "notification completed before flushing", "%v", err)
+			case <-time.After(100 * time.Millisecond):
+			}
+			st.Close()
+			assert.Equal(t, ErrClosed, <-errs)
+		},
+		func(ctx context.Context, conn net.Conn) {
+			st := new(Stream).Init(&opts, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
+			defer st.Close()
+			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
+			if !assert.NoError(t, err) || !assert.True(t, ok) {
+				t.FailNow()
+			}
+		},
+	)
+}
+
+func TestRequestAdmission(t *testing.T) {
+	dopr := new(DequeOfPendingRequests).Init(0)
+	admitted := make(chan int32)
+	admit := func(priority int32) {
+		var listNode intrusive.ListNode
+		err := dopr.AppendNode(context.Background(), &listNode, priority)
+		assert.NoError(t, err)
+		admitted <- priority
+	}
+	waitForAdmissions := func(n int, turnHolderPriority int32) {
+		for {
+			dopr.admissionQueue.mutex.Lock()
+			m := len(dopr.admissionQueue.admissions)
+			turnHolder := dopr.admissionQueue.turnHolder
+			ok := m == n && turnHolder != nil && turnHolder.Priority == turnHolderPriority && turnHolder.cancel != nil
+			dopr.admissionQueue.mutex.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// the deque is full, so the first sender holds the turn and the others queue up.
+	go admit(1)
+	waitForAdmissions(0, 1)
+	for i, priority := range []int32{5, 3, 5, 0, 3} {
+		go admit(priority)
+		waitForAdmissions(i+1, 5)
+	}
+	priorities := []int32(nil)
+	for i := 0; i < 6; i++ {
+		dopr.CommitNodesRemoval(1)
+		priorities = append(priorities, <-admitted)
+	}
+	assert.Equal(t, []int32{5, 5, 3, 3, 1, 0}, priorities)
+}
+
 func testSetup(
 	t *testing.T,
 	cb1 func(ctx context.Context, conn net.Conn),
@@ -607,7 +1370,7 @@ func testSetup2(
 	testSetup(
 		t,
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(opts1, false, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(opts1, false, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
 			if !assert.NoError(t, err) {
@@ -628,7 +1391,7 @@ func testSetup2(
 			cb1(ctx, st)
 		},
 		func(ctx context.Context, conn net.Conn) {
-			st := new(Stream).Init(opts2, true, uuid.UUID{}, nil, new(deque.Deque).Init(0))
+			st := new(Stream).Init(opts2, true, uuid.UUID{}, nil, new(DequeOfPendingRequests).Init(0))
 			defer st.Close()
 			ok, err := st.Establish(ctx, conn, testHandshaker{}.Init())
 			if !assert.NoError(t, err) {
@@ -700,16 +1463,25 @@ func (s *testTrafficCrypter) DecryptTraffic(traffic []byte) {
 }
 
 type testMessageProcessor struct {
-	CbNewKeepalive     func(*Event)
-	CbHandleKeepalive  func(context.Context, *Event)
-	CbEmitKeepalive    func(*Event)
-	CbNewRequest       func(*Event)
-	CbHandleRequest    func(context.Context, *Event)
-	CbPostEmitRequest  func(*Event)
-	CbNewResponse      func(*Event)
-	CbHandleResponse   func(context.Context, *Event)
-	CbPostEmitResponse func(*Event)
-	Stream             *Stream
+	CbNewKeepalive          func(*Event)
+	CbHandleKeepalive       func(context.Context, *Event)
+	CbEmitKeepalive         func(*Event)
+	CbNewRequest            func(*Event)
+	CbHandleRequest         func(context.Context, *Event)
+	CbPostEmitRequest       func(*Event)
+	CbNewResponse           func(*Event)
+	CbHandleResponse        func(context.Context, *Event)
+	CbPostEmitResponse      func(*Event)
+	CbNewStreamMessage      func(*Event)
+	CbHandleStreamMessage   func(context.Context, *Event)
+	CbHandleStreamEnd       func(context.Context, *Event)
+	CbHandleCancel          func(context.Context, *Event)
+	CbPostEmitStreamMessage func(*Event)
+	CbNewNotification       func(*Event)
+	CbHandleNotification    func(context.Context, *Event)
+	CbPostEmitNotification  func(*Event)
+	CbHandleGoAway          func(context.Context, *Event)
+	Stream                  *Stream
 }
 
 func (s testMessageProcessor) Init() testMessageProcessor {
@@ -740,6 +1512,33 @@ func (s testMessageProcessor) Init() testMessageProcessor {
 	if s.CbPostEmitResponse == nil {
 		s.CbPostEmitResponse = func(*Event) {}
 	}
+	if s.CbNewStreamMessage == nil {
+		s.CbNewStreamMessage = func(ev *Event) { ev.Message = NullMessage }
+	}
+	if s.CbHandleStreamMessage == nil {
+		s.CbHandleStreamMessage = func(context.Context, *Event) {}
+	}
+	if s.CbHandleStreamEnd == nil {
+		s.CbHandleStreamEnd = func(context.Context, *Event) {}
+	}
+	if s.CbHandleCancel == nil {
+		s.CbHandleCancel = func(context.Context, *Event) {}
+	}
+	if s.CbPostEmitStreamMessage == nil {
+		s.CbPostEmitStreamMessage = func(*Event) {}
+	}
+	if s.CbNewNotification == nil {
+		s.CbNewNotification = func(ev *Event) { ev.Message = NullMessage }
+	}
+	if s.CbHandleNotification == nil {
+		s.CbHandleNotification = func(context.Context, *Event) {}
+	}
+	if s.CbPostEmitNotification == nil {
+		s.CbPostEmitNotification = func(*Event) {}
+	}
+	if s.CbHandleGoAway == nil {
+		s.CbHandleGoAway = func(context.Context, *Event) {}
+	}
 	return s
 }
 
@@ -779,4 +1578,40 @@ func (s testMessageProcessor) PostEmitResponse(ev *Event) {
 	s.CbPostEmitResponse(ev)
 }
 
+func (s testMessageProcessor) NewStreamMessage(ev *Event) {
+	s.CbNewStreamMessage(ev)
+}
+
+func (s testMessageProcessor) HandleStreamMessage(ctx context.Context, ev *Event) {
+	s.CbHandleStreamMessage(ctx, ev)
+}
+
+func (s testMessageProcessor) HandleStreamEnd(ctx context.Context, ev *Event) {
+	s.CbHandleStreamEnd(ctx, ev)
+}
+
+func (s testMessageProcessor) HandleCancel(ctx context.Context, ev *Event) {
+	s.CbHandleCancel(ctx, ev)
+}
+
+func (s testMessageProcessor) PostEmitStreamMessage(ev *Event) {
+	s.CbPostEmitStreamMessage(ev)
+}
+
+func (s testMessageProcessor) NewNotification(ev *Event) {
+	s.CbNewNotification(ev)
+}
+
+func (s testMessageProcessor) HandleNotification(ctx context.Context, ev *Event) {
+	s.CbHandleNotification(ctx, ev)
+}
+
+func (s testMessageProcessor) PostEmitNotification(ev *Event) {
+	s.CbPostEmitNotification(ev)
+}
+
+func (s testMessageProcessor) HandleGoAway(ctx context.Context, ev *Event) {
+	s.CbHandleGoAway(ctx, ev)
+}
+
 var logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Logger()
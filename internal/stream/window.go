@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+type outgoingWindow struct {
+	mutex     sync.Mutex
+	size      int
+	available int
+	change    chan struct{}
+}
+
+func (ow *outgoingWindow) Init() *outgoingWindow {
+	ow.change = make(chan struct{})
+	return ow
+}
+
+func (ow *outgoingWindow) Open(size int) {
+	ow.mutex.Lock()
+	ow.available += size - ow.size
+	ow.size = size
+	ow.notifyChange()
+	ow.mutex.Unlock()
+}
+
+func (ow *outgoingWindow) Acquire(ctx context.Context, closure <-chan struct{}, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	for {
+		ow.mutex.Lock()
+
+		if ow.isAcquirable(n) {
+			ow.available -= n
+			ow.mutex.Unlock()
+			return nil
+		}
+
+		change := ow.change
+		ow.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-closure:
+			return ErrClosed
+		case <-change:
+		}
+	}
+}
+
+func (ow *outgoingWindow) ForceAcquire(n int) {
+	ow.mutex.Lock()
+	ow.available -= n
+	ow.mutex.Unlock()
+}
+
+func (ow *outgoingWindow) TryAcquire(n int) bool {
+	ow.mutex.Lock()
+	ok := ow.isAcquirable(n)
+
+	if ok {
+		ow.available -= n
+	}
+
+	ow.mutex.Unlock()
+	return ok
+}
+
+func (ow *outgoingWindow) WaitFor(ctx context.Context, n int) error {
+	for {
+		ow.mutex.Lock()
+
+		if ow.isAcquirable(n) {
+			ow.mutex.Unlock()
+			return nil
+		}
+
+		change := ow.change
+		ow.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-change:
+		}
+	}
+}
+
+func (ow *outgoingWindow) Release(n int) {
+	if n == 0 {
+		return
+	}
+
+	ow.mutex.Lock()
+	ow.available += n
+	ow.notifyChange()
+	ow.mutex.Unlock()
+}
+
+func (ow *outgoingWindow) isAcquirable(n int) bool {
+	// a message larger than the window may go once nothing else is in flight.
+	return ow.size >= 1 && (n <= ow.available || ow.available == ow.size)
+}
+
+func (ow *outgoingWindow) notifyChange() {
+	close(ow.change)
+	ow.change = make(chan struct{})
+}
+
+// every message costs some window on top of its payload, so that the number of messages
+// the peer has to buffer is bounded as well, however small they are.
+const messageWindowOverhead = 64
+
+func messageWindowCost(messageSize int) int {
+	return messageWindowOverhead + messageSize
+}
@@ -0,0 +1,173 @@
+package transport
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+
+	"github.com/let-z-go/toolkit/bytestream"
+)
+
+// BufferPool lends byte streams to transports, which hold on to them only while reading or
+// writing, and keeps the memory transports use for buffering within a memory budget: reading
+// and writing are held back while the budget is used up. A buffer larger than the whole
+// budget is refused with ErrOutOfMemoryBudget.
+type BufferPool struct {
+	mutex        sync.Mutex
+	memoryBudget int
+	usedMemory   int
+	change       chan struct{}
+	byteStreams  [bits.UintSize]sync.Pool
+}
+
+func (bp *BufferPool) Init(memoryBudget int) *BufferPool {
+	bp.memoryBudget = memoryBudget
+	bp.change = make(chan struct{})
+	return bp
+}
+
+// SetMemoryBudget changes the memory budget, 0 means no limit.
+func (bp *BufferPool) SetMemoryBudget(memoryBudget int) {
+	bp.mutex.Lock()
+	bp.memoryBudget = memoryBudget
+	bp.notifyChange()
+	bp.mutex.Unlock()
+}
+
+func (bp *BufferPool) MemoryBudget() int {
+	bp.mutex.Lock()
+	memoryBudget := bp.memoryBudget
+	bp.mutex.Unlock()
+	return memoryBudget
+}
+
+func (bp *BufferPool) UsedMemory() int {
+	bp.mutex.Lock()
+	usedMemory := bp.usedMemory
+	bp.mutex.Unlock()
+	return usedMemory
+}
+
+func (bp *BufferPool) acquireMemory(ctx context.Context, n int) error {
+	for {
+		bp.mutex.Lock()
+
+		if bp.memoryBudget < 1 || bp.usedMemory+n <= bp.memoryBudget {
+			bp.usedMemory += n
+			bp.mutex.Unlock()
+			return nil
+		}
+
+		// waiting would never end.
+		if n > bp.memoryBudget {
+			bp.mutex.Unlock()
+			return ErrOutOfMemoryBudget
+		}
+
+		change := bp.change
+		bp.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-change:
+		}
+	}
+}
+
+func (bp *BufferPool) tryAcquireMemory(n int) bool {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+
+	if bp.memoryBudget >= 1 && bp.usedMemory+n > bp.memoryBudget {
+		return false
+	}
+
+	bp.usedMemory += n
+	return true
+}
+
+func (bp *BufferPool) forceAcquireMemory(n int) {
+	bp.mutex.Lock()
+	bp.usedMemory += n
+	bp.mutex.Unlock()
+}
+
+func (bp *BufferPool) releaseMemory(n int) {
+	if n == 0 {
+		return
+	}
+
+	bp.mutex.Lock()
+	bp.usedMemory -= n
+	bp.notifyChange()
+	bp.mutex.Unlock()
+}
+
+func (bp *BufferPool) getByteStream(bufferSize int) *bytestream.ByteStream {
+	byteStream, _ := bp.byteStreams[ceilLog2(bufferSize)].Get().(*bytestream.ByteStream)
+
+	if byteStream == nil {
+		byteStream = new(bytestream.ByteStream)
+	}
+
+	byteStream.ReserveBuffer(bufferSize)
+	return byteStream
+}
+
+func (bp *BufferPool) putByteStream(byteStream *bytestream.ByteStream) {
+	size := byteStream.Size()
+
+	if size < 1 {
+		return
+	}
+
+	byteStream.Skip(byteStream.GetDataSize())
+	bp.byteStreams[floorLog2(size)].Put(byteStream)
+}
+
+func (bp *BufferPool) notifyChange() {
+	close(bp.change)
+	bp.change = make(chan struct{})
+}
+
+var DefaultBufferPool = new(BufferPool).Init(0)
+
+type inputBufferPins struct {
+	count      int32 // including the one held by the transport
+	bufferPool *BufferPool
+	byteStream *bytestream.ByteStream
+	bufferSize int
+}
+
+func (ibp *inputBufferPins) Detach(bufferPool *BufferPool, byteStream *bytestream.ByteStream, bufferSize int) {
+	ibp.bufferPool = bufferPool
+	ibp.byteStream = byteStream
+	ibp.bufferSize = bufferSize
+	ibp.Unpin()
+}
+
+func (ibp *inputBufferPins) Unpin() {
+	// the last one to let go gives the buffer back.
+	if atomic.AddInt32(&ibp.count, -1) == 0 {
+		ibp.bufferPool.putByteStream(ibp.byteStream)
+		ibp.bufferPool.releaseMemory(ibp.bufferSize)
+	}
+}
+
+func (ibp *inputBufferPins) IsPinned() bool {
+	return atomic.LoadInt32(&ibp.count) >= 2
+}
+
+func ceilLog2(x int) int {
+	if x < 2 {
+		return 0
+	}
+
+	return bits.Len(uint(x - 1))
+}
+
+func floorLog2(x int) int {
+	return bits.Len(uint(x)) - 1
+}
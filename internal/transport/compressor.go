@@ -0,0 +1,218 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Compressor interface {
+	Compress(dst []byte, src []byte) (result []byte, err error)
+	Decompress(dst []byte, src []byte, maxSize int) (result []byte, err error)
+}
+
+func RegisterCompressor(algorithmName string, compressor Compressor) error {
+	compressorsMutex.Lock()
+	defer compressorsMutex.Unlock()
+
+	if _, ok := compressors[algorithmName]; ok {
+		return &CompressorExistsError{fmt.Sprintf("algorithmName=%#v", algorithmName)}
+	}
+
+	compressors[algorithmName] = compressor
+	return nil
+}
+
+func MustRegisterCompressor(algorithmName string, compressor Compressor) {
+	if err := RegisterCompressor(algorithmName, compressor); err != nil {
+		panic(err)
+	}
+}
+
+func GetCompressor(algorithmName string) (Compressor, error) {
+	compressorsMutex.RLock()
+	compressor, ok := compressors[algorithmName]
+	compressorsMutex.RUnlock()
+
+	if !ok {
+		return nil, &CompressorNotFoundError{fmt.Sprintf("algorithmName=%#v", algorithmName)}
+	}
+
+	return compressor, nil
+}
+
+type CompressorExistsError struct {
+	context string
+}
+
+func (cee CompressorExistsError) Error() string {
+	message := "gogorpc/transport: compressor exists"
+
+	if cee.context != "" {
+		message += ": " + cee.context
+	}
+
+	return message
+}
+
+type CompressorNotFoundError struct {
+	context string
+}
+
+func (cnfe CompressorNotFoundError) Error() string {
+	message := "gogorpc/transport: compressor not found"
+
+	if cnfe.context != "" {
+		message += ": " + cnfe.context
+	}
+
+	return message
+}
+
+var (
+	compressorsMutex sync.RWMutex
+	compressors      = map[string]Compressor{}
+)
+
+type flateCompressor struct {
+	writerPool sync.Pool
+	readerPool sync.Pool
+}
+
+func (fc *flateCompressor) Compress(dst []byte, src []byte) ([]byte, error) {
+	buffer := bytes.NewBuffer(dst[:0])
+	writer, _ := fc.writerPool.Get().(*flate.Writer)
+
+	if writer == nil {
+		writer, _ = flate.NewWriter(buffer, flate.DefaultCompression)
+	} else {
+		writer.Reset(buffer)
+	}
+
+	defer fc.writerPool.Put(writer)
+
+	if _, err := writer.Write(src); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (fc *flateCompressor) Decompress(dst []byte, src []byte, maxSize int) ([]byte, error) {
+	reader, _ := fc.readerPool.Get().(io.ReadCloser)
+
+	if reader == nil {
+		reader = flate.NewReader(bytes.NewReader(src))
+	} else {
+		reader.(flate.Resetter).Reset(bytes.NewReader(src), nil)
+	}
+
+	defer fc.readerPool.Put(reader)
+	return readLimited(dst, reader, maxSize)
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(dst []byte, src []byte) ([]byte, error) {
+	return snappy.Encode(dst[:cap(dst)], src), nil
+}
+
+func (snappyCompressor) Decompress(dst []byte, src []byte, maxSize int) ([]byte, error) {
+	size, err := snappy.DecodedLen(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if size > maxSize {
+		return nil, ErrPacketTooLarge
+	}
+
+	return snappy.Decode(dst[:cap(dst)], src)
+}
+
+type zstdCompressor struct {
+	encoder      *zstd.Encoder
+	decoderPools sync.Map
+}
+
+func (zc *zstdCompressor) Init() *zstdCompressor {
+	zc.encoder, _ = zstd.NewWriter(nil)
+	return zc
+}
+
+func (zc *zstdCompressor) Compress(dst []byte, src []byte) ([]byte, error) {
+	return zc.encoder.EncodeAll(src, dst[:0]), nil
+}
+
+func (zc *zstdCompressor) Decompress(dst []byte, src []byte, maxSize int) ([]byte, error) {
+	// decoders are bound to the max size so that neither the window nor the decoded
+	// data of a hostile frame can outgrow it.
+	value, _ := zc.decoderPools.LoadOrStore(maxSize, &sync.Pool{})
+	decoderPool := value.(*sync.Pool)
+	decoder, _ := decoderPool.Get().(*zstd.Decoder)
+
+	if decoder == nil {
+		maxWindowSize := uint64(maxSize)
+
+		if maxWindowSize < zstd.MinWindowSize {
+			maxWindowSize = zstd.MinWindowSize
+		}
+
+		var err error
+
+		decoder, err = zstd.NewReader(
+			bytes.NewReader(src),
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxWindow(maxWindowSize),
+			zstd.WithDecoderMaxMemory(uint64(maxSize)+1),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := decoder.Reset(bytes.NewReader(src)); err != nil {
+			return nil, err
+		}
+	}
+
+	defer decoderPool.Put(decoder)
+	result, err := readLimited(dst, decoder, maxSize)
+
+	if err == zstd.ErrDecoderSizeExceeded {
+		return nil, ErrPacketTooLarge
+	}
+
+	return result, err
+}
+
+func readLimited(dst []byte, reader io.Reader, maxSize int) ([]byte, error) {
+	buffer := bytes.NewBuffer(dst[:0])
+
+	// read one more byte so that oversize data can be told apart.
+	if _, err := buffer.ReadFrom(io.LimitReader(reader, int64(maxSize)+1)); err != nil {
+		return nil, err
+	}
+
+	if buffer.Len() > maxSize {
+		return nil, ErrPacketTooLarge
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func init() {
+	MustRegisterCompressor("flate", &flateCompressor{})
+	MustRegisterCompressor("snappy", snappyCompressor{})
+	MustRegisterCompressor("zstd", new(zstdCompressor).Init())
+}
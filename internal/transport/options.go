@@ -9,13 +9,19 @@ import (
 )
 
 type Options struct {
-	Logger                *zerolog.Logger
-	HandshakeTimeout      time.Duration
-	MaxHandshakeSize      int
-	MinInputBufferSize    int
-	MaxInputBufferSize    int
-	MaxIncomingPacketSize int
-	MaxOutgoingPacketSize int
+	Logger                     *zerolog.Logger
+	HandshakeTimeout           time.Duration
+	MaxHandshakeSize           int
+	MinInputBufferSize         int
+	MaxInputBufferSize         int
+	MaxIncomingPacketSize      int
+	MaxOutgoingPacketSize      int
+	EnablePacketChecksum       bool
+	EnableRecordEncryption     bool // the key exchange is unauthenticated, it does not stop an active man in the middle
+	CompressionAlgorithms      []string
+	MinCompressiblePayloadSize int
+	MinVectoredPayloadSize     int
+	BufferPool                 *BufferPool
 
 	normalizeOnce sync.Once
 }
@@ -26,6 +32,10 @@ func (o *Options) Normalize() *Options {
 			o.Logger = &dummyLogger
 		}
 
+		if o.BufferPool == nil {
+			o.BufferPool = DefaultBufferPool
+		}
+
 		normalizeDurValue(&o.HandshakeTimeout, defaultHandshakeTimeout, minHandshakeTimeout, maxHandshakeTimeout)
 		normalizeIntValue(&o.MaxHandshakeSize, defaultMaxHandshakeSize, minMaxHandshakeSize, maxMaxHandshakeSize)
 		normalizeIntValue(&o.MinInputBufferSize, defaultMinInputBufferSize, minInputBufferSize, maxInputBufferSize)
@@ -44,6 +54,8 @@ func (o *Options) Normalize() *Options {
 		}
 
 		normalizeIntValue(&o.MaxOutgoingPacketSize, defaultMaxPacketSize, minMaxPacketSize, maxMaxPacketSize)
+		normalizeIntValue(&o.MinCompressiblePayloadSize, defaultMinCompressiblePayloadSize, minMinCompressiblePayloadSize, maxMinCompressiblePayloadSize)
+		normalizeIntValue(&o.MinVectoredPayloadSize, defaultMinVectoredPayloadSize, minMinVectoredPayloadSize, maxMinVectoredPayloadSize)
 	})
 
 	return o
@@ -74,6 +86,18 @@ const (
 	maxMaxPacketSize     = 1 << 30
 )
 
+const (
+	defaultMinCompressiblePayloadSize = 1 << 10
+	minMinCompressiblePayloadSize     = 1 << 6
+	maxMinCompressiblePayloadSize     = 1 << 30
+)
+
+const (
+	defaultMinVectoredPayloadSize = 1 << 16
+	minMinVectoredPayloadSize     = 1 << 10
+	maxMinVectoredPayloadSize     = 1 << 30
+)
+
 var dummyLogger = zerolog.Nop()
 
 func normalizeDurValue(value *time.Duration, defaultValue, minValue, maxValue time.Duration) {
@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+type chaCha20Poly1305RecordCrypter struct {
+	decrypter       cipher.AEAD
+	encrypter       cipher.AEAD
+	decryptionNonce [chacha20poly1305.NonceSize]byte
+	encryptionNonce [chacha20poly1305.NonceSize]byte
+}
+
+func (cc *chaCha20Poly1305RecordCrypter) Init(decryptionKey []byte, encryptionKey []byte) (*chaCha20Poly1305RecordCrypter, error) {
+	decrypter, err := chacha20poly1305.New(decryptionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encrypter, err := chacha20poly1305.New(encryptionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cc.decrypter = decrypter
+	cc.encrypter = encrypter
+	return cc, nil
+}
+
+func (cc *chaCha20Poly1305RecordCrypter) RecordOverhead() int {
+	return chacha20poly1305.Overhead
+}
+
+func (cc *chaCha20Poly1305RecordCrypter) DecryptRecord(record []byte, additionalData []byte) error {
+	if _, err := cc.decrypter.Open(record[:0], cc.decryptionNonce[:], record, additionalData); err != nil {
+		return err
+	}
+
+	incrementNonce(&cc.decryptionNonce)
+	return nil
+}
+
+func (cc *chaCha20Poly1305RecordCrypter) EncryptRecord(record []byte, additionalData []byte) {
+	plaintextSize := len(record) - chacha20poly1305.Overhead
+	cc.encrypter.Seal(record[:0], cc.encryptionNonce[:], record[:plaintextSize], additionalData)
+	incrementNonce(&cc.encryptionNonce)
+}
+
+// x25519Handshaker prepends an ephemeral X25519 public key to the handshake payload.
+// The exchange is unauthenticated: it keeps passive eavesdroppers out, but not an active
+// man in the middle, against which the peer has to be authenticated some other way
+// (e.g. tls or the handshaker).
+type x25519Handshaker struct {
+	underlying    Handshaker
+	privateKey    [curve25519.ScalarSize]byte
+	publicKey     []byte
+	peerPublicKey []byte
+}
+
+var _ = Handshaker((*x25519Handshaker)(nil))
+
+func (xh *x25519Handshaker) Init(underlying Handshaker) (*x25519Handshaker, error) {
+	if _, err := io.ReadFull(rand.Reader, xh.privateKey[:]); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := curve25519.X25519(xh.privateKey[:], curve25519.Basepoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	xh.underlying = underlying
+	xh.publicKey = publicKey
+	return xh, nil
+}
+
+func (xh *x25519Handshaker) HandleHandshake(ctx context.Context, handshakePayload []byte) (bool, error) {
+	if len(handshakePayload) < curve25519.PointSize {
+		return false, ErrBadHandshake
+	}
+
+	xh.peerPublicKey = append([]byte(nil), handshakePayload[:curve25519.PointSize]...)
+	return xh.underlying.HandleHandshake(ctx, handshakePayload[curve25519.PointSize:])
+}
+
+func (xh *x25519Handshaker) SizeHandshake() int {
+	return curve25519.PointSize + xh.underlying.SizeHandshake()
+}
+
+func (xh *x25519Handshaker) EmitHandshake(buffer []byte) error {
+	copy(buffer, xh.publicKey)
+	return xh.underlying.EmitHandshake(buffer[curve25519.PointSize:])
+}
+
+func (xh *x25519Handshaker) NewRecordCrypter(isServerSide bool, handshakeTranscript []byte) (*chaCha20Poly1305RecordCrypter, error) {
+	sharedSecret, err := curve25519.X25519(xh.privateKey[:], xh.peerPublicKey)
+
+	if err != nil {
+		return nil, ErrBadHandshake
+	}
+
+	// the transcript covers both handshakes in full (headers, public keys and the upper
+	// layer's payload), so tampering with any of them leaves the two sides with different keys.
+	keys := hkdf.New(sha256.New, sharedSecret, handshakeTranscript, []byte("gogorpc record keys"))
+	var clientKey, serverKey [chacha20poly1305.KeySize]byte
+
+	if _, err := io.ReadFull(keys, clientKey[:]); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(keys, serverKey[:]); err != nil {
+		return nil, err
+	}
+
+	if isServerSide {
+		return new(chaCha20Poly1305RecordCrypter).Init(clientKey[:], serverKey[:])
+	}
+
+	return new(chaCha20Poly1305RecordCrypter).Init(serverKey[:], clientKey[:])
+}
+
+func incrementNonce(nonce *[chacha20poly1305.NonceSize]byte) {
+	counter := nonce[chacha20poly1305.NonceSize-8:]
+	binary.BigEndian.PutUint64(counter, binary.BigEndian.Uint64(counter)+1)
+}
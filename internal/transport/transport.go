@@ -2,10 +2,14 @@ package transport
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/let-z-go/toolkit/bytestream"
@@ -21,10 +25,23 @@ type Transport struct {
 	isServerSide          bool
 	id                    uuid.UUID
 	connection            connection.Connection
-	inputByteStream       bytestream.ByteStream
-	outputByteStream      bytestream.ByteStream
+	inputByteStream       *bytestream.ByteStream
+	outputByteStream      *bytestream.ByteStream
+	inputBufferSize       int
+	outputBufferSize      int
+	trafficHead           [8]byte
 	maxIncomingPacketSize int
 	maxOutgoingPacketSize int
+	packetChecksumSize    int
+	handshakeTranscript   hash.Hash
+	recordCrypter         *chaCha20Poly1305RecordCrypter
+	compressor            Compressor
+	payloadBuffer         []byte
+	compressedPayload     []byte
+	externalPayloads      []externalPayload
+	encryptionBuffer      []byte
+	auxiliaryBufferSize   int
+	inputPins             *inputBufferPins
 	peekedTrafficSize     int
 }
 
@@ -44,6 +61,16 @@ func (t *Transport) Close() error {
 }
 
 func (t *Transport) Establish(ctx context.Context, connection net.Conn, handshaker Handshaker) (bool, error) {
+	ok, err := t.establish(ctx, connection, handshaker)
+
+	if err != nil || !ok {
+		t.ReleaseBuffers()
+	}
+
+	return ok, err
+}
+
+func (t *Transport) establish(ctx context.Context, connection net.Conn, handshaker Handshaker) (bool, error) {
 	var doEstablish func(*Transport, context.Context, net.Conn, Handshaker) (bool, error)
 
 	if t.isServerSide {
@@ -52,23 +79,69 @@ func (t *Transport) Establish(ctx context.Context, connection net.Conn, handshak
 		doEstablish = (*Transport).postConnect
 	}
 
-	return doEstablish(t, ctx, connection, handshaker)
+	if !t.options.EnableRecordEncryption {
+		return doEstablish(t, ctx, connection, handshaker)
+	}
+
+	x25519Handshaker_, err := new(x25519Handshaker).Init(handshaker)
+
+	if err != nil {
+		connection.Close()
+		return false, err
+	}
+
+	t.handshakeTranscript = sha256.New()
+	ok, err := doEstablish(t, ctx, connection, x25519Handshaker_)
+	handshakeTranscript := t.handshakeTranscript.Sum(nil)
+	t.handshakeTranscript = nil
+
+	if err != nil {
+		return false, err
+	}
+
+	recordCrypter, err := x25519Handshaker_.NewRecordCrypter(t.isServerSide, handshakeTranscript)
+
+	if err != nil {
+		t.connection.Close()
+		return false, err
+	}
+
+	t.recordCrypter = recordCrypter
+	return ok, nil
 }
 
 func (t *Transport) Prepare(trafficDecrypter TrafficDecrypter) {
+	if t.inputByteStream == nil {
+		return
+	}
+
 	if traffic := t.inputByteStream.GetData(); len(traffic) >= 1 {
 		trafficDecrypter.DecryptTraffic(traffic)
 	}
 }
 
 func (t *Transport) Peek(ctx context.Context, timeout time.Duration, trafficDecrypter TrafficDecrypter, packet *Packet) error {
-	traffic := t.inputByteStream.GetData()
 	connectionIsPreRead := false
 
-	if trafficSize := len(traffic); trafficSize < 8 {
+	if t.inputByteStream == nil || t.inputByteStream.GetDataSize() == 0 {
 		t.connection.PreRead(ctx, makeDeadline(timeout))
 		connectionIsPreRead = true
 
+		if err := t.awaitTraffic(ctx, trafficDecrypter); err != nil {
+			return err
+		}
+	} else if err := t.acquireInputBuffer(ctx); err != nil {
+		return err
+	}
+
+	traffic := t.inputByteStream.GetData()
+
+	if trafficSize := len(traffic); trafficSize < 8 {
+		if !connectionIsPreRead {
+			t.connection.PreRead(ctx, makeDeadline(timeout))
+			connectionIsPreRead = true
+		}
+
 		for {
 			n, err := t.connection.DoRead(ctx, t.inputByteStream.GetBuffer())
 
@@ -89,7 +162,7 @@ func (t *Transport) Peek(ctx context.Context, timeout time.Duration, trafficDecr
 
 	packetSize := int(int32(binary.BigEndian.Uint32(traffic)))
 
-	if packetSize < 8 {
+	if packetSize < 8+t.packetChecksumSize+t.recordOverhead() {
 		return ErrBadPacket
 	}
 
@@ -100,6 +173,10 @@ func (t *Transport) Peek(ctx context.Context, timeout time.Duration, trafficDecr
 	if trafficSize := len(traffic); trafficSize < packetSize {
 		t.inputByteStream.ReserveBuffer(packetSize - trafficSize)
 
+		if err := t.acquireInputBuffer(ctx); err != nil {
+			return err
+		}
+
 		if !connectionIsPreRead {
 			t.connection.PreRead(ctx, makeDeadline(timeout))
 		}
@@ -123,22 +200,8 @@ func (t *Transport) Peek(ctx context.Context, timeout time.Duration, trafficDecr
 	}
 
 	rawPacket := traffic[:packetSize]
-	packetHeaderSize := int(int32(binary.BigEndian.Uint32(rawPacket[4:])))
-	packetPayloadOffset := 8 + packetHeaderSize
-
-	if packetPayloadOffset < 8 || packetPayloadOffset > packetSize {
-		return ErrBadPacket
-	}
-
-	packet.Header.Reset()
-
-	if packet.Header.Unmarshal(rawPacket[8:packetPayloadOffset]) != nil {
-		return ErrBadPacket
-	}
-
-	packet.Payload = rawPacket[packetPayloadOffset:]
 	t.peekedTrafficSize += packetSize
-	return nil
+	return t.loadPacket(rawPacket, packet)
 }
 
 func (t *Transport) PeekNext(packet *Packet) (bool, error) {
@@ -152,7 +215,7 @@ func (t *Transport) PeekNext(packet *Packet) (bool, error) {
 
 	packetSize := int(int32(binary.BigEndian.Uint32(traffic)))
 
-	if packetSize < 8 {
+	if packetSize < 8+t.packetChecksumSize+t.recordOverhead() {
 		return false, ErrBadPacket
 	}
 
@@ -166,41 +229,133 @@ func (t *Transport) PeekNext(packet *Packet) (bool, error) {
 	}
 
 	rawPacket := traffic[:packetSize]
-	packetHeaderSize := int(int32(binary.BigEndian.Uint32(rawPacket[4:])))
-	packetPayloadOffset := 8 + packetHeaderSize
-
-	if packetPayloadOffset < 8 || packetPayloadOffset > packetSize {
-		return false, ErrBadPacket
-	}
-
-	packet.Header.Reset()
+	t.peekedTrafficSize += packetSize
 
-	if packet.Header.Unmarshal(rawPacket[8:packetPayloadOffset]) != nil {
-		return false, ErrBadPacket
+	if err := t.loadPacket(rawPacket, packet); err != nil {
+		return false, err
 	}
 
-	packet.Payload = rawPacket[packetPayloadOffset:]
-	t.peekedTrafficSize += packetSize
 	return true, nil
 }
 
 func (t *Transport) ShrinkInputBuffer() {
+	if t.inputByteStream == nil || t.inputBufferIsPinned() {
+		return
+	}
+
 	t.inputByteStream.Shrink(t.options.MinInputBufferSize)
+	t.accountInputBuffer()
+}
+
+// PinInputBuffer keeps the peeked payloads valid until the returned function is called.
+func (t *Transport) PinInputBuffer() func() {
+	if t.inputPins == nil {
+		t.inputPins = &inputBufferPins{count: 1}
+	}
+
+	atomic.AddInt32(&t.inputPins.count, 1)
+	return t.inputPins.Unpin
 }
 
 func (t *Transport) Write(packet *Packet, callback func([]byte) error) error {
-	packetHeaderSize := packet.Header.Size()
-	packetSize := 8 + packetHeaderSize + packet.PayloadSize
+	if externalPayloadSize := len(packet.ExternalPayload); externalPayloadSize >= 1 {
+		// compression and record encryption both need the payload in one piece.
+		if externalPayloadSize >= t.options.MinVectoredPayloadSize && t.compressor == nil && t.recordCrypter == nil {
+			return t.writeVectored(packet, callback)
+		}
+
+		flatPacket := *packet
+		flatPacket.PayloadSize += externalPayloadSize
+		flatPacket.ExternalPayload = nil
+
+		return t.Write(&flatPacket, func(buffer []byte) error {
+			if err := callback(buffer[:packet.PayloadSize]); err != nil {
+				return err
+			}
+
+			copy(buffer[packet.PayloadSize:], packet.ExternalPayload)
+			return nil
+		})
+	}
+
+	if t.compressor != nil && packet.PayloadSize >= t.options.MinCompressiblePayloadSize {
+		return t.writeCompressed(packet, callback)
+	}
+
+	return t.write(&packet.Header, packet.PayloadSize, callback)
+}
+
+func (t *Transport) writeCompressed(packet *Packet, callback func([]byte) error) error {
+	packetHeader := packet.Header
+	packetHeader.IsCompressed = true
+
+	if 8+packetHeader.Size()+packet.PayloadSize > t.maxOutgoingPacketSize {
+		return ErrPacketTooLarge
+	}
+
+	if cap(t.payloadBuffer) < packet.PayloadSize {
+		t.payloadBuffer = make([]byte, packet.PayloadSize)
+	}
+
+	payload := t.payloadBuffer[:packet.PayloadSize]
+
+	if err := callback(payload); err != nil {
+		return err
+	}
+
+	compressedPayload, err := t.compressor.Compress(t.compressedPayload, payload)
+
+	if err != nil {
+		return err
+	}
+
+	t.compressedPayload = compressedPayload
+	t.accountAuxiliaryBuffers()
+
+	if len(compressedPayload) < len(payload) {
+		payload = compressedPayload
+	} else {
+		packetHeader.IsCompressed = false
+	}
+
+	return t.write(&packetHeader, len(payload), func(buffer []byte) error {
+		copy(buffer, payload)
+		return nil
+	})
+}
+
+func (t *Transport) write(packetHeader *proto.PacketHeader, packetPayloadSize int, callback func([]byte) error) error {
+	packetHeaderSize := packetHeader.Size()
+	packetPayloadOffset := 8 + packetHeaderSize
+	packetChecksumOffset := packetPayloadOffset + packetPayloadSize
+	recordTagOffset := packetChecksumOffset + t.packetChecksumSize
+	packetSize := recordTagOffset + t.recordOverhead()
 
 	if packetSize > t.maxOutgoingPacketSize {
 		return ErrPacketTooLarge
 	}
 
+	t.borrowOutputByteStream()
+
 	if err := t.outputByteStream.WriteDirectly(packetSize, func(buffer []byte) error {
 		binary.BigEndian.PutUint32(buffer, uint32(packetSize))
 		binary.BigEndian.PutUint32(buffer[4:], uint32(packetHeaderSize))
-		packet.Header.MarshalTo(buffer[8:])
-		return callback(buffer[8+packetHeaderSize:])
+		packetHeader.MarshalTo(buffer[8:])
+
+		if err := callback(buffer[packetPayloadOffset:packetChecksumOffset]); err != nil {
+			return err
+		}
+
+		if t.packetChecksumSize >= 1 {
+			packetChecksum := crc32.Checksum(buffer[:packetChecksumOffset], crc32cTable)
+			binary.BigEndian.PutUint32(buffer[packetChecksumOffset:], packetChecksum)
+		}
+
+		if t.recordCrypter != nil {
+			t.recordCrypter.EncryptRecord(buffer[4:packetSize], buffer[:4])
+		}
+
+		return nil
 	}); err != nil {
 		return err
 	}
@@ -208,7 +363,68 @@ func (t *Transport) Write(packet *Packet, callback func([]byte) error) error {
 	return nil
 }
 
+func (t *Transport) writeVectored(packet *Packet, callback func([]byte) error) error {
+	packetHeader := &packet.Header
+	packetHeaderSize := packetHeader.Size()
+	packetPayloadOffset := 8 + packetHeaderSize
+	externalPayloadOffset := packetPayloadOffset + packet.PayloadSize
+	packetChecksumOffset := externalPayloadOffset + len(packet.ExternalPayload)
+	packetSize := packetChecksumOffset + t.packetChecksumSize
+
+	if packetSize > t.maxOutgoingPacketSize {
+		return ErrPacketTooLarge
+	}
+
+	t.borrowOutputByteStream()
+	var packetChecksum uint32
+
+	if err := t.outputByteStream.WriteDirectly(externalPayloadOffset, func(buffer []byte) error {
+		binary.BigEndian.PutUint32(buffer, uint32(packetSize))
+		binary.BigEndian.PutUint32(buffer[4:], uint32(packetHeaderSize))
+		packetHeader.MarshalTo(buffer[8:])
+
+		if err := callback(buffer[packetPayloadOffset:]); err != nil {
+			return err
+		}
+
+		if t.packetChecksumSize >= 1 {
+			packetChecksum = crc32.Checksum(buffer, crc32cTable)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	t.externalPayloads = append(t.externalPayloads, externalPayload{
+		TrafficOffset: t.outputByteStream.GetDataSize(),
+		Data:          packet.ExternalPayload,
+	})
+
+	if t.packetChecksumSize >= 1 {
+		packetChecksum = crc32.Update(packetChecksum, crc32cTable, packet.ExternalPayload)
+
+		t.outputByteStream.WriteDirectly(4, func(buffer []byte) error {
+			binary.BigEndian.PutUint32(buffer, packetChecksum)
+			return nil
+		})
+	}
+
+	return nil
+}
+
 func (t *Transport) Flush(ctx context.Context, timeout time.Duration, trafficEncrypter TrafficEncrypter) error {
+	if t.outputByteStream == nil {
+		return nil
+	}
+
+	t.accountOutputBuffer()
+	defer t.releaseOutputByteStream()
+
+	if len(t.externalPayloads) >= 1 {
+		return t.flushVectored(ctx, timeout, trafficEncrypter)
+	}
+
 	traffic := t.outputByteStream.GetData()
 	trafficEncrypter.EncryptTraffic(traffic)
 	_, err := t.connection.Write(ctx, makeDeadline(timeout), traffic)
@@ -221,8 +437,103 @@ func (t *Transport) Flush(ctx context.Context, timeout time.Duration, trafficEnc
 	return nil
 }
 
+func (t *Transport) flushVectored(ctx context.Context, timeout time.Duration, trafficEncrypter TrafficEncrypter) error {
+	traffic := t.outputByteStream.GetData()
+	buffers := make(net.Buffers, 0, 2*len(t.externalPayloads)+1)
+	trafficOffset := 0
+
+	for i := range t.externalPayloads {
+		externalPayload_ := &t.externalPayloads[i]
+		buffers = append(buffers, traffic[trafficOffset:externalPayload_.TrafficOffset], externalPayload_.Data)
+		trafficOffset = externalPayload_.TrafficOffset
+		// drop the reference so that the caller's memory is not held on to.
+		*externalPayload_ = externalPayload{}
+	}
+
+	buffers = append(buffers, traffic[trafficOffset:])
+	t.externalPayloads = t.externalPayloads[:0]
+	deadline := makeDeadline(timeout)
+	var err error
+
+	if isDummyTrafficEncrypter(trafficEncrypter) {
+		err = t.writeBuffers(ctx, deadline, buffers)
+	} else {
+		err = t.writeEncryptedBuffers(ctx, deadline, buffers, trafficEncrypter)
+	}
+
+	t.outputByteStream.Skip(len(traffic))
+
+	if err != nil {
+		return &NetworkError{err}
+	}
+
+	return nil
+}
+
+func (t *Transport) writeBuffers(ctx context.Context, deadline time.Time, buffers net.Buffers) error {
+	for _, buffer := range buffers {
+		if len(buffer) == 0 {
+			continue
+		}
+
+		if _, err := t.connection.Write(ctx, deadline, buffer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Transport) writeEncryptedBuffers(ctx context.Context, deadline time.Time, buffers net.Buffers, trafficEncrypter TrafficEncrypter) error {
+	for i, buffer := range buffers {
+		if i%2 == 0 {
+			// our own traffic, which can be encrypted in place.
+			trafficEncrypter.EncryptTraffic(buffer)
+
+			if _, err := t.connection.Write(ctx, deadline, buffer); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// external payloads belong to the caller and must be left untouched.
+		if t.encryptionBuffer == nil {
+			t.encryptionBuffer = make([]byte, encryptionBufferSize)
+			t.accountAuxiliaryBuffers()
+		}
+
+		for len(buffer) >= 1 {
+			n := copy(t.encryptionBuffer, buffer)
+			buffer = buffer[n:]
+			trafficEncrypter.EncryptTraffic(t.encryptionBuffer[:n])
+
+			if _, err := t.connection.Write(ctx, deadline, t.encryptionBuffer[:n]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ShrinkOutputBuffer drops the auxiliary buffers, the output byte stream goes back to the buffer pool on flushing.
 func (t *Transport) ShrinkOutputBuffer() {
-	t.outputByteStream.Shrink(0)
+	t.payloadBuffer = nil
+	t.compressedPayload = nil
+	t.encryptionBuffer = nil
+	t.accountAuxiliaryBuffers()
+}
+
+// ReleaseBuffers gives all the buffers back to the buffer pool once the transport is done with.
+func (t *Transport) ReleaseBuffers() {
+	t.releaseInputByteStream()
+	t.releaseOutputByteStream()
+	t.ShrinkOutputBuffer()
+}
+
+func (t *Transport) IsPacketChecksumEnabled() bool {
+	return t.packetChecksumSize >= 1
 }
 
 func (t *Transport) IsServerSide() bool {
@@ -242,7 +553,6 @@ func (t *Transport) postAccept(ctx context.Context, connection net.Conn, handsha
 		Int("max_input_buffer_size", t.options.MaxInputBufferSize).
 		Msg("transport_post_accepting")
 	t.connection.Init(connection)
-	t.inputByteStream.ReserveBuffer(t.options.MinInputBufferSize)
 	deadline := makeDeadline(t.options.HandshakeTimeout)
 	var handshakeHeader proto.TransportHandshakeHeader
 
@@ -271,6 +581,12 @@ func (t *Transport) postAccept(ctx context.Context, connection net.Conn, handsha
 		handshakeHeader.MaxOutgoingPacketSize = int32(t.options.MaxIncomingPacketSize)
 	}
 
+	if t.options.EnablePacketChecksum {
+		handshakeHeader.IsPacketChecksumEnabled = true
+	}
+
+	handshakeHeader.CompressionAlgorithms = t.selectCompressionAlgorithms(handshakeHeader.CompressionAlgorithms)
+
 	t.maxIncomingPacketSize = int(handshakeHeader.MaxOutgoingPacketSize)
 	t.maxOutgoingPacketSize = int(handshakeHeader.MaxIncomingPacketSize)
 
@@ -286,6 +602,8 @@ func (t *Transport) postAccept(ctx context.Context, connection net.Conn, handsha
 		return false, err
 	}
 
+	t.setPacketChecksum(handshakeHeader.IsPacketChecksumEnabled)
+	t.setCompressor(handshakeHeader.CompressionAlgorithms)
 	return ok, nil
 }
 
@@ -298,7 +616,6 @@ func (t *Transport) postConnect(ctx context.Context, connection net.Conn, handsh
 		Int("max_input_buffer_size", t.options.MaxInputBufferSize).
 		Msg("transport_post_connecting")
 	t.connection.Init(connection)
-	t.inputByteStream.ReserveBuffer(t.options.MinInputBufferSize)
 	deadline := makeDeadline(t.options.HandshakeTimeout)
 
 	handshakeHeader := proto.TransportHandshakeHeader{
@@ -307,8 +624,11 @@ func (t *Transport) postConnect(ctx context.Context, connection net.Conn, handsh
 			High: t.id[1],
 		},
 
-		MaxIncomingPacketSize: int32(t.options.MaxIncomingPacketSize),
-		MaxOutgoingPacketSize: int32(t.options.MaxOutgoingPacketSize),
+		MaxIncomingPacketSize:     int32(t.options.MaxIncomingPacketSize),
+		MaxOutgoingPacketSize:     int32(t.options.MaxOutgoingPacketSize),
+		IsPacketChecksumEnabled:   t.options.EnablePacketChecksum,
+		IsRecordEncryptionEnabled: t.options.EnableRecordEncryption,
+		CompressionAlgorithms:     t.options.CompressionAlgorithms,
 	}
 
 	if err := t.sendHandshake(
@@ -336,8 +656,20 @@ func (t *Transport) postConnect(ctx context.Context, connection net.Conn, handsh
 		return false, err
 	}
 
+	if len(handshakeHeader.CompressionAlgorithms) >= 2 {
+		t.connection.Close()
+		return false, ErrBadHandshake
+	}
+
+	if len(t.selectCompressionAlgorithms(handshakeHeader.CompressionAlgorithms)) != len(handshakeHeader.CompressionAlgorithms) {
+		t.connection.Close()
+		return false, ErrBadHandshake
+	}
+
 	t.maxIncomingPacketSize = int(handshakeHeader.MaxIncomingPacketSize)
 	t.maxOutgoingPacketSize = int(handshakeHeader.MaxOutgoingPacketSize)
+	t.setPacketChecksum(handshakeHeader.IsPacketChecksumEnabled)
+	t.setCompressor(handshakeHeader.CompressionAlgorithms)
 	return ok, nil
 }
 
@@ -350,7 +682,11 @@ func (t *Transport) receiveHandshake(
 ) (bool, error) {
 	t.connection.PreRead(ctx, deadline)
 
-	for {
+	if err := t.awaitTraffic(ctx, DummyTrafficDecrypter{}); err != nil {
+		return false, err
+	}
+
+	for t.inputByteStream.GetDataSize() < 8 {
 		n, err := t.connection.DoRead(ctx, t.inputByteStream.GetBuffer())
 
 		if err != nil {
@@ -358,10 +694,6 @@ func (t *Transport) receiveHandshake(
 		}
 
 		t.inputByteStream.CommitBuffer(n)
-
-		if t.inputByteStream.GetDataSize() >= 8 {
-			break
-		}
 	}
 
 	traffic := t.inputByteStream.GetData()
@@ -378,6 +710,10 @@ func (t *Transport) receiveHandshake(
 	if trafficSize := len(traffic); trafficSize < handshakeSize {
 		t.inputByteStream.ReserveBuffer(handshakeSize - trafficSize)
 
+		if err := t.acquireInputBuffer(ctx); err != nil {
+			return false, err
+		}
+
 		for {
 			n, err := t.connection.DoRead(ctx, t.inputByteStream.GetBuffer())
 
@@ -396,6 +732,11 @@ func (t *Transport) receiveHandshake(
 	}
 
 	rawHandshake := traffic[:handshakeSize]
+
+	if t.handshakeTranscript != nil {
+		t.handshakeTranscript.Write(rawHandshake)
+	}
+
 	handshakeHeaderSize := int(int32(binary.BigEndian.Uint32(rawHandshake[4:])))
 	handshakePayloadOffset := 8 + handshakeHeaderSize
 
@@ -409,6 +750,10 @@ func (t *Transport) receiveHandshake(
 		return false, ErrBadHandshake
 	}
 
+	if handshakeHeader.IsRecordEncryptionEnabled != t.options.EnableRecordEncryption {
+		return false, ErrBadHandshake
+	}
+
 	var logEvent *zerolog.Event
 
 	if t.isServerSide {
@@ -432,6 +777,9 @@ func (t *Transport) receiveHandshake(
 		Str("id", t.id.String()).
 		Int32("max_incoming_packet_size", handshakeHeader.MaxIncomingPacketSize).
 		Int32("max_outgoing_packet_size", handshakeHeader.MaxOutgoingPacketSize).
+		Bool("is_packet_checksum_enabled", handshakeHeader.IsPacketChecksumEnabled).
+		Bool("is_record_encryption_enabled", handshakeHeader.IsRecordEncryptionEnabled).
+		Strs("compression_algorithms", handshakeHeader.CompressionAlgorithms).
 		Msg("transport_incoming_handshake")
 	ctx, cancel := context.WithDeadline(ctx, deadline)
 	ok, err := handshakeHandler(ctx, rawHandshake[handshakePayloadOffset:])
@@ -473,12 +821,18 @@ func (t *Transport) sendHandshake(
 		Str("id", t.id.String()).
 		Int32("max_incoming_packet_size", handshakeHeader.MaxIncomingPacketSize).
 		Int32("max_outgoing_packet_size", handshakeHeader.MaxOutgoingPacketSize).
+		Bool("is_packet_checksum_enabled", handshakeHeader.IsPacketChecksumEnabled).
+		Bool("is_record_encryption_enabled", handshakeHeader.IsRecordEncryptionEnabled).
+		Strs("compression_algorithms", handshakeHeader.CompressionAlgorithms).
 		Msg("transport_outgoing_handshake")
 
 	if handshakeSize > t.options.MaxHandshakeSize {
 		return ErrHandshakeTooLarge
 	}
 
+	t.borrowOutputByteStream()
+	defer t.releaseOutputByteStream()
+
 	if err := t.outputByteStream.WriteDirectly(handshakeSize, func(buffer []byte) error {
 		binary.BigEndian.PutUint32(buffer, uint32(handshakeSize))
 		binary.BigEndian.PutUint32(buffer[4:], uint32(handshakeHeaderSize))
@@ -488,6 +842,10 @@ func (t *Transport) sendHandshake(
 		return err
 	}
 
+	if t.handshakeTranscript != nil {
+		t.handshakeTranscript.Write(t.outputByteStream.GetData()[:handshakeSize])
+	}
+
 	_, err := t.connection.Write(ctx, deadline, t.outputByteStream.GetData())
 	t.outputByteStream.Skip(handshakeSize)
 
@@ -498,19 +856,297 @@ func (t *Transport) sendHandshake(
 	return nil
 }
 
+func (t *Transport) setPacketChecksum(isEnabled bool) {
+	if isEnabled {
+		t.packetChecksumSize = 4
+	} else {
+		t.packetChecksumSize = 0
+	}
+}
+
+func (t *Transport) selectCompressionAlgorithms(peerCompressionAlgorithms []string) []string {
+	for _, peerCompressionAlgorithm := range peerCompressionAlgorithms {
+		for _, compressionAlgorithm := range t.options.CompressionAlgorithms {
+			if compressionAlgorithm != peerCompressionAlgorithm {
+				continue
+			}
+
+			if _, err := GetCompressor(compressionAlgorithm); err != nil {
+				continue
+			}
+
+			return []string{compressionAlgorithm}
+		}
+	}
+
+	return nil
+}
+
+func (t *Transport) setCompressor(compressionAlgorithms []string) {
+	if len(compressionAlgorithms) == 0 {
+		t.compressor = nil
+		return
+	}
+
+	t.compressor, _ = GetCompressor(compressionAlgorithms[0])
+}
+
+func (t *Transport) recordOverhead() int {
+	if t.recordCrypter == nil {
+		return 0
+	}
+
+	return t.recordCrypter.RecordOverhead()
+}
+
+func (t *Transport) loadPacket(rawPacket []byte, packet *Packet) error {
+	packetSize := len(rawPacket)
+
+	if t.recordCrypter != nil {
+		if t.recordCrypter.DecryptRecord(rawPacket[4:], rawPacket[:4]) != nil {
+			return ErrBadRecord
+		}
+
+		packetSize -= t.recordCrypter.RecordOverhead()
+		rawPacket = rawPacket[:packetSize]
+	}
+
+	if t.packetChecksumSize >= 1 {
+		packetChecksumOffset := packetSize - t.packetChecksumSize
+		packetChecksum := binary.BigEndian.Uint32(rawPacket[packetChecksumOffset:])
+
+		if crc32.Checksum(rawPacket[:packetChecksumOffset], crc32cTable) != packetChecksum {
+			return ErrBadChecksum
+		}
+
+		rawPacket = rawPacket[:packetChecksumOffset]
+		packetSize = packetChecksumOffset
+	}
+
+	packetHeaderSize := int(int32(binary.BigEndian.Uint32(rawPacket[4:])))
+	packetPayloadOffset := 8 + packetHeaderSize
+
+	if packetPayloadOffset < 8 || packetPayloadOffset > packetSize {
+		return ErrBadPacket
+	}
+
+	packet.Header.Reset()
+
+	if packet.Header.Unmarshal(rawPacket[8:packetPayloadOffset]) != nil {
+		return ErrBadPacket
+	}
+
+	packetPayload := rawPacket[packetPayloadOffset:]
+
+	if packet.Header.IsCompressed {
+		if t.compressor == nil {
+			return ErrBadPacket
+		}
+
+		var err error
+		packetPayload, err = t.compressor.Decompress(nil, packetPayload, t.maxIncomingPacketSize-packetPayloadOffset)
+
+		if err != nil {
+			if err == ErrPacketTooLarge {
+				return err
+			}
+
+			return ErrBadPacket
+		}
+	}
+
+	packet.Payload = packetPayload
+	return nil
+}
+
 func (t *Transport) skip() {
 	bufferIsInsufficient := t.inputByteStream.GetBufferSize() == 0
+
+	if t.inputBufferIsPinned() {
+		t.detachInputBuffer(bufferIsInsufficient)
+		return
+	}
+
 	t.inputByteStream.Skip(t.peekedTrafficSize)
 
 	if bufferIsInsufficient {
-		if t.inputByteStream.Size() < t.options.MaxInputBufferSize {
+		bufferSize := t.inputByteStream.Size()
+
+		// growing is not a must, so it does not wait for the memory budget.
+		if bufferSize < t.options.MaxInputBufferSize && t.options.BufferPool.tryAcquireMemory(bufferSize) {
 			t.inputByteStream.Expand()
+			t.inputBufferSize += bufferSize
+			t.accountInputBuffer()
 		}
 	}
 
 	t.peekedTrafficSize = 0
 }
 
+func (t *Transport) inputBufferIsPinned() bool {
+	return t.inputPins != nil && t.inputPins.IsPinned()
+}
+
+func (t *Transport) detachInputBuffer(bufferIsInsufficient bool) {
+	// the pinned buffer is left to its borrowers and reading carries on in a new one.
+	traffic := t.inputByteStream.GetData()[t.peekedTrafficSize:]
+	bufferSize := t.inputByteStream.Size()
+	acquiredMemory := 0
+
+	// growing is not a must, so it does not wait for the memory budget.
+	if bufferIsInsufficient && bufferSize < t.options.MaxInputBufferSize && t.options.BufferPool.tryAcquireMemory(2*bufferSize) {
+		bufferSize *= 2
+		acquiredMemory = bufferSize
+	}
+
+	byteStream := t.options.BufferPool.getByteStream(bufferSize)
+
+	byteStream.WriteDirectly(len(traffic), func(buffer []byte) error {
+		copy(buffer, traffic)
+		return nil
+	})
+
+	t.releaseInputByteStream()
+	// the rest is accounted on the next peek, before reading into it.
+	t.inputByteStream = byteStream
+	t.inputBufferSize = acquiredMemory
+	t.peekedTrafficSize = 0
+}
+
+// awaitTraffic waits for traffic without holding a buffer, so that idle transports cost little memory.
+func (t *Transport) awaitTraffic(ctx context.Context, trafficDecrypter TrafficDecrypter) error {
+	t.releaseInputByteStream()
+	n, err := t.connection.DoRead(ctx, t.trafficHead[:])
+
+	if err != nil {
+		return &NetworkError{err}
+	}
+
+	if err := t.borrowInputByteStream(ctx); err != nil {
+		return err
+	}
+
+	trafficHead := t.trafficHead[:n]
+	trafficDecrypter.DecryptTraffic(trafficHead)
+
+	t.inputByteStream.WriteDirectly(n, func(buffer []byte) error {
+		copy(buffer, trafficHead)
+		return nil
+	})
+
+	return nil
+}
+
+func (t *Transport) borrowInputByteStream(ctx context.Context) error {
+	t.inputByteStream = t.options.BufferPool.getByteStream(t.options.MinInputBufferSize)
+	return t.acquireInputBuffer(ctx)
+}
+
+// acquireInputBuffer accounts the growth of the input buffer, holding back reading into it
+// until the memory budget allows, which is how the budget pushes back.
+func (t *Transport) acquireInputBuffer(ctx context.Context) error {
+	bufferSize := t.inputByteStream.Size()
+
+	if memoryBudget := t.options.BufferPool.MemoryBudget(); memoryBudget >= 1 && bufferSize > memoryBudget {
+		return ErrOutOfMemoryBudget
+	}
+
+	if delta := bufferSize - t.inputBufferSize; delta >= 1 {
+		if err := t.options.BufferPool.acquireMemory(ctx, delta); err != nil {
+			return err
+		}
+	} else {
+		t.options.BufferPool.releaseMemory(-delta)
+	}
+
+	t.inputBufferSize = bufferSize
+	return nil
+}
+
+func (t *Transport) accountInputBuffer() {
+	t.inputBufferSize = t.accountMemory(t.inputBufferSize, t.inputByteStream.Size())
+}
+
+func (t *Transport) releaseInputByteStream() {
+	if t.inputByteStream == nil {
+		return
+	}
+
+	if t.inputPins == nil {
+		t.options.BufferPool.putByteStream(t.inputByteStream)
+		t.options.BufferPool.releaseMemory(t.inputBufferSize)
+	} else {
+		// borrowers may still hold on to the buffer.
+		t.inputPins.Detach(t.options.BufferPool, t.inputByteStream, t.inputBufferSize)
+		t.inputPins = nil
+	}
+
+	t.inputByteStream = nil
+	t.inputBufferSize = 0
+}
+
+// BorrowOutputBuffer takes the output buffer for the following writes once the memory budget
+// allows, so that writing is held back while the budget is used up.
+func (t *Transport) BorrowOutputBuffer(ctx context.Context) error {
+	if t.outputByteStream != nil {
+		return nil
+	}
+
+	if err := t.options.BufferPool.acquireMemory(ctx, minOutputBufferSize); err != nil {
+		return err
+	}
+
+	t.outputByteStream = t.options.BufferPool.getByteStream(minOutputBufferSize)
+	t.outputBufferSize = minOutputBufferSize
+	t.accountOutputBuffer()
+	return nil
+}
+
+func (t *Transport) borrowOutputByteStream() {
+	if t.outputByteStream != nil {
+		return
+	}
+
+	// writes without a borrowed buffer are not held back, as flushing is what gives the memory back.
+	t.outputByteStream = t.options.BufferPool.getByteStream(minOutputBufferSize)
+	t.accountOutputBuffer()
+}
+
+func (t *Transport) accountOutputBuffer() {
+	t.outputBufferSize = t.accountMemory(t.outputBufferSize, t.outputByteStream.Size())
+}
+
+func (t *Transport) accountAuxiliaryBuffers() {
+	auxiliaryBufferSize := cap(t.payloadBuffer) + cap(t.compressedPayload) + cap(t.encryptionBuffer)
+	t.auxiliaryBufferSize = t.accountMemory(t.auxiliaryBufferSize, auxiliaryBufferSize)
+}
+
+func (t *Transport) releaseOutputByteStream() {
+	if t.outputByteStream == nil {
+		return
+	}
+
+	for i := range t.externalPayloads {
+		t.externalPayloads[i] = externalPayload{}
+	}
+
+	t.externalPayloads = t.externalPayloads[:0]
+	t.options.BufferPool.putByteStream(t.outputByteStream)
+	t.options.BufferPool.releaseMemory(t.outputBufferSize)
+	t.outputByteStream = nil
+	t.outputBufferSize = 0
+}
+
+func (t *Transport) accountMemory(oldSize int, newSize int) int {
+	if delta := newSize - oldSize; delta >= 1 {
+		t.options.BufferPool.forceAcquireMemory(delta)
+	} else {
+		t.options.BufferPool.releaseMemory(-delta)
+	}
+
+	return newSize
+}
+
 type Handshaker interface {
 	HandleHandshake(ctx context.Context, handshakePayload []byte) (ok bool, err error)
 	SizeHandshake() (handshakSize int)
@@ -518,9 +1154,10 @@ type Handshaker interface {
 }
 
 type Packet struct {
-	Header      proto.PacketHeader
-	Payload     []byte // only for peeking
-	PayloadSize int    // only for writing
+	Header          proto.PacketHeader
+	Payload         []byte // only for peeking
+	PayloadSize     int    // only for writing
+	ExternalPayload []byte // only for writing, follows the payload and must stay untouched until flushed
 }
 
 type NetworkError struct {
@@ -536,8 +1173,32 @@ var (
 	ErrBadHandshake      = errors.New("gogorpc/transport: bad handshake")
 	ErrPacketTooLarge    = errors.New("gogorpc/transport: packet too large")
 	ErrBadPacket         = errors.New("gogorpc/transport: bad packet")
+	ErrBadChecksum       = errors.New("gogorpc/transport: bad checksum")
+	ErrBadRecord         = errors.New("gogorpc/transport: bad record")
+	ErrOutOfMemoryBudget = errors.New("gogorpc/transport: out of memory budget")
 )
 
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+type externalPayload struct {
+	TrafficOffset int
+	Data          []byte
+}
+
+const (
+	encryptionBufferSize = 1 << 16
+	minOutputBufferSize  = 1 << 12
+)
+
+func isDummyTrafficEncrypter(trafficEncrypter TrafficEncrypter) bool {
+	switch trafficEncrypter.(type) {
+	case DummyTrafficEncrypter, DummyTrafficCrypter:
+		return true
+	default:
+		return false
+	}
+}
+
 func makeDeadline(timeout time.Duration) time.Time {
 	if timeout < 1 {
 		return time.Time{}
@@ -7,10 +7,12 @@ import (
 	"math"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/let-z-go/toolkit/uuid"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -416,6 +418,469 @@ func TestSendBadPacket2(t *testing.T) {
 	testSetup2(t, &opts1, &opts2, cb2, cb1)
 }
 
+func TestPacketChecksum(t *testing.T) {
+	const N = 3
+	opts1 := Options{EnablePacketChecksum: true}
+	opts2 := Options{}
+	cb1 := func(ctx context.Context, tp *Transport) {
+		assert.True(t, tp.IsPacketChecksumEnabled())
+		for i := 0; i < N; i++ {
+			msg := fmt.Sprintf("this packet %d", i)
+			err := tp.Write(&Packet{
+				Header: proto.PacketHeader{
+					EventType: proto.EVENT_REQUEST,
+				},
+				PayloadSize: len(msg),
+			}, func(buf []byte) error {
+				copy(buf, msg)
+				return nil
+			})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if i == 1 {
+				traffic := tp.outputByteStream.GetData()
+				traffic[len(traffic)-5] ^= 0xFF
+			}
+			err = tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		tp.Close()
+	}
+	cb2 := func(ctx context.Context, tp *Transport) {
+		assert.True(t, tp.IsPacketChecksumEnabled())
+		pk := Packet{}
+		msgs := []string(nil)
+		for len(msgs) < N {
+			err := tp.Peek(ctx, 0, DummyTrafficDecrypter{}, &pk)
+			for {
+				if err == nil {
+					msgs = append(msgs, string(pk.Payload))
+				} else {
+					if !assert.EqualError(t, err, ErrBadChecksum.Error()) {
+						t.FailNow()
+					}
+					msgs = append(msgs, "")
+				}
+				var ok bool
+				ok, err = tp.PeekNext(&pk)
+				if err == nil && !ok {
+					break
+				}
+			}
+		}
+		assert.Equal(t, []string{"this packet 0", "", "this packet 2"}, msgs)
+	}
+	testSetup2(t, &opts1, &opts2, cb1, cb2)
+	testSetup2(t, &opts2, &opts1, cb1, cb2)
+}
+
+func TestRecordEncryption(t *testing.T) {
+	const N = 100
+	opts1 := Options{EnableRecordEncryption: true, EnablePacketChecksum: true}
+	opts2 := Options{EnableRecordEncryption: true}
+	cb1 := func(ctx context.Context, tp *Transport) {
+		for i := 0; i < N; i++ {
+			msg := fmt.Sprintf("this packet %d", i)
+			err := tp.Write(&Packet{
+				Header: proto.PacketHeader{
+					EventType: proto.EVENT_REQUEST,
+				},
+				PayloadSize: len(msg),
+			}, func(buf []byte) error {
+				copy(buf, msg)
+				return nil
+			})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if i%7 == 0 {
+				err = tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+		}
+		err := tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		tp.Close()
+	}
+	cb2 := func(ctx context.Context, tp *Transport) {
+		pk := Packet{}
+		i := 0
+		for i < N {
+			err := tp.Peek(ctx, 0, DummyTrafficDecrypter{}, &pk)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			for {
+				if !assert.Equal(t, fmt.Sprintf("this packet %d", i), string(pk.Payload)) {
+					t.FailNow()
+				}
+				i++
+				ok, err := tp.PeekNext(&pk)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				if !ok {
+					break
+				}
+			}
+		}
+	}
+	testSetup2(t, &opts1, &opts2, cb1, cb2)
+	testSetup2(t, &opts1, &opts2, cb2, cb1)
+}
+
+func TestRecordKeyDerivation(t *testing.T) {
+	hs1, err := new(x25519Handshaker).Init(testHandshaker{}.Init())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	hs2, err := new(x25519Handshaker).Init(testHandshaker{}.Init())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, hs := range [...][2]*x25519Handshaker{{hs1, hs2}, {hs2, hs1}} {
+		buf := make([]byte, hs[0].SizeHandshake())
+		assert.NoError(t, hs[0].EmitHandshake(buf))
+		_, err := hs[1].HandleHandshake(context.Background(), buf)
+		assert.NoError(t, err)
+	}
+	for _, transcript := range [...]string{"the transcript", "a tampered transcript"} {
+		rc1, err := hs1.NewRecordCrypter(false, []byte("the transcript"))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		rc2, err := hs2.NewRecordCrypter(true, []byte(transcript))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		rec := make([]byte, 10+rc1.RecordOverhead())
+		copy(rec, "the record")
+		rc1.EncryptRecord(rec, []byte("ad"))
+		err = rc2.DecryptRecord(rec, []byte("ad"))
+		if transcript == "the transcript" {
+			if assert.NoError(t, err) {
+				assert.Equal(t, "the record", string(rec[:10]))
+			}
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	const N = 10
+	for _, ca := range []string{"flate", "snappy", "zstd"} {
+		opts1 := Options{CompressionAlgorithms: []string{"lz4", ca}}
+		opts2 := Options{CompressionAlgorithms: []string{"flate", "snappy", "zstd"}}
+		cb1 := func(ctx context.Context, tp *Transport) {
+			if !assert.NotNil(t, tp.compressor, ca) {
+				t.FailNow()
+			}
+			for i := 0; i < N; i++ {
+				msg := strings.Repeat(fmt.Sprintf("this packet %d ", i), 1000)
+				err := tp.Write(&Packet{
+					Header: proto.PacketHeader{
+						EventType: proto.EVENT_REQUEST,
+					},
+					PayloadSize: len(msg),
+				}, func(buf []byte) error {
+					copy(buf, msg)
+					return nil
+				})
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			err := tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			tp.Close()
+		}
+		cb2 := func(ctx context.Context, tp *Transport) {
+			pk := Packet{}
+			i := 0
+			for i < N {
+				err := tp.Peek(ctx, 0, DummyTrafficDecrypter{}, &pk)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				for {
+					assert.True(t, pk.Header.IsCompressed)
+					if !assert.Equal(t, strings.Repeat(fmt.Sprintf("this packet %d ", i), 1000), string(pk.Payload)) {
+						t.FailNow()
+					}
+					i++
+					ok, err := tp.PeekNext(&pk)
+					if !assert.NoError(t, err) {
+						t.FailNow()
+					}
+					if !ok {
+						break
+					}
+				}
+			}
+		}
+		testSetup2(t, &opts1, &opts2, cb1, cb2)
+	}
+}
+
+func TestDecompressionLimits(t *testing.T) {
+	const maxSize = 64 * 1024
+	for _, ca := range []string{"flate", "snappy", "zstd"} {
+		c, err := GetCompressor(ca)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		data := []byte(strings.Repeat("x", maxSize))
+		buf, err := c.Compress(nil, data)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		data2, err := c.Decompress(nil, buf, maxSize)
+		if assert.NoError(t, err, ca) {
+			assert.Equal(t, data, data2, ca)
+		}
+		_, err = c.Decompress(nil, buf, maxSize-1)
+		assert.Equal(t, ErrPacketTooLarge, err, ca)
+	}
+	// a frame asking for a window beyond the max size is refused up front:
+	// magic number, 8MiB window, one raw last block of 1 byte.
+	frame := []byte{0x28, 0xB5, 0x2F, 0xFD, 0x00, 0x68, 0x09, 0x00, 0x00, 'x'}
+	c, _ := GetCompressor("zstd")
+	data, err := c.Decompress(nil, frame, 8*1024*1024)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "x", string(data))
+	}
+	_, err = c.Decompress(nil, frame, maxSize)
+	assert.Equal(t, zstd.ErrWindowSizeExceeded, err)
+}
+
+func TestVectoredWrite(t *testing.T) {
+	const N = 10
+	for _, tc := range []TrafficCrypter{DummyTrafficCrypter{}, testTrafficCrypter(0x5A)} {
+		opts1 := Options{EnablePacketChecksum: true}
+		opts2 := Options{}
+		extPayloads := make([][]byte, N)
+		for i := range extPayloads {
+			extPayloads[i] = []byte(strings.Repeat(fmt.Sprintf("%d", i), (i+1)*defaultMinVectoredPayloadSize/8))
+		}
+		cb1 := func(ctx context.Context, tp *Transport) {
+			for i := 0; i < N; i++ {
+				msg := fmt.Sprintf("this packet %d ", i)
+				err := tp.Write(&Packet{
+					Header: proto.PacketHeader{
+						EventType: proto.EVENT_REQUEST,
+					},
+					PayloadSize:     len(msg),
+					ExternalPayload: extPayloads[i],
+				}, func(buf []byte) error {
+					copy(buf, msg)
+					return nil
+				})
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			assert.Len(t, tp.externalPayloads, N-7)
+			err := tp.Flush(ctx, 0, tc)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Len(t, tp.externalPayloads, 0)
+			for i, extPayload := range extPayloads {
+				assert.Equal(t, strings.Repeat(fmt.Sprintf("%d", i), (i+1)*defaultMinVectoredPayloadSize/8), string(extPayload))
+			}
+			tp.Close()
+		}
+		cb2 := func(ctx context.Context, tp *Transport) {
+			pk := Packet{}
+			i := 0
+			for i < N {
+				err := tp.Peek(ctx, 0, tc, &pk)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				for {
+					if !assert.Equal(t, fmt.Sprintf("this packet %d ", i)+string(extPayloads[i]), string(pk.Payload)) {
+						t.FailNow()
+					}
+					i++
+					ok, err := tp.PeekNext(&pk)
+					if !assert.NoError(t, err) {
+						t.FailNow()
+					}
+					if !ok {
+						break
+					}
+				}
+			}
+		}
+		testSetup2(t, &opts1, &opts2, cb1, cb2)
+	}
+}
+
+func TestPinInputBuffer(t *testing.T) {
+	const N = 100
+	opts1 := Options{}
+	opts2 := Options{}
+	cb1 := func(ctx context.Context, tp *Transport) {
+		for i := 0; i < N; i++ {
+			msg := fmt.Sprintf("this packet %d", i)
+			err := tp.Write(&Packet{
+				Header: proto.PacketHeader{
+					EventType: proto.EVENT_REQUEST,
+				},
+				PayloadSize: len(msg),
+			}, func(buf []byte) error {
+				copy(buf, msg)
+				return nil
+			})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			err = tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		tp.Close()
+	}
+	cb2 := func(ctx context.Context, tp *Transport) {
+		pk := Packet{}
+		payloads := [][]byte(nil)
+		unpins := []func(){}
+		for len(payloads) < N {
+			err := tp.Peek(ctx, 0, DummyTrafficDecrypter{}, &pk)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			for {
+				payloads = append(payloads, pk.Payload)
+				unpins = append(unpins, tp.PinInputBuffer())
+				ok, err := tp.PeekNext(&pk)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				if !ok {
+					break
+				}
+			}
+			assert.Nil(t, tp.inputPins)
+		}
+		for i, payload := range payloads {
+			assert.Equal(t, fmt.Sprintf("this packet %d", i), string(payload))
+		}
+		for _, unpin := range unpins {
+			unpin()
+		}
+	}
+	testSetup2(t, &opts1, &opts2, cb1, cb2)
+}
+
+func TestBufferPool(t *testing.T) {
+	bp := new(BufferPool).Init(3 * minOutputBufferSize)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, bp.acquireMemory(ctx, 2*minOutputBufferSize))
+	assert.NoError(t, bp.acquireMemory(ctx, minOutputBufferSize))
+	assert.Equal(t, context.DeadlineExceeded, bp.acquireMemory(ctx, minOutputBufferSize))
+	go bp.releaseMemory(2 * minOutputBufferSize)
+	assert.NoError(t, bp.acquireMemory(context.Background(), minOutputBufferSize))
+	assert.Equal(t, 2*minOutputBufferSize, bp.UsedMemory())
+	bp.releaseMemory(2 * minOutputBufferSize)
+	assert.Equal(t, ErrOutOfMemoryBudget, bp.acquireMemory(context.Background(), 10*minOutputBufferSize))
+	assert.Equal(t, 0, bp.UsedMemory())
+
+	// the later packets are larger than the min input buffer, which has to grow within the budget.
+	const N = 100
+	bp.SetMemoryBudget(1 << 15)
+	opts1 := Options{BufferPool: bp, MinInputBufferSize: 1 << 10}
+	opts2 := Options{BufferPool: bp, MinInputBufferSize: 1 << 10}
+	makeMsg := func(i int) string {
+		return fmt.Sprintf("this packet %d", i) + strings.Repeat(".", i*60)
+	}
+	cb1 := func(ctx context.Context, tp *Transport) {
+		for i := 0; i < N; i++ {
+			msg := makeMsg(i)
+			err := tp.BorrowOutputBuffer(ctx)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			err = tp.Write(&Packet{
+				Header: proto.PacketHeader{
+					EventType: proto.EVENT_REQUEST,
+				},
+				PayloadSize: len(msg),
+			}, func(buf []byte) error {
+				copy(buf, msg)
+				return nil
+			})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			err = tp.Flush(ctx, 0, DummyTrafficEncrypter{})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Nil(t, tp.outputByteStream)
+		}
+		tp.ReleaseBuffers()
+		tp.Close()
+	}
+	cb2 := func(ctx context.Context, tp *Transport) {
+		pk := Packet{}
+		msgs := []string(nil)
+		maxInputBufferSize := 0
+		for len(msgs) < N {
+			err := tp.Peek(ctx, 0, DummyTrafficDecrypter{}, &pk)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			for {
+				msgs = append(msgs, string(pk.Payload))
+				ok, err := tp.PeekNext(&pk)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				if !ok {
+					break
+				}
+			}
+			assert.True(t, bp.UsedMemory() <= bp.MemoryBudget())
+			if tp.inputBufferSize > maxInputBufferSize {
+				maxInputBufferSize = tp.inputBufferSize
+			}
+		}
+		assert.True(t, maxInputBufferSize > opts2.MinInputBufferSize)
+		for i, msg := range msgs {
+			assert.Equal(t, makeMsg(i), msg)
+		}
+		tp.ReleaseBuffers()
+	}
+	testSetup2(t, &opts1, &opts2, cb1, cb2)
+	assert.Equal(t, 0, bp.UsedMemory())
+}
+
+type testTrafficCrypter byte
+
+func (s testTrafficCrypter) EncryptTraffic(traffic []byte) {
+	for i := range traffic {
+		traffic[i] ^= byte(s)
+	}
+}
+
+func (s testTrafficCrypter) DecryptTraffic(traffic []byte) {
+	s.EncryptTraffic(traffic)
+}
+
 type testHandshaker struct {
 	CbHandleHandshake func(context.Context, []byte) (bool, error)
 	CbSizeHandshake   func() int
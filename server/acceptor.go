@@ -2,17 +2,44 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/let-z-go/gogorpc/internal/inproc"
+	"github.com/let-z-go/gogorpc/internal/mux"
 )
 
 type Acceptor func(ctx context.Context, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error
+
+// AcceptorWithOptions is an Acceptor which is also given the server options, e.g. for
+// the tls settings.
+type AcceptorWithOptions func(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error
+
 type ConnectionHandler func(connection net.Conn)
 
 func RegisterAcceptor(schemeName string, acceptor Acceptor) error {
+	return RegisterAcceptorWithOptions(schemeName, func(ctx context.Context, _ *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+		return acceptor(ctx, url_, activityCounter, connectionHandler)
+	})
+}
+
+func MustRegisterAcceptor(schemeName string, acceptor Acceptor) {
+	if err := RegisterAcceptor(schemeName, acceptor); err != nil {
+		panic(err)
+	}
+}
+
+func RegisterAcceptorWithOptions(schemeName string, acceptor AcceptorWithOptions) error {
 	if _, ok := acceptors[schemeName]; ok {
 		return &AcceptorExistsError{fmt.Sprintf("schemeName=%#v", schemeName)}
 	}
@@ -21,13 +48,35 @@ func RegisterAcceptor(schemeName string, acceptor Acceptor) error {
 	return nil
 }
 
-func MustRegisterAcceptor(schemeName string, acceptor Acceptor) {
-	if err := RegisterAcceptor(schemeName, acceptor); err != nil {
+func MustRegisterAcceptorWithOptions(schemeName string, acceptor AcceptorWithOptions) {
+	if err := RegisterAcceptorWithOptions(schemeName, acceptor); err != nil {
 		panic(err)
 	}
 }
 
 func GetAcceptor(schemeName string) (Acceptor, error) {
+	acceptor, err := GetAcceptorWithOptions(schemeName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+		return acceptor(ctx, new(Options).Normalize(), url_, activityCounter, connectionHandler)
+	}, nil
+}
+
+func MustGetAcceptor(schemeName string) Acceptor {
+	acceptor, err := GetAcceptor(schemeName)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return acceptor
+}
+
+func GetAcceptorWithOptions(schemeName string) (AcceptorWithOptions, error) {
 	acceptor, ok := acceptors[schemeName]
 
 	if !ok {
@@ -37,8 +86,8 @@ func GetAcceptor(schemeName string) (Acceptor, error) {
 	return acceptor, nil
 }
 
-func MustGetAcceptor(schemeName string) Acceptor {
-	acceptor, err := GetAcceptor(schemeName)
+func MustGetAcceptorWithOptions(schemeName string) AcceptorWithOptions {
+	acceptor, err := GetAcceptorWithOptions(schemeName)
 
 	if err != nil {
 		panic(err)
@@ -75,15 +124,250 @@ func (anfe AcceptorNotFoundError) Error() string {
 	return message
 }
 
-var acceptors = map[string]Acceptor{}
+var acceptors = map[string]AcceptorWithOptions{}
+
+func tcpAcceptor(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	if options.ProxyProtocol.isEnabled(url_) {
+		trustedNetworks, err := options.ProxyProtocol.parseTrustedNetworks()
+
+		if err != nil {
+			return err
+		}
+
+		connectionHandler = proxyConnectionHandler(options, url_, trustedNetworks, connectionHandler)
+	}
 
-func tcpAcceptor(ctx context.Context, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
 	listener, err := net.Listen("tcp", url_.Host)
 
 	if err != nil {
 		return err
 	}
 
+	return acceptConnections(ctx, listener, activityCounter, connectionHandler)
+}
+
+func proxyConnectionHandler(options *Options, url_ *url.URL, trustedNetworks []*net.IPNet, connectionHandler ConnectionHandler) ConnectionHandler {
+	return func(connection net.Conn) {
+		if !isTrustedProxy(connection, trustedNetworks) {
+			connectionHandler(connection)
+			return
+		}
+
+		proxiedConnection, err := readProxyHeader(connection, options.Channel.Stream.Transport.HandshakeTimeout)
+
+		if err != nil {
+			options.Logger.Warn().Err(err).
+				Str("server_url", url_.String()).
+				Str("proxy_address", connection.RemoteAddr().String()).
+				Msg("server_proxy_header_read_failed")
+			connection.Close()
+			return
+		}
+
+		connectionHandler(proxiedConnection)
+	}
+}
+
+func unixAcceptor(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	socketFileName := url_.Host + url_.Path
+
+	if err := removeStaleUnixSocket(socketFileName); err != nil {
+		return err
+	}
+
+	listener, err := listenUnix(socketFileName, options.UnixSocketFileMode)
+
+	if err != nil {
+		return err
+	}
+
+	return acceptConnections(ctx, listener, activityCounter, connectionHandler)
+}
+
+func removeStaleUnixSocket(socketFileName string) error {
+	if fileInfo, err := os.Lstat(socketFileName); err != nil || fileInfo.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	// a socket file left behind by a previous process refuses connections, anything
+	// else is left to fail the listen.
+	connection, err := net.DialTimeout("unix", socketFileName, time.Second)
+
+	if err == nil {
+		connection.Close()
+		return ErrUnixSocketInUse
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return nil
+	}
+
+	return os.Remove(socketFileName)
+}
+
+func listenUnix(socketFileName string, socketFileMode os.FileMode) (net.Listener, error) {
+	if socketFileMode == 0 {
+		return net.Listen("unix", socketFileName)
+	}
+
+	// create the socket in a private directory and link it into place once its mode
+	// is set, so that no one can connect in between.
+	dirName, err := ioutil.TempDir(filepath.Dir(socketFileName), ".gogorpc")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dirName)
+	privateSocketFileName := filepath.Join(dirName, "socket")
+	listener, err := net.Listen("unix", privateSocketFileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(privateSocketFileName, socketFileMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	if err := os.Link(privateSocketFileName, socketFileName); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return unixListener{listener, socketFileName}, nil
+}
+
+var ErrUnixSocketInUse = errors.New("gogorpc/server: unix socket in use")
+
+type unixListener struct {
+	net.Listener
+
+	socketFileName string
+}
+
+func (ul unixListener) Close() error {
+	err := ul.Listener.Close()
+	os.Remove(ul.socketFileName)
+	return err
+}
+
+func unixAbstractAcceptor(ctx context.Context, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	listener, err := net.Listen("unix", "@"+url_.Host+url_.Path)
+
+	if err != nil {
+		return err
+	}
+
+	return acceptConnections(ctx, listener, activityCounter, connectionHandler)
+}
+
+func inprocAcceptor(ctx context.Context, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	listener, err := inproc.Listen(url_.Host + url_.Path)
+
+	if err != nil {
+		return err
+	}
+
+	return acceptConnections(ctx, listener, activityCounter, connectionHandler)
+}
+
+func webSocketAcceptor(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	return acceptWebSocketConnections(ctx, options, url_, nil, activityCounter, connectionHandler)
+}
+
+func webSocketSecureAcceptor(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	return acceptWebSocketConnections(ctx, options, url_, options.TLS.config, activityCounter, connectionHandler)
+}
+
+func acceptWebSocketConnections(ctx context.Context, options *Options, url_ *url.URL, tlsConfig *tls.Config, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	if handler := options.WebSocketHandler; handler != nil {
+		// mounted on an existing http server, which takes care of listening and tls.
+		listener := webSocketListener{handler, webSocketAddress(url_.Host)}
+		return acceptConnections(ctx, listener, activityCounter, connectionHandler)
+	}
+
+	rawListener, err := net.Listen("tcp", url_.Host)
+
+	if err != nil {
+		return err
+	}
+
+	if tlsConfig != nil {
+		rawListener = tls.NewListener(rawListener, tlsConfig)
+	}
+
+	handler := new(WebSocketHandler).Init()
+	path := url_.Path
+
+	if path == "" {
+		path = "/"
+	}
+
+	serveMux := http.NewServeMux()
+	serveMux.Handle(path, handler)
+
+	httpServer := http.Server{
+		Handler:           serveMux,
+		ReadHeaderTimeout: options.Channel.Stream.Transport.HandshakeTimeout,
+	}
+
+	err2 := make(chan error, 1)
+
+	go func() {
+		err2 <- httpServer.Serve(rawListener)
+		handler.close()
+	}()
+
+	listener := webSocketListener{handler, rawListener.Addr()}
+	err = acceptConnections(ctx, listener, activityCounter, connectionHandler)
+	httpServer.Close()
+
+	if err3 := <-err2; err3 != http.ErrServerClosed {
+		err = err3
+	}
+
+	return err
+}
+
+func muxAcceptor(underlyingAcceptor AcceptorWithOptions) AcceptorWithOptions {
+	return func(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+		return underlyingAcceptor(ctx, options, url_, activityCounter, func(connection net.Conn) {
+			session := new(mux.Session).Init(connection, true)
+			defer session.Close()
+			acceptDone := make(chan struct{})
+			defer close(acceptDone)
+
+			go func() {
+				select {
+				case <-ctx.Done():
+					// let the logical channels drain before the connection goes away.
+					session.Drain()
+				case <-acceptDone:
+				}
+			}()
+
+			for {
+				conn, err := session.Accept()
+
+				if err != nil {
+					return
+				}
+
+				atomic.AddInt32(activityCounter, 1)
+
+				go func() {
+					connectionHandler(conn)
+					atomic.AddInt32(activityCounter, -1)
+				}()
+			}
+		})
+	}
+}
+
+func acceptConnections(ctx context.Context, listener net.Listener, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	var err error
 	err2 := make(chan error, 1)
 
 	go func() {
@@ -137,6 +421,35 @@ func tcpAcceptor(ctx context.Context, url_ *url.URL, activityCounter *int32, con
 	return err
 }
 
+func tlsAcceptor(ctx context.Context, options *Options, url_ *url.URL, activityCounter *int32, connectionHandler ConnectionHandler) error {
+	return tcpAcceptor(ctx, options, url_, activityCounter, func(connection net.Conn) {
+		tlsConnection := tls.Server(connection, options.TLS.config)
+		tlsConnection.SetDeadline(time.Now().Add(options.Channel.Stream.Transport.HandshakeTimeout))
+
+		if err := tlsConnection.Handshake(); err != nil {
+			options.Logger.Warn().Err(err).
+				Str("server_url", url_.String()).
+				Str("client_address", connection.RemoteAddr().String()).
+				Msg("server_tls_handshake_failed")
+			connection.Close()
+			return
+		}
+
+		tlsConnection.SetDeadline(time.Time{})
+		connectionHandler(tlsConnection)
+	})
+}
+
 func init() {
-	MustRegisterAcceptor("tcp", tcpAcceptor)
+	MustRegisterAcceptorWithOptions("tcp", tcpAcceptor)
+	MustRegisterAcceptorWithOptions("tls", tlsAcceptor)
+	MustRegisterAcceptorWithOptions("unix", unixAcceptor)
+	MustRegisterAcceptor("unix-abstract", unixAbstractAcceptor)
+	MustRegisterAcceptor("inproc", inprocAcceptor)
+	MustRegisterAcceptorWithOptions("ws", webSocketAcceptor)
+	MustRegisterAcceptorWithOptions("wss", webSocketSecureAcceptor)
+
+	for _, schemeName := range [...]string{"tcp", "tls", "unix", "unix-abstract", "ws", "wss"} {
+		MustRegisterAcceptorWithOptions("mux+"+schemeName, muxAcceptor(MustGetAcceptorWithOptions(schemeName)))
+	}
 }
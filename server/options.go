@@ -2,19 +2,27 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"net/url"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/let-z-go/gogorpc/channel"
+	"github.com/let-z-go/gogorpc/internal/certloader"
 	"github.com/rs/zerolog"
 )
 
 type Options struct {
-	Channel         *channel.Options
-	Logger          *zerolog.Logger
-	Hooks           []*Hook
-	ShutdownTimeout time.Duration
+	Channel            *channel.Options
+	Logger             *zerolog.Logger
+	Hooks              []*Hook
+	DrainTimeout       time.Duration
+	ShutdownTimeout    time.Duration
+	TLS                TLSOptions
+	UnixSocketFileMode os.FileMode
+	WebSocketHandler   *WebSocketHandler
+	ProxyProtocol      ProxyProtocolOptions
 
 	normalizeOnce sync.Once
 }
@@ -30,6 +38,12 @@ func (o *Options) Normalize() *Options {
 		if o.Logger == nil {
 			o.Logger = o.Channel.Logger
 		}
+
+		if o.DrainTimeout == 0 {
+			o.DrainTimeout = defaultDrainTimeout
+		}
+
+		o.TLS.normalize()
 	})
 
 	return o
@@ -40,9 +54,43 @@ func (o *Options) Do(doer func(*Options)) *Options {
 	return o
 }
 
+func (o *Options) getDrainTimeout() time.Duration {
+	if drainTimeout := o.DrainTimeout; drainTimeout >= 1 {
+		return drainTimeout
+	}
+
+	return 0
+}
+
+type TLSOptions struct {
+	Config   *tls.Config
+	CertFile string
+	KeyFile  string
+
+	config *tls.Config
+}
+
+func (to *TLSOptions) normalize() {
+	if to.Config == nil {
+		to.config = &tls.Config{}
+	} else {
+		to.config = to.Config.Clone()
+	}
+
+	if to.CertFile != "" {
+		certLoader := new(certloader.CertLoader).Init(to.CertFile, to.KeyFile)
+
+		to.config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certLoader.GetCert()
+		}
+	}
+}
+
 type Hook struct {
 	BeforeRun func(ctx context.Context, url_ *url.URL) error
 	AfterRun  func(url_ *url.URL)
 }
 
+const defaultDrainTimeout = 3 * time.Second
+
 var defaultChannelOptions channel.Options
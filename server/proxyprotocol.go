@@ -0,0 +1,222 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ProxyProtocolOptions struct {
+	IsEnabled bool
+
+	// TrustedNetworks lists the CIDRs allowed to send a PROXY header,
+	// connections from anywhere else are taken as they are. It must not be empty.
+	TrustedNetworks []string
+}
+
+func (ppo *ProxyProtocolOptions) isEnabled(url_ *url.URL) bool {
+	if ppo.IsEnabled {
+		return true
+	}
+
+	isEnabled, _ := strconv.ParseBool(url_.Query().Get("proxy_protocol"))
+	return isEnabled
+}
+
+func (ppo *ProxyProtocolOptions) parseTrustedNetworks() ([]*net.IPNet, error) {
+	if len(ppo.TrustedNetworks) == 0 {
+		return nil, ErrNoTrustedNetworks
+	}
+
+	trustedNetworks := make([]*net.IPNet, len(ppo.TrustedNetworks))
+
+	for i, rawTrustedNetwork := range ppo.TrustedNetworks {
+		_, trustedNetwork, err := net.ParseCIDR(rawTrustedNetwork)
+
+		if err != nil {
+			return nil, err
+		}
+
+		trustedNetworks[i] = trustedNetwork
+	}
+
+	return trustedNetworks, nil
+}
+
+func isTrustedProxy(connection net.Conn, trustedNetworks []*net.IPNet) bool {
+	address, ok := connection.RemoteAddr().(*net.TCPAddr)
+
+	if !ok {
+		return false
+	}
+
+	for _, trustedNetwork := range trustedNetworks {
+		if trustedNetwork.Contains(address.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readProxyHeader(connection net.Conn, timeout time.Duration) (net.Conn, error) {
+	if timeout >= 1 {
+		connection.SetReadDeadline(time.Now().Add(timeout))
+		defer connection.SetReadDeadline(time.Time{})
+	}
+
+	reader := bufio.NewReader(connection)
+	signature, err := reader.Peek(len(proxyHeaderV2Signature))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceAddress net.Addr
+
+	if bytes.Equal(signature, proxyHeaderV2Signature) {
+		sourceAddress, err = readProxyHeaderV2(reader)
+	} else if bytes.HasPrefix(signature, proxyHeaderV1Signature) {
+		sourceAddress, err = readProxyHeaderV1(reader)
+	} else {
+		err = ErrBadProxyHeader
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceAddress == nil {
+		// LOCAL or UNKNOWN, e.g. health checks from the proxy itself.
+		sourceAddress = connection.RemoteAddr()
+	}
+
+	return &proxiedConnection{
+		Conn:          connection,
+		reader:        reader,
+		remoteAddress: sourceAddress,
+	}, nil
+}
+
+func readProxyHeaderV1(reader *bufio.Reader) (net.Addr, error) {
+	line, err := reader.ReadSlice('\n')
+
+	if err != nil {
+		if err == bufio.ErrBufferFull {
+			err = ErrBadProxyHeader
+		}
+
+		return nil, err
+	}
+
+	if len(line) > maxProxyHeaderV1Size || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, ErrBadProxyHeader
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrBadProxyHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+
+	if ip == nil || err != nil {
+		return nil, ErrBadProxyHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyHeaderV2(reader *bufio.Reader) (net.Addr, error) {
+	var header [16]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+
+	versionAndCommand := header[12]
+	family := header[13]
+	addressesSize := int(binary.BigEndian.Uint16(header[14:]))
+
+	if versionAndCommand>>4 != 2 {
+		return nil, ErrBadProxyHeader
+	}
+
+	addresses := make([]byte, addressesSize)
+
+	if _, err := io.ReadFull(reader, addresses); err != nil {
+		return nil, err
+	}
+
+	switch versionAndCommand & 0xF {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, ErrBadProxyHeader
+	}
+
+	switch family {
+	case 0x11: // TCP over IPv4
+		if addressesSize < 12 {
+			return nil, ErrBadProxyHeader
+		}
+
+		return &net.TCPAddr{
+			IP:   net.IP(addresses[:4]),
+			Port: int(binary.BigEndian.Uint16(addresses[8:])),
+		}, nil
+	case 0x21: // TCP over IPv6
+		if addressesSize < 36 {
+			return nil, ErrBadProxyHeader
+		}
+
+		return &net.TCPAddr{
+			IP:   net.IP(addresses[:16]),
+			Port: int(binary.BigEndian.Uint16(addresses[32:])),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+var (
+	ErrNoTrustedNetworks = errors.New("gogorpc/server: no trusted networks")
+	ErrBadProxyHeader    = errors.New("gogorpc/server: bad proxy header")
+)
+
+type proxiedConnection struct {
+	net.Conn
+
+	reader        *bufio.Reader
+	remoteAddress net.Addr
+}
+
+func (pc *proxiedConnection) Read(buffer []byte) (int, error) {
+	// drain whatever was read ahead along with the header first.
+	return pc.reader.Read(buffer)
+}
+
+func (pc *proxiedConnection) RemoteAddr() net.Addr {
+	return pc.remoteAddress
+}
+
+const maxProxyHeaderV1Size = 107
+
+var (
+	proxyHeaderV1Signature = []byte("PROXY ")
+	proxyHeaderV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
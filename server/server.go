@@ -23,7 +23,7 @@ func (s *Server) Init(options *Options, rawURL string) *Server {
 	s.options = options.Normalize()
 	s.rawURL = rawURL
 	s.ctx, s.cancel = context.WithCancel(context.Background())
-	s.activity.Init(s.ctx, s.options.ShutdownTimeout)
+	s.activity.Init(s.ctx, s.options.getDrainTimeout(), s.options.ShutdownTimeout)
 	return s
 }
 
@@ -69,8 +69,8 @@ func (s *Server) Run() (err error) {
 		return
 	}
 
-	var acceptor Acceptor
-	acceptor, err = GetAcceptor(url_.Scheme)
+	var acceptor AcceptorWithOptions
+	acceptor, err = GetAcceptorWithOptions(url_.Scheme)
 
 	if err != nil {
 		return
@@ -98,9 +98,20 @@ func (s *Server) Run() (err error) {
 		}
 	}
 
-	err = acceptor(s.ctx, url_, &s.activity.Counter, func(connection net.Conn) {
+	err = acceptor(s.ctx, s.options, url_, &s.activity.Counter, func(connection net.Conn) {
 		channel_ := new(channel.Channel).Init(s.options.Channel, true)
 		defer channel_.Close()
+		runDone := make(chan struct{})
+		defer close(runDone)
+
+		go func() {
+			select {
+			case <-s.ctx.Done():
+				channel_.Drain()
+			case <-runDone:
+			}
+		}()
+
 		err := channel_.Run(s.activity.Ctx, url_, connection)
 		s.options.Logger.Warn().Err(err).
 			Str("server_url", s.rawURL).
@@ -136,10 +147,11 @@ type activity struct {
 	isClosed int32
 }
 
-func (a *activity) Init(ctx context.Context, overtime time.Duration) {
+func (a *activity) Init(ctx context.Context, drainTimeout time.Duration, overtime time.Duration) {
+	// channels are drained first once ctx is done, the overtime starts after the drain timeout.
 	if overtime < 0 {
 		a.Ctx = context.Background()
-	} else if overtime == 0 {
+	} else if drainTimeout+overtime == 0 {
 		a.Ctx = ctx
 	} else {
 		var cancel context.CancelFunc
@@ -148,7 +160,7 @@ func (a *activity) Init(ctx context.Context, overtime time.Duration) {
 		go func() {
 			select {
 			case <-ctx.Done():
-				time.Sleep(overtime)
+				time.Sleep(drainTimeout + overtime)
 				cancel()
 			}
 		}()
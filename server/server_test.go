@@ -2,10 +2,28 @@ package server
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/let-z-go/gogorpc/channel"
 	"github.com/let-z-go/gogorpc/client"
@@ -42,8 +60,495 @@ func TestServerShutdown(t *testing.T) {
 	}()
 	go func() {
 		t.Log(s.Run())
+		s.WaitForShutdown()
 	}()
 	s.WaitForShutdown()
 }
 
+func TestServerDrain(t *testing.T) {
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+	}
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		time.Sleep(time.Second)
+		rpc.Response = channel.NullMessage
+	})
+	s := new(Server).Init(&opts, "tcp://127.0.0.1:8004")
+	go func() {
+		t.Log(s.Run())
+		s.WaitForShutdown()
+	}()
+	c := new(client.Client).Init(&client.Options{Logger: &logger}, "tcp://127.0.0.1:8004")
+	defer func() {
+		c.Close()
+		<-c.Shutdown()
+	}()
+	time.AfterFunc(300*time.Millisecond, s.Close)
+	rpc := channel.RPC{
+		Ctx:     context.Background(),
+		Request: channel.NullMessage,
+	}
+	// the in-flight rpc is finished before the shutdown.
+	c.DoRPC(&rpc, channel.GetNullMessage)
+	assert.NoError(t, rpc.Err)
+	s.WaitForShutdown()
+}
+
+func TestTLS(t *testing.T) {
+	dirName, err := ioutil.TempDir("", "gogorpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dirName)
+	caCert, caKey := makeTestCert(t, dirName, "ca", nil, nil)
+	makeTestCert(t, dirName, "server", caCert, caKey)
+	makeTestCert(t, dirName, "client", caCert, caKey)
+	caCerts := x509.NewCertPool()
+	caCerts.AddCert(caCert)
+	for _, serverURL := range [...]string{"tls://127.0.0.1:8001", "wss://127.0.0.1:8005/", "mux+tls://127.0.0.1:8006"} {
+		opts := Options{
+			Channel: &channel.Options{
+				Stream: &channel.StreamOptions{
+					Transport: &channel.TransportOptions{
+						Logger: &logger,
+					},
+				},
+			},
+			TLS: TLSOptions{
+				Config: &tls.Config{
+					ClientAuth: tls.RequireAndVerifyClientCert,
+					ClientCAs:  caCerts,
+				},
+				CertFile: filepath.Join(dirName, "server.crt"),
+				KeyFile:  filepath.Join(dirName, "server.key"),
+			},
+		}
+		peerCommonName := ""
+		opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+			if peerCert := rpc.Channel().PeerCert(); peerCert != nil {
+				peerCommonName = peerCert.Subject.CommonName
+			}
+			rpc.Response = channel.NullMessage
+		})
+		s := new(Server).Init(&opts, serverURL)
+		go func() {
+			c := new(client.Client).Init(&client.Options{
+				Logger: &logger,
+				TLS: client.TLSOptions{
+					Config: &tls.Config{
+						RootCAs: caCerts,
+					},
+					CertFile: filepath.Join(dirName, "client.crt"),
+					KeyFile:  filepath.Join(dirName, "client.key"),
+				},
+			}, serverURL)
+			defer func() {
+				c.Close()
+				<-c.Shutdown()
+				t.Log(c.LastError())
+			}()
+			rpc := channel.RPC{
+				Ctx:     context.Background(),
+				Request: channel.NullMessage,
+			}
+			c.DoRPC(&rpc, channel.GetNullMessage)
+			assert.NoError(t, rpc.Err)
+			s.Close()
+		}()
+		t.Log(s.Run())
+		s.WaitForShutdown()
+		assert.Equal(t, "client", peerCommonName)
+	}
+}
+
+func TestUnixSocket1(t *testing.T) {
+	dirName, err := ioutil.TempDir("", "gogorpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dirName)
+	socketFileName := filepath.Join(dirName, "test.sock")
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+		UnixSocketFileMode: 0600,
+	}
+	var peerCred *channel.PeerCred
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		peerCred = rpc.Channel().PeerCred()
+		rpc.Response = channel.NullMessage
+	})
+	s := new(Server).Init(&opts, "unix://"+socketFileName)
+	go func() {
+		c := new(client.Client).Init(&client.Options{Logger: &logger}, "unix://"+socketFileName)
+		defer func() {
+			c.Close()
+			<-c.Shutdown()
+			t.Log(c.LastError())
+		}()
+		rpc := channel.RPC{
+			Ctx:     context.Background(),
+			Request: channel.NullMessage,
+		}
+		c.DoRPC(&rpc, channel.GetNullMessage)
+		assert.NoError(t, rpc.Err)
+		fileInfo, err := os.Stat(socketFileName)
+		if assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+		}
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+	if runtime.GOOS == "linux" && assert.NotNil(t, peerCred) {
+		assert.Equal(t, os.Getpid(), peerCred.PID)
+		assert.Equal(t, os.Getuid(), peerCred.UID)
+	}
+}
+
+func TestUnixSocket2(t *testing.T) {
+	dirName, err := ioutil.TempDir("", "gogorpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dirName)
+	socketFileName := filepath.Join(dirName, "test.sock")
+	l, err := net.Listen("unix", socketFileName)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	newServer := func() *Server {
+		opts := Options{
+			Channel: &channel.Options{
+				Stream: &channel.StreamOptions{
+					Transport: &channel.TransportOptions{
+						Logger: &logger,
+					},
+				},
+			},
+			UnixSocketFileMode: 0600,
+		}
+		opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+			rpc.Response = channel.NullMessage
+		})
+		return new(Server).Init(&opts, "unix://"+socketFileName)
+	}
+	// a socket still being listened on is not taken over.
+	assert.Equal(t, ErrUnixSocketInUse, newServer().Run())
+	// a socket left behind is.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	s := newServer()
+	go func() {
+		c := new(client.Client).Init(&client.Options{Logger: &logger}, "unix://"+socketFileName)
+		defer func() {
+			c.Close()
+			<-c.Shutdown()
+		}()
+		rpc := channel.RPC{
+			Ctx:     context.Background(),
+			Request: channel.NullMessage,
+		}
+		c.DoRPC(&rpc, channel.GetNullMessage)
+		assert.NoError(t, rpc.Err)
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+	fileInfos, err := ioutil.ReadDir(dirName)
+	if assert.NoError(t, err) {
+		assert.Len(t, fileInfos, 0)
+	}
+}
+
+func TestInproc(t *testing.T) {
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+	}
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		rpc.Response = rpc.Request
+	})
+	s := new(Server).Init(&opts, "inproc://test")
+	go func() {
+		c := new(client.Client).Init(&client.Options{Logger: &logger}, "inproc://test")
+		defer func() {
+			c.Close()
+			<-c.Shutdown()
+			t.Log(c.LastError())
+		}()
+		for i := 0; i < 100; i++ {
+			rpc := channel.RPC{
+				Ctx:     context.Background(),
+				Request: channel.NullMessage,
+			}
+			c.DoRPC(&rpc, channel.GetNullMessage)
+			if !assert.NoError(t, rpc.Err) {
+				break
+			}
+		}
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+}
+
+func TestWebSocket(t *testing.T) {
+	handler := new(WebSocketHandler).Init()
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/gogorpc", handler)
+	ts := httptest.NewServer(serveMux)
+	defer ts.Close()
+	serverURL := "ws://" + ts.Listener.Addr().String() + "/gogorpc"
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+		WebSocketHandler: handler,
+	}
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		rpc.Response = channel.NullMessage
+	})
+	s := new(Server).Init(&opts, serverURL)
+	go func() {
+		c := new(client.Client).Init(&client.Options{Logger: &logger}, serverURL)
+		defer func() {
+			c.Close()
+			<-c.Shutdown()
+			t.Log(c.LastError())
+		}()
+		for i := 0; i < 10; i++ {
+			rpc := channel.RPC{
+				Ctx:     context.Background(),
+				Request: channel.NullMessage,
+			}
+			c.DoRPC(&rpc, channel.GetNullMessage)
+			if !assert.NoError(t, rpc.Err) {
+				break
+			}
+		}
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+}
+
+func TestProxyProtocol1(t *testing.T) {
+	client.MustRegisterConnector("tcp-proxied", func(ctx context.Context, timeout time.Duration, serverURL *url.URL) (net.Conn, error) {
+		connection, err := (&net.Dialer{}).DialContext(ctx, "tcp", serverURL.Host)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := connection.Write([]byte("PROXY TCP4 192.0.2.1 127.0.0.1 4321 8002\r\n")); err != nil {
+			connection.Close()
+			return nil, err
+		}
+		return connection, nil
+	})
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+		ProxyProtocol: ProxyProtocolOptions{
+			TrustedNetworks: []string{"127.0.0.0/8"},
+		},
+	}
+	remoteAddress := ""
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		remoteAddress = rpc.Channel().RemoteAddr().String()
+		rpc.Response = channel.NullMessage
+	})
+	s := new(Server).Init(&opts, "tcp://127.0.0.1:8002?proxy_protocol=true")
+	go func() {
+		c := new(client.Client).Init(&client.Options{Logger: &logger}, "tcp-proxied://127.0.0.1:8002")
+		defer func() {
+			c.Close()
+			<-c.Shutdown()
+			t.Log(c.LastError())
+		}()
+		rpc := channel.RPC{
+			Ctx:     context.Background(),
+			Request: channel.NullMessage,
+		}
+		c.DoRPC(&rpc, channel.GetNullMessage)
+		assert.NoError(t, rpc.Err)
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+	assert.Equal(t, "192.0.2.1:4321", remoteAddress)
+}
+
+func TestProxyProtocol2(t *testing.T) {
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+	}
+	// trusting every source is refused.
+	assert.Equal(t, ErrNoTrustedNetworks, new(Server).Init(&opts, "tcp://127.0.0.1:8007?proxy_protocol=true").Run())
+	opts2 := Options{
+		ProxyProtocol: ProxyProtocolOptions{
+			TrustedNetworks: []string{"192.0.2.0/24"},
+		},
+	}
+	opts2.Normalize()
+	trustedNetworks, err := opts2.ProxyProtocol.parseTrustedNetworks()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer l.Close()
+	go func() {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("PROXY TCP4 192.0.2.1 127.0.0.1 4321 8007\r\n"))
+	}()
+	conn, err := l.Accept()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+	url_, _ := url.Parse("tcp://127.0.0.1:8007?proxy_protocol=true")
+	// an untrusted source can not override the remote address.
+	proxyConnectionHandler(&opts2, url_, trustedNetworks, func(conn2 net.Conn) {
+		assert.Equal(t, conn.RemoteAddr(), conn2.RemoteAddr())
+		buf, err := ioutil.ReadAll(conn2)
+		assert.NoError(t, err)
+		assert.Equal(t, "PROXY TCP4 192.0.2.1 127.0.0.1 4321 8007\r\n", string(buf))
+	})(conn)
+}
+
+func TestMux(t *testing.T) {
+	opts := Options{
+		Channel: &channel.Options{
+			Stream: &channel.StreamOptions{
+				Transport: &channel.TransportOptions{
+					Logger: &logger,
+				},
+			},
+		},
+	}
+	var mutex sync.Mutex
+	transportIDs := map[string]struct{}{}
+	remoteAddresses := map[string]struct{}{}
+	opts.Channel.BuildMethod("", "").SetIncomingRPCHandler(func(rpc *channel.RPC) {
+		mutex.Lock()
+		transportIDs[rpc.Channel().TransportID().String()] = struct{}{}
+		remoteAddresses[rpc.Channel().RemoteAddr().String()] = struct{}{}
+		mutex.Unlock()
+		rpc.Response = channel.NullMessage
+	})
+	s := new(Server).Init(&opts, "mux+tcp://127.0.0.1:8003")
+	go func() {
+		var cs []*client.Client
+		// clients with the same options share one connection.
+		copts := client.Options{Logger: &logger}
+		for i := 0; i < 4; i++ {
+			cs = append(cs, new(client.Client).Init(&copts, "mux+tcp://127.0.0.1:8003"))
+		}
+		var wg sync.WaitGroup
+		for _, c := range cs {
+			c := c
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rpc := channel.RPC{
+					Ctx:     context.Background(),
+					Request: channel.NullMessage,
+				}
+				c.DoRPC(&rpc, channel.GetNullMessage)
+				assert.NoError(t, rpc.Err)
+			}()
+		}
+		wg.Wait()
+		for _, c := range cs {
+			c.Close()
+			<-c.Shutdown()
+			t.Log(c.LastError())
+		}
+		s.Close()
+	}()
+	t.Log(s.Run())
+	s.WaitForShutdown()
+	assert.Len(t, transportIDs, 4)
+	assert.Len(t, remoteAddresses, 1)
+}
+
+func makeTestCert(t *testing.T, dirName string, name string, parentCert *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parentCert == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parentCert, parentKey = &template, key
+	}
+	rawCert, err := x509.CreateCertificate(rand.Reader, &template, parentCert, &key.PublicKey, parentKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(filepath.Join(dirName, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rawCert}), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(filepath.Join(dirName, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey}), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	cert, err := x509.ParseCertificate(rawCert)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return cert, key
+}
+
 var logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Logger()
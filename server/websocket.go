@@ -0,0 +1,130 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketHandler lets ws:// and wss:// servers be mounted on an existing net/http server.
+type WebSocketHandler struct {
+	connections chan net.Conn
+	closure     chan struct{}
+	closeOnce   sync.Once
+}
+
+func (wsh *WebSocketHandler) Init() *WebSocketHandler {
+	wsh.connections = make(chan net.Conn)
+	wsh.closure = make(chan struct{})
+	return wsh
+}
+
+func (wsh *WebSocketHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	select {
+	case <-wsh.closure:
+		http.Error(responseWriter, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	websocket.Server{Handler: func(rawConnection *websocket.Conn) {
+		rawConnection.PayloadType = websocket.BinaryFrame
+
+		connection := &webSocketConnection{
+			Conn:          rawConnection,
+			remoteAddress: webSocketAddress(request.RemoteAddr),
+			closure:       make(chan struct{}),
+		}
+
+		select {
+		case wsh.connections <- connection:
+		case <-wsh.closure:
+			return
+		case <-request.Context().Done():
+			return
+		}
+
+		// the websocket connection is torn down as soon as this handler returns.
+		<-connection.closure
+	}}.ServeHTTP(responseWriter, request)
+}
+
+func (wsh *WebSocketHandler) accept() (net.Conn, error) {
+	select {
+	case connection := <-wsh.connections:
+		return connection, nil
+	case <-wsh.closure:
+		return nil, ErrWebSocketHandlerClosed
+	}
+}
+
+func (wsh *WebSocketHandler) close() {
+	wsh.closeOnce.Do(func() {
+		close(wsh.closure)
+	})
+}
+
+var ErrWebSocketHandlerClosed = errors.New("gogorpc/server: websocket handler closed")
+
+type webSocketListener struct {
+	handler *WebSocketHandler
+	address net.Addr
+}
+
+var _ = net.Listener(webSocketListener{})
+
+func (wsl webSocketListener) Accept() (net.Conn, error) {
+	return wsl.handler.accept()
+}
+
+func (wsl webSocketListener) Close() error {
+	wsl.handler.close()
+	return nil
+}
+
+func (wsl webSocketListener) Addr() net.Addr {
+	return wsl.address
+}
+
+type webSocketConnection struct {
+	*websocket.Conn
+
+	remoteAddress webSocketAddress
+	closure       chan struct{}
+	closeOnce     sync.Once
+}
+
+func (wsc *webSocketConnection) Close() error {
+	wsc.closeOnce.Do(func() {
+		close(wsc.closure)
+	})
+
+	return wsc.Conn.Close()
+}
+
+func (wsc *webSocketConnection) ConnectionState() tls.ConnectionState {
+	if connectionState := wsc.Request().TLS; connectionState != nil {
+		return *connectionState
+	}
+
+	return tls.ConnectionState{}
+}
+
+func (wsc *webSocketConnection) RemoteAddr() net.Addr {
+	// websocket.Conn reports the origin instead, which may be absent.
+	return wsc.remoteAddress
+}
+
+type webSocketAddress string
+
+func (webSocketAddress) Network() string {
+	return "tcp"
+}
+
+func (wsa webSocketAddress) String() string {
+	return string(wsa)
+}